@@ -0,0 +1,103 @@
+// Command calc is the command line version of the calculator. Without arguments it starts an interactive session,
+// with a file name it runs the file as a script and prints the result of each statement. The -output flag prints
+// results as LaTeX or MathML together with their expression, so they can be embedded in documents. The interactive
+// session keeps a history of all calculations in the file given by -history, -private stops writing to it. The flags
+// -mode, -base, -locale and -percent set what the commands of the same name set in the interactive session.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/niklasstich/calculator/evaluation"
+	"github.com/niklasstich/calculator/history"
+	"github.com/niklasstich/calculator/render"
+	"github.com/niklasstich/calculator/repl"
+	"github.com/niklasstich/calculator/script"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+func main() {
+	output := flag.String("output", "text", "output format of results: text, latex or mathml")
+	historyPath := flag.String("history", "", "file the history is stored in (default in the user config directory)")
+	historySize := flag.Int("history-size", history.DefaultMaxEntries, "number of calculations kept in the history")
+	private := flag.Bool("private", false, "don't write calculations to the history file")
+	settings := map[string]*string{
+		"mode":    flag.String("mode", "float", "evaluate with float, integer [TYPE] or big [BITS] numbers"),
+		"base":    flag.String("base", "10", "base of integer results: 2, 8, 10 or 16"),
+		"locale":  flag.String("locale", "en", "locale numbers are read and printed in: en, de, fr or ch"),
+		"percent": flag.String("percent", "calculator", "meaning of percentages after + and -: calculator or divide"),
+	}
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [-output FORMAT] [-mode MODE] [-base BASE] [-locale LOCALE] "+
+			"[-percent MODE] [-history FILE] [-private] [FILE]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	f, err := render.ParseFormat(*output)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	r := repl.New()
+	r.Output = f
+	for setting, value := range settings {
+		if err := r.Set(setting, strings.Fields(*value)...); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+	}
+	if flag.NArg() > 0 {
+		os.Exit(runScript(flag.Arg(0), r))
+	}
+	if r.History, err = openHistory(*historyPath, r.Env); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	r.History.MaxEntries = *historySize
+	r.History.Private = *private
+	if err := r.Run(os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// openHistory opens the history file at path, or at history.DefaultPath if it is empty
+func openHistory(path string, env *evaluation.Environment) (*history.History, error) {
+	if path == "" {
+		var err error
+		if path, err = history.DefaultPath(); err != nil {
+			return nil, err
+		}
+	}
+	return history.Open(path, env)
+}
+
+// runScript runs the script in the file with the settings of the REPL and returns the exit code, which is 1 if any
+// statement failed
+func runScript(name string, r *repl.REPL) int {
+	src, err := ioutil.ReadFile(name)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	code := 0
+	renderer := render.Renderer{Formatter: r.Formatter}
+	runner := script.Runner{Parser: r.Parser, Evaluator: r.Evaluator}
+	for _, res := range runner.Run(string(src)) {
+		var output string
+		if res.Definition != nil {
+			output = res.Definition.Source
+		} else if res.Err == nil {
+			output, res.Err = renderer.Render(r.Output, res.Expression, res.Value)
+		}
+		if res.Err != nil {
+			fmt.Fprintf(os.Stderr, "%s:%d: %v\n", name, res.Line, res.Err)
+			code = 1
+			continue
+		}
+		fmt.Println(output)
+	}
+	return code
+}
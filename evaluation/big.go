@@ -0,0 +1,239 @@
+package evaluation
+
+import (
+	"fmt"
+	"github.com/niklasstich/calculator/util"
+	"math"
+	"math/big"
+)
+
+// DefaultPrecision is the number of mantissa bits used in ModeBig if Evaluator.Precision is not set, about 77 decimal
+// digits
+const DefaultPrecision = 256
+
+// bigFuncLookup contains the binary operations of ModeBig, results are rounded to prec bits
+var bigFuncLookup = map[util.Op]func(a, b *big.Float, prec uint) (*big.Float, error){
+	util.OpAddition: func(a, b *big.Float, prec uint) (*big.Float, error) {
+		return newBig(prec).Add(a, b), nil
+	},
+	util.OpSubtraction: func(a, b *big.Float, prec uint) (*big.Float, error) {
+		return newBig(prec).Sub(a, b), nil
+	},
+	util.OpMultiplication: func(a, b *big.Float, prec uint) (*big.Float, error) {
+		return newBig(prec).Mul(a, b), nil
+	},
+	util.OpDivision: func(a, b *big.Float, prec uint) (*big.Float, error) {
+		if b.Sign() == 0 {
+			return nil, ErrDivByZero
+		}
+		return newBig(prec).Quo(a, b), nil
+	},
+	util.OpIntegerDivision: func(a, b *big.Float, prec uint) (*big.Float, error) {
+		if b.Sign() == 0 {
+			return nil, ErrDivByZero
+		}
+		return bigFloor(newBig(prec).Quo(a, b)), nil
+	},
+	util.OpModulo: func(a, b *big.Float, prec uint) (*big.Float, error) {
+		if b.Sign() == 0 {
+			return nil, ErrDivByZero
+		}
+		//a - b*floor(a/b)
+		q := bigFloor(newBig(prec).Quo(a, b))
+		return newBig(prec).Sub(a, q.Mul(q, b)), nil
+	},
+	util.OpRemainder: func(a, b *big.Float, prec uint) (*big.Float, error) {
+		if b.Sign() == 0 {
+			return nil, ErrDivByZero
+		}
+		//a - b*trunc(a/b)
+		q := bigTrunc(newBig(prec).Quo(a, b))
+		return newBig(prec).Sub(a, q.Mul(q, b)), nil
+	},
+	util.OpExponentiation: func(a, b *big.Float, prec uint) (*big.Float, error) {
+		if !b.IsInt() {
+			return nil, fmt.Errorf("%w: exponent %s in arbitrary precision mode", ErrNotAnInteger, util.BigText(b))
+		}
+		n, acc := b.Int64()
+		if acc != big.Exact {
+			return nil, fmt.Errorf("%w: exponent %s in arbitrary precision mode", ErrOverflow, util.BigText(b))
+		}
+		if n < 0 && a.Sign() == 0 {
+			return nil, ErrDivByZero
+		}
+		//the binary exponent of the result is about n*log2|a|, results out of range are rejected before squaring
+		if a.Sign() != 0 {
+			mant := new(big.Float)
+			exp := a.MantExp(mant)
+			m, _ := mant.Abs(mant).Float64()
+			if bits := float64(n) * (float64(exp) + math.Log2(m)); math.Abs(bits) > util.MaxBigExp {
+				return nil, fmt.Errorf("%w: %s ^ %d is out of the range 2^±%d", ErrOverflow, util.BigText(a), n,
+					util.MaxBigExp)
+			}
+		}
+		//exponentiation by squaring
+		res, base := newBig(prec).SetInt64(1), newBig(prec).Set(a)
+		for e := n; e != 0; e /= 2 {
+			if e%2 != 0 {
+				res.Mul(res, base)
+			}
+			base.Mul(base, base)
+		}
+		if n < 0 {
+			res.Quo(newBig(prec).SetInt64(1), res)
+		}
+		return res, nil
+	},
+}
+
+// bigUnaryLookup contains the unary operations of ModeBig
+var bigUnaryLookup = map[util.Op]func(a *big.Float, prec uint) *big.Float{
+	util.OpNegation: func(a *big.Float, prec uint) *big.Float {
+		return newBig(prec).Neg(a)
+	},
+	util.OpPercent: func(a *big.Float, prec uint) *big.Float {
+		return newBig(prec).Quo(a, big.NewFloat(100))
+	},
+}
+
+// inBigRange reports whether the binary exponent of v is within util.MaxBigExp, larger values would take very long to
+// print
+func inBigRange(v *big.Float) bool {
+	exp := v.MantExp(nil)
+	return exp >= -util.MaxBigExp && exp <= util.MaxBigExp
+}
+
+func newBig(prec uint) *big.Float {
+	return new(big.Float).SetPrec(prec)
+}
+
+// bigTrunc rounds x towards zero
+func bigTrunc(x *big.Float) *big.Float {
+	i, _ := x.Int(nil)
+	return newBig(x.Prec()).SetInt(i)
+}
+
+// bigFloor rounds x towards negative infinity
+func bigFloor(x *big.Float) *big.Float {
+	i, acc := x.Int(nil)
+	//Int truncates, which rounds negative numbers up
+	if acc == big.Above {
+		i.Sub(i, big.NewInt(1))
+	}
+	return newBig(x.Prec()).SetInt(i)
+}
+
+func (e *Evaluator) precision() uint {
+	if e.Precision == 0 {
+		return DefaultPrecision
+	}
+	return e.Precision
+}
+
+// evaluateBigToken works like evaluateToken in ModeBig
+func (e *Evaluator) evaluateBigToken(f *frame, token util.Token, stack *util.TokenStack) error {
+	prec := e.precision()
+	before := e.snapshot(stack)
+	if token.TokenType == util.TokenTypeOperand {
+		operand := token
+		if token.TokenName != "" {
+			var err error
+			if operand, err = variable(f, token.TokenName); err != nil {
+				return err
+			}
+		}
+		v, err := toBig(&operand, prec)
+		if err != nil {
+			return err
+		}
+		if !inBigRange(v) {
+			return fmt.Errorf("%w: %v", ErrOverflow, token)
+		}
+		stack.Push(bigToken(v))
+		e.trace(token, f.depth, before, stack)
+		return nil
+	}
+	if err := f.limit.step(); err != nil {
+		return err
+	}
+	op := token.TokenOperator.Op
+	switch {
+	case op == util.OpAnd, op == util.OpOr, op == util.OpTernary, op == util.OpNot:
+		if err := e.logical(op, stack); err != nil {
+			return err
+		}
+	case isComparison(op):
+		op1 := stack.Pop()
+		op2 := stack.Pop()
+		stack.Push(e.truth(compared(op, op2.TokenBig.Cmp(op1.TokenBig))))
+	case bigUnaryLookup[op] != nil:
+		a := stack.Pop()
+		v := bigUnaryLookup[op](a.TokenBig, prec)
+		if !inBigRange(v) {
+			return fmt.Errorf("%w: %v %v", ErrOverflow, token, a)
+		}
+		res := bigToken(v)
+		res.TokenPercent = op == util.OpPercent || op == util.OpNegation && a.TokenPercent
+		stack.Push(res)
+	default:
+		binary := bigFuncLookup[op]
+		if binary == nil {
+			return fmt.Errorf("%w: %v in arbitrary precision mode", ErrUnsupportedOperator, token)
+		}
+		op1 := stack.Pop()
+		op2 := stack.Pop()
+		b := op1.TokenBig
+		if (op == util.OpAddition || op == util.OpSubtraction) && e.isPercentage(op1) {
+			b = newBig(prec).Mul(op2.TokenBig, b)
+		}
+		v, err := binary(op2.TokenBig, b, prec)
+		if err != nil {
+			return err
+		}
+		//big.Float panics on operations like Inf*0, so an infinite value must never get on the stack
+		if v.IsInf() || !inBigRange(v) {
+			return fmt.Errorf("%w: %v %v %v", ErrOverflow, op2, token, op1)
+		}
+		stack.Push(bigToken(v))
+	}
+	e.trace(token, f.depth, before, stack)
+	return nil
+}
+
+// toBig converts an operand to a big.Float, literals are parsed again from their digits so 0.1 is exact to prec bits
+func toBig(token *util.Token, prec uint) (*big.Float, error) {
+	if token.TokenName != "" {
+		return nil, fmt.Errorf("%w: variable %s in arbitrary precision mode", ErrUnsupportedOperator, token.TokenName)
+	}
+	if token.TokenUnit != nil || isDate(token) {
+		return nil, fmt.Errorf("%w: %v in arbitrary precision mode", ErrIncompatibleUnits, token)
+	}
+	switch {
+	case token.TokenBig != nil:
+		return newBig(prec).Set(token.TokenBig), nil
+	case token.TokenInteger != nil:
+		if token.TokenInteger.Type.Signed {
+			return newBig(prec).SetInt64(int64(token.TokenInteger.Value)), nil
+		}
+		return newBig(prec).SetUint64(token.TokenInteger.Value), nil
+	case token.TokenLiteral != "":
+		v, _, err := big.ParseFloat(token.TokenLiteral, 10, prec, big.ToNearestEven)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidExpression, token.TokenLiteral)
+		}
+		return v, nil
+	}
+	if math.IsNaN(token.TokenOperand) {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidExpression, token)
+	}
+	return newBig(prec).SetFloat64(token.TokenOperand), nil
+}
+
+func bigToken(v *big.Float) util.Token {
+	f, _ := v.Float64()
+	return util.Token{
+		TokenType:    util.TokenTypeOperand,
+		TokenOperand: f,
+		TokenBig:     v,
+	}
+}
@@ -0,0 +1,90 @@
+package evaluation
+
+import (
+	"errors"
+	"fmt"
+	"github.com/niklasstich/calculator/parser"
+	"testing"
+)
+
+func TestBigMode(t *testing.T) {
+	var tests = []struct {
+		input     string
+		precision uint
+		result    string
+		err       error
+	}{
+		{"0.1 + 0.2", 0, "0.3", nil},
+		{"2 ^ 100", 0, "1.267650600228229401496703205376e+30", nil},
+		{"2 ^ -2", 0, "0.25", nil},
+		{"1 / 3", 64, "0.33333333333333333334", nil},
+		{"0xFFFFFFFFFFFFFFFF + 1", 0, "1.8446744073709551616e+19", nil},
+		{"123456789012345678901234567890 mod 11", 0, "7", nil},
+		{"-7 mod 3", 0, "2", nil},
+		{"-7 rem 3", 0, "-1", nil},
+		{"-7 // 2", 0, "-4", nil},
+		{"-(2 - 5)", 0, "3", nil},
+		{"200 + 10%", 0, "220", nil},
+		{"200 - -10%", 0, "220", nil},
+		{"0.1 + 0.2 == 0.3", 0, "true", nil},
+		{"2 ^ -2000 > 0", 0, "true", nil},
+		{"2 ^ -2000 ? 1 : 2", 0, "1", nil},
+		{"not 2 ^ -2000", 0, "false", nil},
+		{"1 < 2 && 2 < 3", 0, "true", nil},
+		{"0 || 1 / 0", 0, "", ErrDivByZero},
+		{"1 || 1 / 0", 0, "true", nil},
+		{"1 > 2 ? 1 / 0 : 3", 0, "3", nil},
+		{"1 / 0", 0, "", ErrDivByZero},
+		{"1 mod 0", 0, "", ErrDivByZero},
+		{"1 rem 0", 0, "", ErrDivByZero},
+		{"1 // 0", 0, "", ErrDivByZero},
+		{"0 ^ -1", 0, "", ErrDivByZero},
+		{"2 ^ 99999999999 * 0", 0, "", ErrOverflow},
+		{"9 ^ 9 ^ 9 ^ 9", 0, "", ErrOverflow},
+		{"9 ^ 9 ^ 9", 0, "", ErrOverflow},
+		{"2 ^ 2 ^ 70", 0, "", ErrOverflow},
+		{"0.5 ^ 70000", 0, "", ErrOverflow},
+		{"(-1) ^ 99999999999", 0, "-1", nil},
+		{"2 ^ 65535", 16, "1.00176e+19728", nil},
+		{"2 ^ 0.5", 0, "", ErrNotAnInteger},
+		{"3 m", 0, "", ErrIncompatibleUnits},
+		{"1 & 1", 0, "", ErrUnsupportedOperator},
+	}
+
+	for _, tt := range tests {
+		testName := fmt.Sprintf("%s(%d)=%s", tt.input, tt.precision, tt.result)
+		t.Run(testName, func(t *testing.T) {
+			tokens, err := parser.TokenizeString(tt.input)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			tokens, err = parser.ReformToRPN(tokens)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			e := Evaluator{Mode: ModeBig, Precision: tt.precision}
+			result, err := e.Evaluate(tokens)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Expected error %v, got %v", tt.err, err)
+			}
+			if err == nil && result.String() != tt.result {
+				t.Errorf("Expected %s, got %s", tt.result, result)
+			}
+		})
+	}
+}
+
+func TestBigModePi(t *testing.T) {
+	tokens, err := parser.TokenizeString("pi")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	result, err := (&Evaluator{Mode: ModeBig, Precision: 200}).Evaluate(tokens)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "3.14159265358979323846264338327950288419716939937510582097494"
+	if s := result.TokenBig.Text('f', 59); s != expected {
+		t.Errorf("Expected %s, got %s", expected, s)
+	}
+}
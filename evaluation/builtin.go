@@ -0,0 +1,116 @@
+package evaluation
+
+import (
+	"fmt"
+	"github.com/niklasstich/calculator/util"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// Builtin is a function every Environment knows, like sin or sqrt. A user defined function with the same name takes
+// precedence. Builtins are only available in ModeFloat.
+type Builtin struct {
+	Name string
+	// Params is the number of arguments, or Variadic
+	Params int
+	// Optional is the number of arguments at the end which may be left out
+	Optional int
+	// Lists is set for functions of lists like sum, they get lists as they are. Other functions are applied to each
+	// element of list arguments, so sqrt([4, 9]) is [2, 3].
+	Lists bool
+	// Apply returns the result for the arguments, which are in the order they were written
+	Apply func(args []util.Token) (util.Token, error)
+}
+
+// Variadic is the Params of a Builtin which takes any number of arguments
+const Variadic = -1
+
+var builtins = map[string]*Builtin{}
+
+func init() {
+	for name, f := range map[string]func(float64) float64{
+		"sin": math.Sin, "cos": math.Cos, "tan": math.Tan,
+		"asin": math.Asin, "acos": math.Acos, "atan": math.Atan,
+		"sinh": math.Sinh, "cosh": math.Cosh, "tanh": math.Tanh,
+		"exp": math.Exp, "ln": math.Log, "log": math.Log10, "log2": math.Log2,
+		"sqrt": math.Sqrt, "cbrt": math.Cbrt,
+	} {
+		builtins[name] = dimensionless(name, f)
+	}
+	for name, f := range map[string]func(float64) float64{
+		"abs": math.Abs, "floor": math.Floor, "ceil": math.Ceil, "round": math.Round, "trunc": math.Trunc,
+	} {
+		builtins[name] = sameUnit(name, f)
+	}
+	builtins["atan2"] = &Builtin{Name: "atan2", Params: 2, Apply: func(args []util.Token) (util.Token, error) {
+		y, x := args[0], args[1]
+		if !y.TokenUnit.Compatible(x.TokenUnit) {
+			return util.Token{}, fmt.Errorf("%w: atan2 of %v and %v", ErrIncompatibleUnits, y, x)
+		}
+		return quantity(math.Atan2(y.TokenOperand, x.TokenOperand*x.TokenUnit.ConversionFactor(y.TokenUnit)), nil), nil
+	}}
+}
+
+// LookupBuiltin returns the Builtin with the given name
+func LookupBuiltin(name string) (*Builtin, bool) {
+	b, ok := builtins[name]
+	return b, ok
+}
+
+// Builtins returns all builtin functions sorted by name
+func Builtins() []*Builtin {
+	list := make([]*Builtin, 0, len(builtins))
+	for _, b := range builtins {
+		list = append(list, b)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// dimensionless wraps a function of numbers without a unit, angles are in radians
+func dimensionless(name string, f func(float64) float64) *Builtin {
+	return &Builtin{Name: name, Params: 1, Apply: func(args []util.Token) (util.Token, error) {
+		if args[0].TokenUnit != nil {
+			return util.Token{}, fmt.Errorf("%w: argument %v of %s is not dimensionless", ErrIncompatibleUnits,
+				args[0], name)
+		}
+		return quantity(f(args[0].TokenOperand), nil), nil
+	}}
+}
+
+// sameUnit wraps a function whose result has the unit of its argument, like abs(-3 m) = 3 m
+func sameUnit(name string, f func(float64) float64) *Builtin {
+	return &Builtin{Name: name, Params: 1, Apply: func(args []util.Token) (util.Token, error) {
+		return quantity(f(args[0].TokenOperand), args[0].TokenUnit), nil
+	}}
+}
+
+// callBuiltin applies the Builtin to the arguments on top of the stack
+func callBuiltin(b *Builtin, operator *util.Operator, stack *util.TokenStack) error {
+	if b.Params != Variadic && (operator.Arguments > b.Params || operator.Arguments < b.Params-b.Optional) {
+		count := strconv.Itoa(b.Params)
+		if b.Optional > 0 {
+			count = fmt.Sprintf("%d to %d", b.Params-b.Optional, b.Params)
+		}
+		return fmt.Errorf("%w: %s takes %s arguments, got %d", ErrArgumentCount, b.Name, count, operator.Arguments)
+	}
+	args := popList(stack, operator.Arguments).TokenList
+	for i := range args {
+		if isDate(&args[i]) {
+			return fmt.Errorf("%w: %s takes numbers, got %v", ErrIncompatibleUnits, b.Name, args[i])
+		}
+	}
+	var res util.Token
+	var err error
+	if b.Lists {
+		res, err = b.Apply(args)
+	} else {
+		res, err = broadcast(args, b.Apply)
+	}
+	if err != nil {
+		return err
+	}
+	stack.Push(res)
+	return nil
+}
@@ -0,0 +1,80 @@
+package evaluation
+
+import (
+	"errors"
+	"github.com/niklasstich/calculator/parser"
+	"testing"
+)
+
+func TestBuiltins(t *testing.T) {
+	env := &Environment{}
+	p := parser.Parser{Scope: env}
+	var tests = []struct {
+		input, result string
+		err           error
+	}{
+		{"sqrt(16) + 1", "5", nil},
+		{"sin(0) + cos(0)", "1", nil},
+		{"round(atan2(1, 1) * 4 * 1000)", "3142", nil},
+		{"ln(exp(2))", "2", nil},
+		{"log(1000) - log2(8)", "0", nil},
+		{"abs(-3 km) in m", "3000 m", nil},
+		{"floor(2.7 m)", "2 m", nil},
+		{"2 sqrt(9)", "6", nil},
+		{"sin(3 m)", "", ErrIncompatibleUnits},
+		{"sqrt(1, 2)", "", ErrArgumentCount},
+	}
+	for _, tt := range tests {
+		tokens, err := p.Tokenize(tt.input)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", tt.input, err)
+		}
+		expression, err := p.ReformToRPN(tokens)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", tt.input, err)
+		}
+		result, err := (&Evaluator{Env: env}).Evaluate(expression)
+		if !errors.Is(err, tt.err) {
+			t.Fatalf("%s: expected error %v, got %v", tt.input, tt.err, err)
+		}
+		if err == nil && result.String() != tt.result {
+			t.Errorf("%s: expected %s, got %s", tt.input, tt.result, result)
+		}
+	}
+
+	//a user defined function replaces the builtin
+	def, _, err := p.ParseDefinition("sqrt(x) = x / 2")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := env.Define(*def); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	tokens, _ := p.Tokenize("sqrt(16)")
+	expression, _ := p.ReformToRPN(tokens)
+	if result, err := (&Evaluator{Env: env}).Evaluate(expression); err != nil || result.String() != "8" {
+		t.Errorf("Expected 8, got %v: %v", result, err)
+	}
+}
+
+func TestCall(t *testing.T) {
+	p := parser.Parser{Scope: &Environment{}}
+	def, _, err := p.ParseDefinition("f(x) = sin(x) / x")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	f, err := Compile(*def)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	e := Evaluator{}
+	for x, want := range map[float64]string{1: "0.8414709848078965", -2: "0.4546487134128408"} {
+		result, err := e.Call(f, quantity(x, nil))
+		if err != nil || result.String() != want {
+			t.Errorf("f(%v): expected %s, got %v: %v", x, want, result, err)
+		}
+	}
+	if _, err := e.Call(f); !errors.Is(err, ErrArgumentCount) {
+		t.Errorf("Expected error %v, got %v", ErrArgumentCount, err)
+	}
+}
@@ -0,0 +1,172 @@
+package evaluation
+
+import (
+	"errors"
+	"fmt"
+	"github.com/niklasstich/calculator/units"
+	"github.com/niklasstich/calculator/util"
+	"math"
+	"time"
+)
+
+var ErrDateRange = errors.New("date is out of range")
+
+// maxDateSeconds is about 10000 years, which covers all dates from 0001-01-01 to 9999-12-31
+const maxDateSeconds = 10000 * 366 * 86400
+
+// now returns the current time of Clock
+func (e *Evaluator) now() time.Time {
+	if e.Clock != nil {
+		return e.Clock()
+	}
+	return time.Now()
+}
+
+// clock returns the value of today or now, which are midnight of the current day and the current time now
+func clock(name string, now time.Time) (util.Token, bool) {
+	switch name {
+	case "today":
+		now = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	case "now":
+	default:
+		return util.Token{}, false
+	}
+	return date(now), true
+}
+
+func date(t time.Time) util.Token {
+	return util.Token{TokenType: util.TokenTypeOperand, TokenTime: &t}
+}
+
+// isDate reports whether the token is a date or a time zone, which aren't numbers
+func isDate(t *util.Token) bool {
+	return t.TokenTime != nil || t.TokenZone != nil
+}
+
+// dated wraps an operator of funcLookup so it works on dates as well. Durations can be added to and subtracted from
+// dates, two dates can be subtracted and compared and dates can be converted to other time zones, like
+// "2026-12-24 18:00 Europe/Berlin in America/New_York". Every other operator rejects dates.
+func dated(operator *util.Operator, apply func(e *Evaluator, stack *util.TokenStack) error) func(e *Evaluator,
+	stack *util.TokenStack) error {
+	op := operator.Op
+	return func(e *Evaluator, stack *util.TokenStack) error {
+		operands := stack.Tokens()
+		dates := false
+		for i := range operands {
+			dates = dates || isDate(&operands[i])
+		}
+		if !dates {
+			return apply(e, stack)
+		}
+		if len(operands) != 2 {
+			return fmt.Errorf("%w: cannot apply %v to %v", ErrIncompatibleUnits, operator, operands[0])
+		}
+		op1 := stack.Pop()
+		op2 := stack.Pop()
+		a, b := op2.TokenTime, op1.TokenTime
+		switch {
+		case op == util.OpAddition && a != nil && isDuration(op1):
+			return later(stack, *a, seconds(op1))
+		case op == util.OpAddition && b != nil && isDuration(op2):
+			return later(stack, *b, seconds(op2))
+		case op == util.OpSubtraction && a != nil && isDuration(op1):
+			return later(stack, *a, -seconds(op1))
+		case op == util.OpSubtraction && a != nil && b != nil:
+			stack.Push(duration(difference(*a, *b)))
+			return nil
+		case op == util.OpConversion && a != nil && op1.TokenZone != nil:
+			stack.Push(date(a.In(op1.TokenZone)))
+			return nil
+		case isComparison(op) && a != nil && b != nil:
+			//the difference is compared to 0, so dates in different time zones are compared by their instant
+			stack.Push(quantity(difference(*a, *b), nil))
+			stack.Push(quantity(0, nil))
+			return apply(e, stack)
+		}
+		return fmt.Errorf("%w: cannot apply %v to %v and %v", ErrIncompatibleUnits, operator, op2, op1)
+	}
+}
+
+func isComparison(op util.Op) bool {
+	switch op {
+	case util.OpLess, util.OpLessEqual, util.OpGreater, util.OpGreaterEqual, util.OpEqual, util.OpNotEqual:
+		return true
+	}
+	return false
+}
+
+// isDuration reports whether the operand is a quantity of time like 3 h
+func isDuration(t *util.Token) bool {
+	return t.TokenUnit != nil && t.TokenUnit.Dimension == util.TimeDimension && t.TokenList == nil
+}
+
+// seconds returns the length of a duration in seconds
+func seconds(t *util.Token) float64 {
+	return t.TokenOperand * t.TokenUnit.Factor
+}
+
+// duration returns the seconds in the largest of the units day, hour and minute they are a whole number of, so the
+// difference of two dates is in days
+func duration(seconds float64) util.Token {
+	for _, symbol := range []string{"d", "h", "min"} {
+		unit, _ := units.Lookup(symbol)
+		if v := seconds / unit.Factor; v == math.Trunc(v) {
+			return quantity(v, unit)
+		}
+	}
+	unit, _ := units.Lookup("s")
+	return quantity(seconds, unit)
+}
+
+// later pushes the date the seconds after t
+func later(stack *util.TokenStack, t time.Time, seconds float64) error {
+	t, err := shift(t, seconds)
+	if err != nil {
+		return err
+	}
+	stack.Push(date(t))
+	return nil
+}
+
+// wall returns the date and time of day of t as if they were in UTC, where every day has 24 hours
+func wall(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
+
+// shift adds the seconds to t. Whole days are added to the wall clock, so adding 1 d keeps the time of day even if
+// the clocks are changed for daylight saving time in between, the rest is added to the instant, so 3 h are always 3
+// hours.
+func shift(t time.Time, seconds float64) (time.Time, error) {
+	if !(math.Abs(seconds) <= maxDateSeconds) {
+		return time.Time{}, fmt.Errorf("%w: %v s after %s", ErrDateRange, seconds, util.FormatDate(t))
+	}
+	days := math.Trunc(seconds / 86400)
+	rest := time.Duration(math.Round((seconds - days*86400) * float64(time.Second)))
+	res := addDays(t, int(days)).Add(rest)
+	if res.Year() < 1 || res.Year() > 9999 {
+		return time.Time{}, fmt.Errorf("%w: %v s after %s", ErrDateRange, seconds, util.FormatDate(t))
+	}
+	return res, nil
+}
+
+// addDays adds the days to the wall clock of t
+func addDays(t time.Time, days int) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day()+days, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+}
+
+// difference returns a - b in seconds, the inverse of shift. Dates in the same time zone are whole days apart by
+// their wall clocks plus the time between the instants, so there is always a whole number of days between two
+// midnights.
+func difference(a, b time.Time) float64 {
+	days := 0.0
+	if a.Location().String() == b.Location().String() {
+		days = math.Trunc(elapsed(wall(a), wall(b)) / 86400)
+		b = addDays(b, int(days))
+	}
+	return days*86400 + elapsed(a, b)
+}
+
+// elapsed returns the seconds between the instants b and a
+func elapsed(a, b time.Time) float64 {
+	return float64(a.Unix()-b.Unix()) + float64(a.Nanosecond()-b.Nanosecond())/float64(time.Second)
+}
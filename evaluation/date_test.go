@@ -0,0 +1,106 @@
+package evaluation
+
+import (
+	"errors"
+	"github.com/niklasstich/calculator/parser"
+	"testing"
+	"time"
+)
+
+func TestDates(t *testing.T) {
+	if _, err := time.LoadLocation("Europe/Berlin"); err != nil {
+		t.Skipf("no tzdata: %v", err)
+	}
+	env := &Environment{}
+	p := parser.Parser{Scope: env}
+	clock := func() time.Time {
+		return time.Date(2026, 10, 19, 14, 30, 0, 0, time.Local)
+	}
+	var tests = []struct {
+		input, result string
+		err           error
+	}{
+		{"today", "2026-10-19", nil},
+		{"now", "2026-10-19 14:30", nil},
+		{"2026-12-24 - today in days", "66 d", nil},
+		{"today - 2026-12-24", "-66 d", nil},
+		{"3h 20m * 4", "800 min", nil},
+		{"1h 30m * 4 in h", "6 h", nil},
+		{"5m 30s in s", "330 s", nil},
+		{"1 d 6 h + 2 h", "32 h", nil},
+		{"now + 1h 45m", "2026-10-19 16:15", nil},
+		{"2 h + today", "2026-10-19 02:00", nil},
+		{"2026-12-24 - 2 wk", "2026-12-10", nil},
+		{"2026-12-24 + 90 s", "2026-12-24 00:01:30", nil},
+		{"2026-12-24 + 1.5 s", "2026-12-24 00:00:01.5", nil},
+		{"2026-01-31T08:00 - 2026-01-30 20:00", "12 h", nil},
+		{"2026-01-31 08:00:30 - 2026-01-31 08:00", "30 s", nil},
+		{"2026-03-28 12:00 Europe/Berlin + 1 d", "2026-03-29 12:00 Europe/Berlin", nil},
+		{"2026-03-30 Europe/Berlin - 2026-03-29 Europe/Berlin", "1 d", nil},
+		{"2026-10-25 01:00 Europe/Berlin + 3 h", "2026-10-25 03:00 Europe/Berlin", nil},
+		{"2026-03-29 01:00 Europe/Berlin + 2 h", "2026-03-29 04:00 Europe/Berlin", nil},
+		{"2026-10-24 12:00 Europe/Berlin + 1 d 1 h", "2026-10-25 13:00 Europe/Berlin", nil},
+		{"2026-10-25 03:00 Europe/Berlin - 2026-10-25 01:00 Europe/Berlin", "3 h", nil},
+		{"2026-10-25 13:00 Europe/Berlin - 2026-10-24 12:00 Europe/Berlin", "25 h", nil},
+		{"2026-12-24 18:00 Europe/Berlin in America/New_York", "2026-12-24 12:00 America/New_York", nil},
+		{"2026-12-24 18:00 UTC - 2026-12-24 18:00 Europe/Berlin", "1 h", nil},
+		{"2026-12-24 12:00 UTC == 2026-12-24 13:00 Europe/Berlin", "true", nil},
+		{"2026-12-24 < 2026-12-25", "true", nil},
+		{"[2026-12-24, 2026-12-31] - today", "[66 d, 73 d]", nil},
+		{"2026-12-24 + 2026-12-25", "", ErrIncompatibleUnits},
+		{"2026-12-24 * 2", "", ErrIncompatibleUnits},
+		{"-today", "", ErrIncompatibleUnits},
+		{"today + 3 km", "", ErrIncompatibleUnits},
+		{"today in h", "", ErrIncompatibleUnits},
+		{"3 h in UTC", "", ErrIncompatibleUnits},
+		{"sqrt(today)", "", ErrIncompatibleUnits},
+		{"today ? 1 : 2", "", ErrIncompatibleUnits},
+		{"today && 1", "", ErrIncompatibleUnits},
+		{"1 && today", "", ErrIncompatibleUnits},
+		{"0 || today", "", ErrIncompatibleUnits},
+		{"not today", "", ErrIncompatibleUnits},
+		{"sum([2026-12-24])", "", ErrIncompatibleUnits},
+		{"mean(today, 1)", "", ErrIncompatibleUnits},
+		{"det([[today]])", "", ErrIncompatibleUnits},
+		{"9999-12-31 + 1 d", "", ErrDateRange},
+	}
+	for _, tt := range tests {
+		tokens, err := p.Tokenize(tt.input)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", tt.input, err)
+		}
+		expression, err := p.ReformToRPN(tokens)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", tt.input, err)
+		}
+		result, err := (&Evaluator{Env: env, Clock: clock}).Evaluate(expression)
+		if !errors.Is(err, tt.err) {
+			t.Fatalf("%s: expected error %v, got %v", tt.input, tt.err, err)
+		}
+		if err == nil && result.String() != tt.result {
+			t.Errorf("%s: expected %s, got %s", tt.input, tt.result, result)
+		}
+	}
+
+	tokens, _ := p.Tokenize("today")
+	if _, err := (&Evaluator{Mode: ModeInteger, Clock: clock}).Evaluate(tokens); !errors.Is(err, ErrIncompatibleUnits) {
+		t.Errorf("Expected error %v in integer mode, got %v", ErrIncompatibleUnits, err)
+	}
+
+	//the clock is read once per evaluation
+	ticks := 0
+	ticking := func() time.Time {
+		ticks++
+		return time.Date(2026, 10, 19, 14, 30, ticks, 0, time.UTC)
+	}
+	tokens, _ = p.Tokenize("now - now")
+	expression, _ := p.ReformToRPN(tokens)
+	if result, err := (&Evaluator{Clock: ticking}).Evaluate(expression); err != nil || result.TokenOperand != 0 {
+		t.Errorf("now - now: expected 0, got %v: %v", result, err)
+	}
+
+	v, err := ParseValue("2026-12-24 15:30 Europe/Berlin")
+	if err != nil || v.TokenTime == nil || v.String() != "2026-12-24 15:30 Europe/Berlin" {
+		t.Errorf("Unexpected value %v: %v", v, err)
+	}
+}
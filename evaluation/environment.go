@@ -0,0 +1,248 @@
+package evaluation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/niklasstich/calculator/parser"
+	"github.com/niklasstich/calculator/util"
+	"sort"
+)
+
+var ErrUnknownFunction = errors.New("unknown function")
+var ErrArgumentCount = errors.New("wrong number of arguments")
+var ErrRecursionDepth = errors.New("maximum recursion depth exceeded")
+
+// DefaultMaxDepth is the number of nested function calls allowed if Evaluator.MaxDepth is not set
+const DefaultMaxDepth = 1000
+
+// Function is a user defined function stored in an Environment
+type Function struct {
+	parser.Definition
+	//starts and branches are the subexpressions of the body, they only have to be found once
+	starts   []int
+	branches []int
+}
+
+func (f *Function) String() string {
+	return f.Source
+}
+
+// Environment holds the user defined functions and variables, it is shared by all evaluations which use it. The zero
+// value is an empty Environment.
+type Environment struct {
+	functions map[string]*Function
+	variables map[string]util.Token
+}
+
+// Define adds the function to the Environment, replacing an existing function with the same name
+func (env *Environment) Define(def parser.Definition) error {
+	f, err := Compile(def)
+	if err != nil {
+		return err
+	}
+	if env.functions == nil {
+		env.functions = make(map[string]*Function)
+	}
+	env.functions[def.Name] = f
+	return nil
+}
+
+// Compile checks the body of the definition and returns a Function which can be called with Evaluator.Call without
+// adding it to an Environment
+func Compile(def parser.Definition) (*Function, error) {
+	starts, branches, err := subexpressions(def.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", parser.ErrInvalidDefinition, err)
+	}
+	return &Function{Definition: def, starts: starts, branches: branches}, nil
+}
+
+// Function returns the function with the given name
+func (env *Environment) Function(name string) (*Function, bool) {
+	f, ok := env.functions[name]
+	return f, ok
+}
+
+// Functions returns all functions sorted by name
+func (env *Environment) Functions() []*Function {
+	functions := make([]*Function, 0, len(env.functions))
+	for _, f := range env.functions {
+		functions = append(functions, f)
+	}
+	sort.Slice(functions, func(i, j int) bool { return functions[i].Name < functions[j].Name })
+	return functions
+}
+
+// Delete removes the function with the given name and reports whether it existed
+func (env *Environment) Delete(name string) bool {
+	_, ok := env.functions[name]
+	delete(env.functions, name)
+	return ok
+}
+
+// IsFunction implements parser.Scope, so the functions of the Environment and the builtin functions can be called in
+// expressions
+func (env *Environment) IsFunction(name string) bool {
+	_, ok := env.functions[name]
+	_, builtin := builtins[name]
+	return ok || builtin
+}
+
+// SetVariable sets the value of a variable, replacing its old value. Variables can be used in expressions, but not in
+// the body of a function.
+func (env *Environment) SetVariable(name string, value util.Token) {
+	if env.variables == nil {
+		env.variables = make(map[string]util.Token)
+	}
+	env.variables[name] = value
+}
+
+// Variable returns the value of the variable with the given name
+func (env *Environment) Variable(name string) (util.Token, bool) {
+	v, ok := env.variables[name]
+	return v, ok
+}
+
+// DeleteVariable removes the variable with the given name and reports whether it existed
+func (env *Environment) DeleteVariable(name string) bool {
+	_, ok := env.variables[name]
+	delete(env.variables, name)
+	return ok
+}
+
+// IsVariable implements parser.VariableScope, so the variables of the Environment can be used in expressions
+func (env *Environment) IsVariable(name string) bool {
+	_, ok := env.variables[name]
+	return ok
+}
+
+// ParseValue reads a value printed by util.Token.String, which is a number with an optional sign and unit, a boolean,
+// a date or a list of values. It is used to store variables as text.
+func ParseValue(s string) (util.Token, error) {
+	tokens, err := parser.TokenizeString(s)
+	if err != nil {
+		return util.Token{}, err
+	}
+	if len(tokens) > 0 && tokens[0].TokenType == util.TokenTypeOperator && tokens[0].TokenOperator.Op == util.OpList {
+		return parseList(s, tokens)
+	}
+	negative := len(tokens) == 2 && tokens[0].TokenType == util.TokenTypeOperator &&
+		tokens[0].TokenOperator.Op == util.OpSubtraction
+	if negative {
+		tokens = tokens[1:]
+	}
+	if len(tokens) != 1 || tokens[0].TokenType != util.TokenTypeOperand {
+		return util.Token{}, fmt.Errorf("%v: %s", ErrInvalidExpression, s)
+	}
+	t := tokens[0]
+	if negative {
+		t.TokenOperand = -t.TokenOperand
+		if t.TokenLiteral != "" {
+			t.TokenLiteral = "-" + t.TokenLiteral
+		}
+		if t.TokenInteger != nil {
+			i := *t.TokenInteger
+			i.Value = -i.Value
+			t.TokenInteger = &i
+		}
+	}
+	return t, nil
+}
+
+// parseList evaluates the tokens of a list, the elements are values like those ParseValue reads
+func parseList(s string, tokens []util.Token) (util.Token, error) {
+	rpn, err := parser.ReformToRPN(tokens)
+	if err != nil {
+		return util.Token{}, err
+	}
+	for _, t := range rpn {
+		if t.TokenType == util.TokenTypeOperator && t.TokenOperator.Op != util.OpList &&
+			t.TokenOperator.Op != util.OpNegation {
+			return util.Token{}, fmt.Errorf("%v: %s", ErrInvalidExpression, s)
+		}
+	}
+	v, err := EvaluateRPNExpression(rpn)
+	if err != nil {
+		return util.Token{}, err
+	}
+	if v.TokenList == nil {
+		return util.Token{}, fmt.Errorf("%v: %s", ErrInvalidExpression, s)
+	}
+	return *v, nil
+}
+
+// variable returns the value of an argument of the frame, or of today and now
+func variable(f *frame, name string) (util.Token, error) {
+	if v, ok := f.args[name]; ok {
+		return v, nil
+	}
+	if v, ok := clock(name, f.now); ok {
+		return v, nil
+	}
+	return util.Token{}, fmt.Errorf("%v: unknown variable %s", ErrInvalidExpression, name)
+}
+
+// call evaluates the function called by the operator with the arguments on top of the stack
+func (e *Evaluator) call(caller *frame, operator *util.Operator, stack *util.TokenStack) error {
+	var f *Function
+	if e.Env != nil {
+		f = e.Env.functions[operator.Name]
+	}
+	if f == nil {
+		if b, ok := builtins[operator.Name]; ok {
+			return callBuiltin(b, operator, stack)
+		}
+		return fmt.Errorf("%w: %s", ErrUnknownFunction, operator.Name)
+	}
+	if operator.Arguments != len(f.Params) {
+		return fmt.Errorf("%w: %s takes %d arguments, got %d", ErrArgumentCount, f.Name, len(f.Params),
+			operator.Arguments)
+	}
+	if caller.depth >= e.maxDepth() {
+		return fmt.Errorf("%w: %d calls of %s", ErrRecursionDepth, caller.depth, f.Name)
+	}
+	return e.evaluateFrame(e.frame(f, caller, stack), stack)
+}
+
+// frame creates the frame of a call of f, its arguments are popped from the stack
+func (e *Evaluator) frame(f *Function, caller *frame, stack *util.TokenStack) *frame {
+	callee := &frame{
+		expression: f.Body,
+		starts:     f.starts,
+		branches:   f.branches,
+		args:       make(map[string]util.Token, len(f.Params)),
+		depth:      caller.depth + 1,
+		limit:      caller.limit,
+		now:        caller.now,
+	}
+	//the arguments were pushed from left to right
+	for i := len(f.Params) - 1; i >= 0; i-- {
+		callee.args[f.Params[i]] = *stack.Pop()
+	}
+	return callee
+}
+
+// Call evaluates the function with the arguments in ModeFloat, the function doesn't have to be part of the Env of the
+// Evaluator. It is used to evaluate the same expression for many values, like the points of a plot.
+func (e *Evaluator) Call(f *Function, args ...util.Token) (*util.Token, error) {
+	if len(args) != len(f.Params) {
+		return nil, fmt.Errorf("%w: %s takes %d arguments, got %d", ErrArgumentCount, f.Name, len(f.Params), len(args))
+	}
+	stack := util.TokenStack{}
+	for _, arg := range args {
+		stack.Push(arg)
+	}
+	caller := &frame{limit: &limiter{ctx: context.Background(), max: e.MaxSteps}, now: e.now()}
+	if err := e.evaluateFrame(e.frame(f, caller, &stack), &stack); err != nil {
+		return nil, err
+	}
+	return stack.Pop(), nil
+}
+
+func (e *Evaluator) maxDepth() int {
+	if e.MaxDepth == 0 {
+		return DefaultMaxDepth
+	}
+	return e.MaxDepth
+}
@@ -0,0 +1,211 @@
+package evaluation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/niklasstich/calculator/parser"
+	"github.com/niklasstich/calculator/units"
+	"github.com/niklasstich/calculator/util"
+	"testing"
+	"time"
+)
+
+func TestFunctions(t *testing.T) {
+	env := &Environment{}
+	p := parser.Parser{Scope: env}
+	for _, input := range []string{
+		"f(x, y) = x^2 + y^2",
+		"g(t) = 2t",
+		"fact(n) = n <= 1 ? 1 : n * fact(n - 1)",
+		"fib(n) = n < 2 ? n : fib(n - 1) + fib(n - 2)",
+		"speed(d, t) = d / t in km/h",
+		"forever(n) = forever(n + 1)",
+		"half(x) = x / 2",
+		"x2(x) = half(x) * 4",
+	} {
+		def, ok, err := p.ParseDefinition(input)
+		if !ok || err != nil {
+			t.Fatalf("Failed to parse %s: %v", input, err)
+		}
+		if err := env.Define(*def); err != nil {
+			t.Fatalf("Failed to define %s: %v", input, err)
+		}
+	}
+
+	var tests = []struct {
+		input, result string
+		err           error
+	}{
+		{"f(3, 4)", "25", nil},
+		{"g(5) + 1", "11", nil},
+		{"3 g + g(1) g", "5 g", nil},
+		{"fact(6)", "720", nil},
+		{"fib(15)", "610", nil},
+		{"speed(100 m, 10 s)", "36 km/h", nil},
+		{"x2(3)", "6", nil},
+		{"f(1)", "", ErrArgumentCount},
+		{"forever(1)", "", ErrRecursionDepth},
+	}
+
+	for _, tt := range tests {
+		testName := fmt.Sprintf("%s=%s", tt.input, tt.result)
+		t.Run(testName, func(t *testing.T) {
+			tokens, err := p.Tokenize(tt.input)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			tokens, err = parser.ReformToRPN(tokens)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			e := Evaluator{Env: env, MaxDepth: 100}
+			result, err := e.Evaluate(tokens)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Expected error %v, got %v", tt.err, err)
+			}
+			if err == nil && result.String() != tt.result {
+				t.Errorf("Expected %s, got %s", tt.result, result)
+			}
+		})
+	}
+}
+
+func TestEnvironment(t *testing.T) {
+	env := &Environment{}
+	p := parser.Parser{Scope: env}
+	for _, input := range []string{"b(x) = x", "a(x) = 2x", "b(x) = 3x"} {
+		def, _, err := p.ParseDefinition(input)
+		if err != nil {
+			t.Fatalf("Failed to parse %s: %v", input, err)
+		}
+		if err := env.Define(*def); err != nil {
+			t.Fatalf("Failed to define %s: %v", input, err)
+		}
+	}
+	if got := fmt.Sprint(env.Functions()); got != "[a(x) = 2x b(x) = 3x]" {
+		t.Errorf("Unexpected functions %s", got)
+	}
+	if !env.Delete("a") || env.Delete("a") || env.IsFunction("a") {
+		t.Errorf("Failed to delete a")
+	}
+
+	//a deleted function can't be called anymore
+	tokens := parser.RPNExpression{{TokenType: util.TokenTypeOperand, TokenOperand: 1},
+		{TokenType: util.TokenTypeOperator, TokenOperator: &util.Operator{Name: "a", Op: util.OpCall, Arguments: 1}}}
+	if _, err := (&Evaluator{Env: env}).Evaluate(tokens); !errors.Is(err, ErrUnknownFunction) {
+		t.Errorf("Expected error %v, got %v", ErrUnknownFunction, err)
+	}
+}
+
+func TestVariables(t *testing.T) {
+	env := &Environment{}
+	env.SetVariable("ans", util.Token{TokenType: util.TokenTypeOperand, TokenOperand: 6, TokenLiteral: "6"})
+	km, _ := units.Lookup("km")
+	env.SetVariable("$2", util.Token{TokenType: util.TokenTypeOperand, TokenOperand: 2, TokenUnit: km})
+	env.SetVariable("sq", util.Token{TokenType: util.TokenTypeOperand, TokenOperand: 3})
+	p := parser.Parser{Scope: env}
+	def, _, err := p.ParseDefinition("sq(x) = x * x")
+	if err != nil {
+		t.Fatalf("Failed to parse sq: %v", err)
+	}
+	if err := env.Define(*def); err != nil {
+		t.Fatalf("Failed to define sq: %v", err)
+	}
+
+	var tests = []struct {
+		input  string
+		mode   Mode
+		result string
+		err    error
+	}{
+		{"ans * 2", ModeFloat, "12", nil},
+		{"$2 in m", ModeFloat, "2000 m", nil},
+		{"sq(sq)", ModeFloat, "9", nil},
+		{"ans // 4", ModeInteger, "1", nil},
+		{"ans / 4", ModeBig, "1.5", nil},
+		{"$2", ModeBig, "", ErrIncompatibleUnits},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tokens, err := p.Tokenize(tt.input)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			tokens, err = parser.ReformToRPN(tokens)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			result, err := (&Evaluator{Env: env, Mode: tt.mode}).Evaluate(tokens)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Expected error %v, got %v", tt.err, err)
+			}
+			if err == nil && result.String() != tt.result {
+				t.Errorf("Expected %s, got %s", tt.result, result)
+			}
+		})
+	}
+
+	if !env.DeleteVariable("ans") || env.DeleteVariable("ans") || env.IsVariable("ans") {
+		t.Errorf("Failed to delete ans")
+	}
+	if _, err := p.Tokenize("ans + $3"); !errors.Is(err, parser.ErrInvalidToken) {
+		t.Errorf("Expected error %v, got %v", parser.ErrInvalidToken, err)
+	}
+}
+
+func TestLimits(t *testing.T) {
+	env := &Environment{}
+	p := parser.Parser{Scope: env}
+	def, _, err := p.ParseDefinition("fib(n) = n < 2 ? n : fib(n - 1) + fib(n - 2)")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := env.Define(*def); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	parse := func(input string) parser.RPNExpression {
+		tokens, err := p.Tokenize(input)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		rpn, err := p.ReformToRPN(tokens)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return rpn
+	}
+
+	expired, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	<-expired.Done()
+
+	var tests = []struct {
+		name  string
+		e     Evaluator
+		ctx   context.Context
+		input string
+		err   error
+	}{
+		{"steps", Evaluator{Env: env, MaxSteps: 100}, context.Background(), "fib(5)", nil},
+		{"too many steps", Evaluator{Env: env, MaxSteps: 100}, context.Background(), "fib(10)", ErrTooManySteps},
+		{"integer steps", Evaluator{Mode: ModeInteger, MaxSteps: 2}, context.Background(), "1 + 2 + 3 + 4", ErrTooManySteps},
+		{"big steps", Evaluator{Mode: ModeBig, MaxSteps: 2}, context.Background(), "1 + 2 + 3 + 4", ErrTooManySteps},
+		{"precision", Evaluator{Mode: ModeBig, Precision: 1024, MaxPrecision: 1024}, context.Background(), "1 / 3", nil},
+		{"too high precision", Evaluator{Mode: ModeBig, Precision: 1 << 20, MaxPrecision: 1024},
+			context.Background(), "1 / 3", ErrPrecisionTooHigh},
+		{"default precision", Evaluator{Mode: ModeBig, MaxPrecision: 64}, context.Background(), "1 / 3",
+			ErrPrecisionTooHigh},
+		{"deadline", Evaluator{Env: env}, expired, "fib(30)", context.DeadlineExceeded},
+		{"deadline integer", Evaluator{Mode: ModeInteger}, expired, "1 + 1", context.DeadlineExceeded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.e.EvaluateContext(tt.ctx, parse(tt.input))
+			if !errors.Is(err, tt.err) {
+				t.Errorf("Expected error %v, got %v", tt.err, err)
+			}
+		})
+	}
+}
@@ -1,95 +1,672 @@
 package evaluation
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/niklasstich/calculator/parser"
 	"github.com/niklasstich/calculator/util"
 	"math"
+	"time"
 )
 
 var ErrDivByZero = errors.New("division by 0")
 var ErrNotImplemented = errors.New("factorial is not yet implemented")
 var ErrInvalidExpression = errors.New("provided expression is not valid")
 
-var funcLookup = map[util.Op]func(stack *util.TokenStack) error{
-	util.OpAddition: func(stack *util.TokenStack) error {
+var ErrIncompatibleUnits = errors.New("operands have incompatible units")
+var ErrNotAnInteger = errors.New("operand is not an integer")
+var ErrNegativeShift = errors.New("shift count must not be negative")
+var ErrUnsupportedOperator = errors.New("operator is not supported in this mode")
+var ErrTooManySteps = errors.New("evaluation takes too many steps")
+var ErrPrecisionTooHigh = errors.New("precision is too high")
+var ErrOverflow = errors.New("result is too large")
+
+// Mode selects how operands are represented during evaluation
+type Mode int
+
+const (
+	// ModeFloat evaluates with float64 operands which may have units
+	ModeFloat Mode = iota
+	// ModeInteger evaluates with fixed width integers that wrap around, see Evaluator.IntType
+	ModeInteger
+	// ModeBig evaluates with arbitrary precision floating point numbers, see Evaluator.Precision. Their binary exponent
+	// is limited to util.MaxBigExp, larger and smaller results are an ErrOverflow.
+	ModeBig
+)
+
+// PercentMode selects the meaning of the postfix '%' operator
+type PercentMode int
+
+const (
+	// PercentCalculator works like a desk calculator: after '+' or '-' a percentage is taken of the left operand,
+	// so 200 + 10% is 220 and 200 - 10% is 180. Everywhere else b% is b/100, so 50 * 20% is 10.
+	PercentCalculator PercentMode = iota
+	// PercentDivide always divides by 100, so 200 + 10% is 200.1
+	PercentDivide
+)
+
+// Evaluator evaluates RPN expressions with the given settings, the zero value evaluates in ModeFloat.
+type Evaluator struct {
+	Mode Mode
+	// IntType is the width and signedness of all operands in ModeInteger, int64 if not set
+	IntType util.IntType
+	// Percent selects the meaning of '%', PercentCalculator if not set
+	Percent PercentMode
+	// Precision is the number of mantissa bits in ModeBig, DefaultPrecision if not set
+	Precision uint
+	// Env contains the user defined functions which can be called, there are none if it is not set
+	Env *Environment
+	// MaxDepth limits the number of nested function calls, DefaultMaxDepth if not set
+	MaxDepth int
+	// MaxSteps limits the number of operators applied during an evaluation, including those in function bodies
+	MaxSteps int
+	// MaxPrecision limits Precision, so it can be set from untrusted input
+	MaxPrecision uint
+	// Trace is called with every Step of the evaluation if it is set
+	Trace func(Step)
+	// Clock returns the current time, which today and now are read from, time.Now if not set
+	Clock func() time.Time
+}
+
+var funcLookup = map[util.Op]func(e *Evaluator, stack *util.TokenStack) error{
+	util.OpAddition: func(e *Evaluator, stack *util.TokenStack) error {
 		op1 := stack.Pop()
 		op2 := stack.Pop()
+		if e.isPercentage(op1) {
+			stack.Push(quantity(op2.TokenOperand+op2.TokenOperand*op1.TokenOperand, op2.TokenUnit))
+			return nil
+		}
+		if !op2.TokenUnit.Compatible(op1.TokenUnit) {
+			return fmt.Errorf("%w: cannot add %v to %v", ErrIncompatibleUnits, op1, op2)
+		}
+		//the result is in the unit of the left operand
 		stack.Push(util.Token{
 			TokenType:    util.TokenTypeOperand,
-			TokenOperand: op1.TokenOperand + op2.TokenOperand,
+			TokenOperand: op2.TokenOperand + op1.TokenOperand*op1.TokenUnit.ConversionFactor(op2.TokenUnit),
+			TokenUnit:    op2.TokenUnit,
 		})
 		return nil
 	},
-	util.OpSubtraction: func(stack *util.TokenStack) error {
+	util.OpSubtraction: func(e *Evaluator, stack *util.TokenStack) error {
 		op1 := stack.Pop()
 		op2 := stack.Pop()
+		if e.isPercentage(op1) {
+			stack.Push(quantity(op2.TokenOperand-op2.TokenOperand*op1.TokenOperand, op2.TokenUnit))
+			return nil
+		}
+		if !op2.TokenUnit.Compatible(op1.TokenUnit) {
+			return fmt.Errorf("%w: cannot subtract %v from %v", ErrIncompatibleUnits, op1, op2)
+		}
 		stack.Push(util.Token{
 			TokenType:    util.TokenTypeOperand,
-			TokenOperand: op2.TokenOperand - op1.TokenOperand,
+			TokenOperand: op2.TokenOperand - op1.TokenOperand*op1.TokenUnit.ConversionFactor(op2.TokenUnit),
+			TokenUnit:    op2.TokenUnit,
 		})
 		return nil
 	},
-	util.OpMultiplication: func(stack *util.TokenStack) error {
+	util.OpMultiplication: func(e *Evaluator, stack *util.TokenStack) error {
 		op1 := stack.Pop()
 		op2 := stack.Pop()
-		stack.Push(util.Token{
-			TokenType:    util.TokenTypeOperand,
-			TokenOperand: op1.TokenOperand * op2.TokenOperand,
-		})
+		stack.Push(quantity(op1.TokenOperand*op2.TokenOperand, op2.TokenUnit.Mul(op1.TokenUnit)))
 		return nil
 	},
-	util.OpDivision: func(stack *util.TokenStack) error {
+	util.OpDivision: func(e *Evaluator, stack *util.TokenStack) error {
 		op1 := stack.Pop()
 		op2 := stack.Pop()
-		if op2.TokenOperand == 0 {
+		if op1.TokenOperand == 0 {
 			return ErrDivByZero
 		}
+		stack.Push(quantity(op2.TokenOperand/op1.TokenOperand, op2.TokenUnit.Div(op1.TokenUnit)))
+		return nil
+	},
+	util.OpIntegerDivision: func(e *Evaluator, stack *util.TokenStack) error {
+		op1 := stack.Pop()
+		op2 := stack.Pop()
+		if op1.TokenOperand == 0 {
+			return ErrDivByZero
+		}
+		//units are folded before rounding, so 7 km // 3 m is 2333 and not 2000
+		res := quantity(op2.TokenOperand/op1.TokenOperand, op2.TokenUnit.Div(op1.TokenUnit))
+		res.TokenOperand = math.Floor(res.TokenOperand)
+		stack.Push(res)
+		return nil
+	},
+	util.OpModulo: func(e *Evaluator, stack *util.TokenStack) error {
+		return remainder(stack, floorMod)
+	},
+	util.OpRemainder: func(e *Evaluator, stack *util.TokenStack) error {
+		return remainder(stack, math.Mod)
+	},
+	util.OpExponentiation: func(e *Evaluator, stack *util.TokenStack) error {
+		op1 := stack.Pop()
+		op2 := stack.Pop()
+		if op1.TokenUnit != nil {
+			return fmt.Errorf("%w: exponent %v is not dimensionless", ErrIncompatibleUnits, op1)
+		}
+		var unit *util.Unit
+		if op2.TokenUnit != nil {
+			//units can only be raised to integer powers, m^0.5 has no meaning
+			if op1.TokenOperand != math.Trunc(op1.TokenOperand) {
+				return fmt.Errorf("%w: cannot raise %v to non-integer power %v", ErrIncompatibleUnits, op2, op1)
+			}
+			unit = op2.TokenUnit.Pow(int(op1.TokenOperand))
+		}
+		stack.Push(quantity(math.Pow(op2.TokenOperand, op1.TokenOperand), unit))
+		return nil
+	},
+	util.OpConversion: func(e *Evaluator, stack *util.TokenStack) error {
+		//only the unit of the right operand is used, its value is ignored
+		op1 := stack.Pop()
+		op2 := stack.Pop()
+		if op1.TokenUnit == nil || !op2.TokenUnit.Compatible(op1.TokenUnit) {
+			return fmt.Errorf("%w: cannot convert %v to %v", ErrIncompatibleUnits, op2, op1.TokenUnit)
+		}
 		stack.Push(util.Token{
 			TokenType:    util.TokenTypeOperand,
-			TokenOperand: op2.TokenOperand / op1.TokenOperand,
+			TokenOperand: op2.TokenOperand * op2.TokenUnit.ConversionFactor(op1.TokenUnit),
+			TokenUnit:    op1.TokenUnit,
 		})
 		return nil
 	},
-	util.OpExponentiation: func(stack *util.TokenStack) error {
-		op1 := stack.Pop()
-		op2 := stack.Pop()
+	util.OpNegation: func(e *Evaluator, stack *util.TokenStack) error {
+		op := stack.Pop()
+		res := quantity(-op.TokenOperand, op.TokenUnit)
+		//-10% is still a percentage, so 200 + -10% is 180
+		res.TokenPercent = op.TokenPercent
+		stack.Push(res)
+		return nil
+	},
+	util.OpBitwiseNot: func(e *Evaluator, stack *util.TokenStack) error {
+		op := stack.Pop()
+		a, err := toInt64(op)
+		if err != nil {
+			return err
+		}
 		stack.Push(util.Token{
 			TokenType:    util.TokenTypeOperand,
-			TokenOperand: math.Pow(op2.TokenOperand, op1.TokenOperand),
+			TokenOperand: float64(^a),
+		})
+		return nil
+	},
+	util.OpBitwiseAnd: func(e *Evaluator, stack *util.TokenStack) error {
+		return bitwise(stack, func(a, b int64) (int64, error) { return a & b, nil })
+	},
+	util.OpBitwiseOr: func(e *Evaluator, stack *util.TokenStack) error {
+		return bitwise(stack, func(a, b int64) (int64, error) { return a | b, nil })
+	},
+	util.OpBitwiseXor: func(e *Evaluator, stack *util.TokenStack) error {
+		return bitwise(stack, func(a, b int64) (int64, error) { return a ^ b, nil })
+	},
+	util.OpShiftLeft: func(e *Evaluator, stack *util.TokenStack) error {
+		return bitwise(stack, func(a, b int64) (int64, error) {
+			if b < 0 {
+				return 0, ErrNegativeShift
+			}
+			return a << uint64(b), nil
 		})
+	},
+	util.OpShiftRight: func(e *Evaluator, stack *util.TokenStack) error {
+		return bitwise(stack, func(a, b int64) (int64, error) {
+			if b < 0 {
+				return 0, ErrNegativeShift
+			}
+			return a >> uint64(b), nil
+		})
+	},
+	util.OpPercent: func(e *Evaluator, stack *util.TokenStack) error {
+		op := stack.Pop()
+		res := quantity(op.TokenOperand/100, op.TokenUnit)
+		res.TokenPercent = true
+		stack.Push(res)
+		return nil
+	},
+	util.OpLess: func(e *Evaluator, stack *util.TokenStack) error {
+		return compare(stack, func(a, b float64) bool { return a < b })
+	},
+	util.OpLessEqual: func(e *Evaluator, stack *util.TokenStack) error {
+		return compare(stack, func(a, b float64) bool { return a <= b })
+	},
+	util.OpGreater: func(e *Evaluator, stack *util.TokenStack) error {
+		return compare(stack, func(a, b float64) bool { return a > b })
+	},
+	util.OpGreaterEqual: func(e *Evaluator, stack *util.TokenStack) error {
+		return compare(stack, func(a, b float64) bool { return a >= b })
+	},
+	util.OpEqual: func(e *Evaluator, stack *util.TokenStack) error {
+		return compare(stack, func(a, b float64) bool { return a == b })
+	},
+	util.OpNotEqual: func(e *Evaluator, stack *util.TokenStack) error {
+		return compare(stack, func(a, b float64) bool { return a != b })
+	},
+	util.OpNot: func(e *Evaluator, stack *util.TokenStack) error {
+		b, err := truthy(stack.Pop())
+		if err != nil {
+			return err
+		}
+		stack.Push(boolean(!b))
 		return nil
 	},
-	util.OpFactorial: func(stack *util.TokenStack) error {
+	util.OpFactorial: func(e *Evaluator, stack *util.TokenStack) error {
 		//factorial is more complicated than i thought, because we first need to assure that the token we pop is an int
 		return ErrNotImplemented
 	},
 }
 
+// quantity creates an operand token, units which cancelled out completely (like in km/m) are folded into the value
+func quantity(value float64, unit *util.Unit) util.Token {
+	if unit != nil && unit.Dimensionless() {
+		value *= unit.Factor
+		unit = nil
+	}
+	return util.Token{
+		TokenType:    util.TokenTypeOperand,
+		TokenOperand: value,
+		TokenUnit:    unit,
+	}
+}
+
+// isPercentage reports whether the right operand of '+' or '-' is a percentage of the left operand
+func (e *Evaluator) isPercentage(op *util.Token) bool {
+	return e.Percent == PercentCalculator && op.TokenPercent && op.TokenUnit == nil
+}
+
+// compare applies f to the two topmost operands, the right operand is converted to the unit of the left one first
+func compare(stack *util.TokenStack, f func(a, b float64) bool) error {
+	op1 := stack.Pop()
+	op2 := stack.Pop()
+	if !op2.TokenUnit.Compatible(op1.TokenUnit) {
+		return fmt.Errorf("%w: cannot compare %v to %v", ErrIncompatibleUnits, op2, op1)
+	}
+	stack.Push(boolean(f(op2.TokenOperand, op1.TokenOperand*op1.TokenUnit.ConversionFactor(op2.TokenUnit))))
+	return nil
+}
+
+// compared returns the result of the comparison op of two operands, c is negative, zero or positive if the left operand
+// is less than, equal to or greater than the right one
+func compared(op util.Op, c int) bool {
+	switch op {
+	case util.OpLess:
+		return c < 0
+	case util.OpLessEqual:
+		return c <= 0
+	case util.OpGreater:
+		return c > 0
+	case util.OpGreaterEqual:
+		return c >= 0
+	case util.OpEqual:
+		return c == 0
+	}
+	return c != 0
+}
+
+// truthy reports whether an operand counts as true, which is every number except 0. Dates are neither true nor false.
+func truthy(t *util.Token) (bool, error) {
+	if isDate(t) {
+		return false, fmt.Errorf("%w: conditions take numbers, got %v", ErrIncompatibleUnits, t)
+	}
+	if t.TokenBig != nil {
+		//the float64 of tiny values is 0
+		return t.TokenBig.Sign() != 0, nil
+	}
+	return t.TokenOperand != 0, nil
+}
+
+func boolean(b bool) util.Token {
+	t := util.Token{
+		TokenType:    util.TokenTypeOperand,
+		TokenBoolean: true,
+	}
+	if b {
+		t.TokenOperand = 1
+	}
+	return t
+}
+
+// remainder applies f to the two topmost operands, the divisor is converted to the unit of the dividend first
+func remainder(stack *util.TokenStack, f func(a, b float64) float64) error {
+	op1 := stack.Pop()
+	op2 := stack.Pop()
+	if !op2.TokenUnit.Compatible(op1.TokenUnit) {
+		return fmt.Errorf("%w: cannot divide %v by %v with remainder", ErrIncompatibleUnits, op2, op1)
+	}
+	b := op1.TokenOperand * op1.TokenUnit.ConversionFactor(op2.TokenUnit)
+	if b == 0 {
+		return ErrDivByZero
+	}
+	stack.Push(quantity(f(op2.TokenOperand, b), op2.TokenUnit))
+	return nil
+}
+
+// floorMod returns the remainder of the floored division a/b, which has the sign of b
+func floorMod(a, b float64) float64 {
+	r := math.Mod(a, b)
+	if r != 0 && (r < 0) != (b < 0) {
+		r += b
+	}
+	return r
+}
+
+// bitwise applies f to the two topmost operands, which have to be integers
+func bitwise(stack *util.TokenStack, f func(a, b int64) (int64, error)) error {
+	op1 := stack.Pop()
+	op2 := stack.Pop()
+	b, err := toInt64(op1)
+	if err != nil {
+		return err
+	}
+	a, err := toInt64(op2)
+	if err != nil {
+		return err
+	}
+	res, err := f(a, b)
+	if err != nil {
+		return err
+	}
+	stack.Push(util.Token{
+		TokenType:    util.TokenTypeOperand,
+		TokenOperand: float64(res),
+	})
+	return nil
+}
+
+func toInt64(t *util.Token) (int64, error) {
+	if t.TokenUnit != nil {
+		return 0, fmt.Errorf("%w: %v", ErrIncompatibleUnits, t)
+	}
+	if t.TokenOperand != math.Trunc(t.TokenOperand) || math.Abs(t.TokenOperand) >= 1<<63 {
+		return 0, fmt.Errorf("%w: %v", ErrNotAnInteger, t)
+	}
+	return int64(t.TokenOperand), nil
+}
+
+// EvaluateRPNExpression evaluates the expression with float64 operands
 func EvaluateRPNExpression(expression parser.RPNExpression) (result *util.Token, err error) {
+	return (&Evaluator{}).Evaluate(expression)
+}
+
+// Evaluate evaluates the expression in the Mode of the Evaluator
+func (e *Evaluator) Evaluate(expression parser.RPNExpression) (result *util.Token, err error) {
+	return e.EvaluateContext(context.Background(), expression)
+}
+
+// EvaluateContext works like Evaluate, but stops with the error of ctx once ctx is done
+func (e *Evaluator) EvaluateContext(ctx context.Context, expression parser.RPNExpression) (result *util.Token, err error) {
+	//every mode relies on the operands being checked here, so malformed expressions never reach the stack operations
+	starts, branches, err := subexpressions(expression)
+	if err != nil {
+		return nil, err
+	}
+	if e.Mode == ModeBig && e.MaxPrecision > 0 && e.precision() > e.MaxPrecision {
+		return nil, fmt.Errorf("%w: %d bits, at most %d are allowed", ErrPrecisionTooHigh, e.precision(),
+			e.MaxPrecision)
+	}
+	limit := &limiter{ctx: ctx, max: e.MaxSteps}
 	stack := util.TokenStack{}
-	for _, token := range expression {
-		if token.TokenType == util.TokenTypeOperand {
-			stack.Push(token)
-		} else {
-			err = funcLookup[token.TokenOperator.Op](&stack)
+	top := &frame{expression: expression, starts: starts, branches: branches, limit: limit, now: e.now()}
+	if e.Env != nil {
+		//the variables of the Environment are the arguments of the expression
+		top.args = e.Env.variables
+	}
+	if err := e.evaluateFrame(top, &stack); err != nil {
+		return nil, err
+	}
+	return stack.Pop(), nil
+}
+
+// frame is an expression being evaluated, either the expression passed to Evaluate or the body of a function with
+// the arguments it was called with
+type frame struct {
+	expression parser.RPNExpression
+	starts     []int
+	branches   []int
+	args       map[string]util.Token
+	//depth is the number of function calls which lead to this frame
+	depth int
+	limit *limiter
+	//now is read from the Clock once per evaluation, so now - now is 0
+	now time.Time
+}
+
+// limiter counts the steps of an evaluation, which are the operators applied, and stops it once there were too many
+// steps or its context is done
+type limiter struct {
+	ctx   context.Context
+	max   int
+	steps int
+}
+
+func (l *limiter) step() error {
+	l.steps++
+	if l.max > 0 && l.steps > l.max {
+		return fmt.Errorf("%w: at most %d are allowed", ErrTooManySteps, l.max)
+	}
+	select {
+	case <-l.ctx.Done():
+		return fmt.Errorf("evaluation stopped after %d steps: %w", l.steps, l.ctx.Err())
+	default:
+		return nil
+	}
+}
+
+// subexpressions returns the index of the first token of the subexpression ending at each token of the RPN
+// expression, and the branches where the evaluation may skip operands. The branch of a token is the index of the
+// short-circuit operator whose condition or first alternative ends at the token, and 0 for all other tokens. It also
+// makes sure each operator has enough operands and the whole expression has exactly one result.
+func subexpressions(expression parser.RPNExpression) (starts, branches []int, err error) {
+	starts = make([]int, len(expression))
+	branches = make([]int, len(expression))
+	//open holds the start of all subexpressions which are not yet the operand of an operator
+	open := make([]int, 0, len(expression))
+	for i, token := range expression {
+		starts[i] = i
+		if token.TokenType == util.TokenTypeOperator {
+			n := token.TokenOperator.Arity()
+			if len(open) < n {
+				return nil, nil, fmt.Errorf("%v: not enough operands for %v", ErrInvalidExpression, token)
+			}
+			//operators without operands, like an empty list, start at themselves
+			if n > 0 {
+				starts[i] = open[len(open)-n]
+			}
+			open = open[:len(open)-n]
+			//the operands end right before the operator and right before the start of the following operand
+			switch token.TokenOperator.Op {
+			case util.OpAnd, util.OpOr:
+				branches[starts[i-1]-1] = i
+			case util.OpTernary:
+				alternative := starts[i-1] - 1
+				branches[alternative] = i
+				branches[starts[alternative]-1] = i
+			}
+		}
+		open = append(open, starts[i])
+	}
+	if len(open) == 0 {
+		return nil, nil, fmt.Errorf("%v: expression is empty", ErrInvalidExpression)
+	}
+	if len(open) > 1 {
+		return nil, nil, fmt.Errorf("%v: There were extra tokens on the stack after evaluation of expression", ErrInvalidExpression)
+	}
+	return starts, branches, nil
+}
+
+// evaluateFrame pushes the result of the expression of the frame onto the stack. The tokens are evaluated from left
+// to right in the Mode of the Evaluator, the short-circuit operators &&, || and ?: jump over the operands they don't
+// need.
+func (e *Evaluator) evaluateFrame(f *frame, stack *util.TokenStack) error {
+	evaluate := e.evaluateToken
+	switch e.Mode {
+	case ModeInteger:
+		evaluate = e.evaluateIntegerToken
+	case ModeBig:
+		evaluate = e.evaluateBigToken
+	}
+	i := 0
+	for i < len(f.expression) {
+		if err := evaluate(f, f.expression[i], stack); err != nil {
+			return err
+		}
+		end := i
+		i++
+		//a branch which skips operands completes its operator, which may be an operand of another one
+		for f.branches[end] != 0 {
+			operator := f.branches[end]
+			next, err := e.branch(f, end, operator, stack)
 			if err != nil {
-				return nil, err
+				return err
+			}
+			i = next
+			if next != operator+1 {
+				break
 			}
+			end = operator
 		}
 	}
+	return nil
+}
+
+// branch is called once the operand of a short-circuit operator ending at end was evaluated. It returns the index of
+// the next token, which is right after the operator if the remaining operands are skipped.
+func (e *Evaluator) branch(f *frame, end, operator int, stack *util.TokenStack) (int, error) {
+	token := f.expression[operator]
+	op := token.TokenOperator.Op
+	//the last operand ends right before the operator
+	last := f.starts[operator-1]
+	first, err := truthy(stack.Peek())
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case op == util.OpTernary && end == last-1:
+		//the first alternative was evaluated, so the second one is skipped
+	case op == util.OpTernary && !first:
+		return last, nil
+	case op == util.OpAnd && !first, op == util.OpOr && first:
+	default:
+		return end + 1, nil
+	}
+	if err := f.limit.step(); err != nil {
+		return 0, err
+	}
+	before := e.snapshot(stack)
+	value := *stack.Pop()
+	if op == util.OpTernary {
+		stack.Pop()
+	} else {
+		value = e.truth(first)
+	}
+	stack.Push(value)
+	e.trace(token, f.depth, before, stack)
+	return operator + 1, nil
+}
 
-	//pop element, assure that it is an operand, otherwise our expression was malformed
-	result = stack.Pop()
-	if result.TokenType != util.TokenTypeOperand {
-		return nil, fmt.Errorf("%v: Top token after expression evaluation was not an operand", ErrInvalidExpression)
+// logical applies not or a short-circuit operator whose operands were all evaluated, they stay on the stack until the
+// operator is applied so they show up in a trace
+func (e *Evaluator) logical(op util.Op, stack *util.TokenStack) error {
+	value := *stack.Pop()
+	switch op {
+	case util.OpNot:
+		b, err := truthy(&value)
+		if err != nil {
+			return err
+		}
+		value = e.truth(!b)
+	case util.OpAnd, util.OpOr:
+		stack.Pop()
+		b, err := truthy(&value)
+		if err != nil {
+			return err
+		}
+		value = e.truth(b)
+	default:
+		stack.Pop()
 	}
+	stack.Push(value)
+	return nil
+}
 
-	//if there are still tokens on the stack, again the expression is malformed
-	if stack.HasElements() {
-		return nil, fmt.Errorf("%v: There were extra tokens on the stack after evaluation of expression", ErrInvalidExpression)
+// truth returns a truth value the operators of the Mode of the Evaluator work with
+func (e *Evaluator) truth(b bool) util.Token {
+	var t util.Token
+	switch e.Mode {
+	case ModeInteger:
+		var v uint64
+		if b {
+			v = 1
+		}
+		t = integerToken(v, e.intType())
+	case ModeBig:
+		var v int64
+		if b {
+			v = 1
+		}
+		t = bigToken(newBig(e.precision()).SetInt64(v))
+	default:
+		return boolean(b)
 	}
+	t.TokenBoolean = true
+	return t
+}
 
-	return
+// evaluateToken pushes an operand onto the stack or applies an operator to the operands on top of it
+func (e *Evaluator) evaluateToken(f *frame, token util.Token, stack *util.TokenStack) error {
+	before := e.snapshot(stack)
+	if token.TokenType == util.TokenTypeOperand && token.TokenName != "" {
+		arg, err := variable(f, token.TokenName)
+		if err != nil {
+			return err
+		}
+		//variables may hold the results of other modes
+		arg.TokenInteger = nil
+		arg.TokenLiteral = ""
+		arg.TokenBig = nil
+		stack.Push(arg)
+		e.trace(token, f.depth, before, stack)
+		return nil
+	}
+	if token.TokenType == util.TokenTypeOperand {
+		//the exact value of literals is only needed in ModeInteger and ModeBig
+		token.TokenInteger = nil
+		token.TokenLiteral = ""
+		stack.Push(token)
+		e.trace(token, f.depth, before, stack)
+		return nil
+	}
+	if err := f.limit.step(); err != nil {
+		return err
+	}
+	op := token.TokenOperator.Op
+	switch op {
+	case util.OpAnd, util.OpOr, util.OpTernary:
+		if err := e.logical(op, stack); err != nil {
+			return err
+		}
+	case util.OpCall:
+		if err := e.call(f, token.TokenOperator, stack); err != nil {
+			return err
+		}
+	case util.OpList:
+		stack.Push(popList(stack, token.TokenOperator.Arguments))
+	default:
+		apply := funcLookup[op]
+		if apply == nil {
+			return fmt.Errorf("%w: %v", ErrUnsupportedOperator, token)
+		}
+		done, err := false, error(nil)
+		switch op {
+		case util.OpMultiplication:
+			done, err = product(stack)
+		case util.OpExponentiation:
+			done, err = power(stack)
+		}
+		if !done {
+			err = e.elementwise(dated(token.TokenOperator, apply), token.TokenOperator.Arity(), stack)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	e.trace(token, f.depth, before, stack)
+	return nil
 }
@@ -1,9 +1,11 @@
 package evaluation
 
 import (
+	"errors"
 	"fmt"
 	"github.com/niklasstich/calculator/parser"
 	"github.com/niklasstich/calculator/util"
+	"strings"
 	"testing"
 )
 
@@ -83,6 +85,14 @@ func TestTokenizerIntoParserIntoEvaluation(t *testing.T) {
 			"3    +   3",
 			"6",
 		},
+		{
+			"2(3+4)",
+			"14",
+		},
+		{
+			"(1+2)(3+4)",
+			"21",
+		},
 	}
 
 	for _, tt := range tests {
@@ -107,3 +117,215 @@ func TestTokenizerIntoParserIntoEvaluation(t *testing.T) {
 		})
 	}
 }
+
+func TestUnitArithmetic(t *testing.T) {
+	var tests = []struct {
+		input, result string
+		err           error
+	}{
+		{"3 km / 20 min in km/h", "9 km/h", nil},
+		{"3 km + 200 m", "3.2 km", nil},
+		{"3 km + 200 m in m", "3200 m", nil},
+		{"1 km / 1 m", "1000", nil},
+		{"5 m^2 * 2 m", "10 m^3", nil},
+		{"2 kg * 3 m/s^2", "6 kg*m/s^2", nil},
+		{"(2 m)^2", "4 m^2", nil},
+		{"1 kWh in J", "3.6e+06 J", nil},
+		{"12 inch to ft", "1 ft", nil},
+		{"3 m + 2 s", "", ErrIncompatibleUnits},
+		{"3 m - 2", "", ErrIncompatibleUnits},
+		{"3 m in s", "", ErrIncompatibleUnits},
+		{"3 in m", "", ErrIncompatibleUnits},
+		{"2 ^ 3 m", "", ErrIncompatibleUnits},
+		{"2 m ^ 0.5", "", ErrIncompatibleUnits},
+	}
+
+	for _, tt := range tests {
+		testName := fmt.Sprintf("%s=%s", tt.input, tt.result)
+		t.Run(testName, func(t *testing.T) {
+			tokens, err := parser.TokenizeString(tt.input)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			tokens, err = parser.ReformToRPN(tokens)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			result, err := EvaluateRPNExpression(tokens)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Expected error %v, got %v", tt.err, err)
+			}
+			if err == nil && result.String() != tt.result {
+				t.Errorf("Expected %s, got %s", tt.result, result)
+			}
+		})
+	}
+}
+
+func TestPercent(t *testing.T) {
+	var tests = []struct {
+		input  string
+		mode   PercentMode
+		result string
+	}{
+		{"200 + 10%", PercentCalculator, "220"},
+		{"200 - 10%", PercentCalculator, "180"},
+		{"50 * 20%", PercentCalculator, "10"},
+		{"50 / 20%", PercentCalculator, "250"},
+		{"20%", PercentCalculator, "0.2"},
+		{"10% + 200", PercentCalculator, "200.1"},
+		{"(200 + 10%) + 10%", PercentCalculator, "242"},
+		{"3 km + 10%", PercentCalculator, "3.3 km"},
+		{"10 m%", PercentCalculator, "0.1 m"},
+		{"200 + -10%", PercentCalculator, "180"},
+		{"200 - -10%", PercentCalculator, "220"},
+		{"200 + (-10%)", PercentCalculator, "180"},
+		{"200 + 10%", PercentDivide, "200.1"},
+		{"200 - 10%", PercentDivide, "199.9"},
+		{"50 * 20%", PercentDivide, "10"},
+		{"20%", PercentDivide, "0.2"},
+	}
+
+	for _, tt := range tests {
+		testName := fmt.Sprintf("%s=%s", tt.input, tt.result)
+		t.Run(testName, func(t *testing.T) {
+			tokens, err := parser.TokenizeString(tt.input)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			tokens, err = parser.ReformToRPN(tokens)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			e := Evaluator{Percent: tt.mode}
+			result, err := e.Evaluate(tokens)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result.String() != tt.result {
+				t.Errorf("Expected %s, got %s", tt.result, result)
+			}
+		})
+	}
+}
+
+func TestModulo(t *testing.T) {
+	var tests = []struct {
+		input, result string
+		err           error
+	}{
+		{"7 mod 3", "1", nil},
+		{"-7 mod 3", "2", nil},
+		{"7 mod -3", "-2", nil},
+		{"-7 rem 3", "-1", nil},
+		{"7 rem -3", "1", nil},
+		{"7 %% 3", "1", nil},
+		{"5.5 mod 2", "1.5", nil},
+		{"7 // 2", "3", nil},
+		{"-7 // 2", "-4", nil},
+		{"1 + 7 mod 4 * 2", "7", nil},
+		{"2 * 7 // 4", "3", nil},
+		{"2 ^ 3 mod 5", "3", nil},
+		{"7 m mod 2 m", "1 m", nil},
+		{"5 km mod 2000 m", "1 km", nil},
+		{"7 km // 3 m", "2333", nil},
+		{"7 m mod 2 s", "", ErrIncompatibleUnits},
+		{"7 mod 0", "", ErrDivByZero},
+		{"7 rem 0", "", ErrDivByZero},
+		{"7 // 0", "", ErrDivByZero},
+		{"1 / 0", "", ErrDivByZero},
+		{"0 / 5", "0", nil},
+	}
+
+	for _, tt := range tests {
+		testName := fmt.Sprintf("%s=%s", tt.input, tt.result)
+		t.Run(testName, func(t *testing.T) {
+			tokens, err := parser.TokenizeString(tt.input)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			tokens, err = parser.ReformToRPN(tokens)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			result, err := EvaluateRPNExpression(tokens)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Expected error %v, got %v", tt.err, err)
+			}
+			if err == nil && result.String() != tt.result {
+				t.Errorf("Expected %s, got %s", tt.result, result)
+			}
+		})
+	}
+}
+
+func TestBoolean(t *testing.T) {
+	var tests = []struct {
+		input, result string
+		err           error
+	}{
+		{"1 < 2", "true", nil},
+		{"2 <= 1", "false", nil},
+		{"3 > 2 == true", "true", nil},
+		{"1 km > 999 m", "true", nil},
+		{"100 cm == 1 m", "true", nil},
+		{"1 != 1", "false", nil},
+		{"not 0", "true", nil},
+		{"not 1 < 2", "false", nil},
+		{"true && false", "false", nil},
+		{"false || 2", "true", nil},
+		{"120 > 100 ? 10*0.9 : 10", "9", nil},
+		{"80 > 100 ? 10*0.9 : 10", "10", nil},
+		{"0 ? 1 : 0 ? 2 : 3", "3", nil},
+		{"1 ? 0 ? 1 : 2 : 3", "2", nil},
+		{"(1 < 2) + 1", "2", nil},
+		{"0 && 1 / 0", "false", nil},
+		{"1 || 1 / 0", "true", nil},
+		{"1 ? 2 : 1 / 0", "2", nil},
+		{"0 ? 1 / 0 : 2", "2", nil},
+		{"1 && 1 / 0", "", ErrDivByZero},
+		{"(0 && 1 / 0) || 2", "true", nil},
+		{"1 || 1 / 0 && 1 / 0", "true", nil},
+		{"0 && 1 / 0 ? 1 / 0 : 5", "5", nil},
+		{"1 ? 1 || 1 / 0 : 1 / 0", "true", nil},
+		{"0 && 1 || 1 / 0", "", ErrDivByZero},
+		{"1 m < 1 s", "", ErrIncompatibleUnits},
+	}
+
+	for _, tt := range tests {
+		testName := fmt.Sprintf("%s=%s", tt.input, tt.result)
+		t.Run(testName, func(t *testing.T) {
+			tokens, err := parser.TokenizeString(tt.input)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			tokens, err = parser.ReformToRPN(tokens)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			result, err := EvaluateRPNExpression(tokens)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Expected error %v, got %v", tt.err, err)
+			}
+			if err == nil && result.String() != tt.result {
+				t.Errorf("Expected %s, got %s", tt.result, result)
+			}
+		})
+	}
+}
+
+func TestLongExpression(t *testing.T) {
+	//the evaluation must not need a Go stack frame per operator
+	tokens, err := parser.TokenizeString("1" + strings.Repeat(" + 1", 500000))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	tokens, err = parser.ReformToRPN(tokens)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	result, err := EvaluateRPNExpression(tokens)
+	if err != nil || result.String() != "500001" {
+		t.Errorf("Expected 500001, got %v: %v", result, err)
+	}
+}
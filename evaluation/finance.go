@@ -0,0 +1,106 @@
+package evaluation
+
+import (
+	"fmt"
+	"github.com/niklasstich/calculator/finance"
+	"github.com/niklasstich/calculator/util"
+)
+
+// the financial functions take their arguments in the order of spreadsheets, the optional ones at the end have the
+// same defaults. The type argument is 0 for payments at the end of each period and 1 for payments at its start.
+func init() {
+	builtins["pmt"] = money("pmt", 3, []float64{0, 0}, func(a []float64) (float64, error) {
+		return finance.Payment(a[0], a[1], a[2], a[3], due(a[4]))
+	})
+	builtins["fv"] = money("fv", 3, []float64{0, 0}, func(a []float64) (float64, error) {
+		return finance.FutureValue(a[0], a[1], a[2], a[3], due(a[4])), nil
+	})
+	builtins["pv"] = money("pv", 3, []float64{0, 0}, func(a []float64) (float64, error) {
+		return finance.PresentValue(a[0], a[1], a[2], a[3], due(a[4])), nil
+	})
+	builtins["nper"] = money("nper", 3, []float64{0, 0}, func(a []float64) (float64, error) {
+		return finance.Periods(a[0], a[1], a[2], a[3], due(a[4]))
+	})
+	builtins["rate"] = money("rate", 3, []float64{0, 0, finance.DefaultGuess}, func(a []float64) (float64, error) {
+		return finance.Rate(a[0], a[1], a[2], a[3], due(a[4]), a[5])
+	})
+	//npv(rate, values...) and irr(values, guess) take the cash flows as lists or single values
+	builtins["npv"] = &Builtin{Name: "npv", Params: Variadic, Lists: true,
+		Apply: func(args []util.Token) (util.Token, error) {
+			if len(args) == 0 {
+				return util.Token{}, fmt.Errorf("%w: npv needs a rate followed by the cash flows", ErrArgumentCount)
+			}
+			if args[0].TokenList != nil || args[0].TokenUnit != nil {
+				return util.Token{}, fmt.Errorf("%w: rate %v of npv is not a number", ErrIncompatibleUnits, args[0])
+			}
+			values, err := cashFlows("npv", args[1:])
+			if err != nil {
+				return util.Token{}, err
+			}
+			return quantity(finance.NPV(args[0].TokenOperand, values), nil), nil
+		}}
+	builtins["irr"] = &Builtin{Name: "irr", Params: 2, Optional: 1, Lists: true,
+		Apply: func(args []util.Token) (util.Token, error) {
+			values, err := cashFlows("irr", args[:1])
+			if err != nil {
+				return util.Token{}, err
+			}
+			guess := finance.DefaultGuess
+			if len(args) > 1 {
+				if args[1].TokenList != nil || args[1].TokenUnit != nil {
+					return util.Token{}, fmt.Errorf("%w: guess %v of irr is not a number", ErrIncompatibleUnits, args[1])
+				}
+				guess = args[1].TokenOperand
+			}
+			r, err := finance.IRR(values, guess)
+			if err != nil {
+				return util.Token{}, err
+			}
+			return quantity(r, nil), nil
+		}}
+}
+
+// money wraps a financial function of numbers without units, the optional arguments which are left out get their
+// defaults
+func money(name string, required int, defaults []float64, f func(args []float64) (float64, error)) *Builtin {
+	return &Builtin{Name: name, Params: required + len(defaults), Optional: len(defaults),
+		Apply: func(args []util.Token) (util.Token, error) {
+			values := make([]float64, required, required+len(defaults))
+			for i, arg := range args {
+				if arg.TokenUnit != nil {
+					return util.Token{}, fmt.Errorf("%w: argument %v of %s is not dimensionless", ErrIncompatibleUnits,
+						arg, name)
+				}
+				if i < required {
+					values[i] = arg.TokenOperand
+				} else {
+					values = append(values, arg.TokenOperand)
+				}
+			}
+			values = append(values, defaults[len(values)-required:]...)
+			res, err := f(values)
+			if err != nil {
+				return util.Token{}, err
+			}
+			return quantity(res, nil), nil
+		}}
+}
+
+// cashFlows returns the numbers of lists of cash flows, which have no unit
+func cashFlows(name string, args []util.Token) ([]float64, error) {
+	values, unit, err := numbers(name, args)
+	if err != nil {
+		return nil, err
+	}
+	if unit != nil {
+		return nil, fmt.Errorf("%w: cash flows of %s have unit %v", ErrIncompatibleUnits, name, unit)
+	}
+	return values, nil
+}
+
+func due(t float64) finance.Due {
+	if t != 0 {
+		return finance.StartOfPeriod
+	}
+	return finance.EndOfPeriod
+}
@@ -0,0 +1,55 @@
+package evaluation
+
+import (
+	"errors"
+	"github.com/niklasstich/calculator/finance"
+	"github.com/niklasstich/calculator/parser"
+	"testing"
+)
+
+func TestFinance(t *testing.T) {
+	env := &Environment{}
+	p := parser.Parser{Scope: env}
+	var tests = []struct {
+		input, result string
+		err           error
+	}{
+		{"round(100 pmt(8%/12, 10, 10000)) / 100", "-1037.03", nil},
+		{"round(100 pmt(10%/12, 3, 2000, 0, 1)) / 100", "-672.21", nil},
+		{"round(100 fv(6%/12, 10, -200, -500, 1)) / 100", "2581.4", nil},
+		{"round(100 pv(8%/12, 12*20, 500)) / 100", "-59777.15", nil},
+		{"round(1000 nper(12%/12, -100, -1000, 10000, 1)) / 1000", "59.674", nil},
+		{"round(10000 rate(4*12, -200, 8000)) / 10000", "0.0077", nil},
+		{"round(100 npv(10%, -10000, 3000, 4200, 6800)) / 100", "1188.44", nil},
+		{"round(100 npv(10%, [-10000, 3000], [4200, 6800])) / 100", "1188.44", nil},
+		{"round(10000 irr([-70000, 12000, 15000, 18000, 21000, 26000])) / 10000", "0.0866", nil},
+		{"round(10000 irr([-70000, 12000, 15000], -10%)) / 10000", "-0.4435", nil},
+		{"round(pmt(0, [10, 20], 1000))", "[-100, -50]", nil},
+		{"pmt(1%, 10)", "", ErrArgumentCount},
+		{"pmt(1%, 10, 1000, 0, 0, 0)", "", ErrArgumentCount},
+		{"pmt(1%, 10, 1000 m)", "", ErrIncompatibleUnits},
+		{"npv()", "", ErrArgumentCount},
+		{"npv(10 m, 100, 200)", "", ErrIncompatibleUnits},
+		{"npv([10%], 100, 200)", "", ErrIncompatibleUnits},
+		{"pmt(1%, 0, 1000)", "", finance.ErrInvalidArgument},
+		{"irr([100, 200])", "", finance.ErrInvalidArgument},
+		{"rate(10, 100, 100, 100)", "", finance.ErrNoConvergence},
+	}
+	for _, tt := range tests {
+		tokens, err := p.Tokenize(tt.input)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", tt.input, err)
+		}
+		expression, err := p.ReformToRPN(tokens)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", tt.input, err)
+		}
+		result, err := (&Evaluator{Env: env}).Evaluate(expression)
+		if !errors.Is(err, tt.err) {
+			t.Fatalf("%s: expected error %v, got %v", tt.input, tt.err, err)
+		}
+		if err == nil && result.String() != tt.result {
+			t.Errorf("%s: expected %s, got %s", tt.input, tt.result, result)
+		}
+	}
+}
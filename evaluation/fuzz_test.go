@@ -0,0 +1,60 @@
+package evaluation
+
+import (
+	"context"
+	"github.com/niklasstich/calculator/parser"
+	"testing"
+	"time"
+)
+
+// FuzzEvaluate runs the whole pipeline from the input string to the printed result in every Mode and makes sure it
+// never panics. Steps and time are limited, the fuzzer finds expressions like nested recursive calls that take forever.
+// Huge arbitrary precision results like 9^9^9 are rejected by the evaluator, since printing them would never end.
+func FuzzEvaluate(f *testing.F) {
+	for _, seed := range []string{
+		"2+4",
+		"(1 + 2) * 3 - 4 / 5",
+		"-2^-2",
+		"3!",
+		"200 + 10%",
+		"5 km + 300 m in m",
+		"2pi(1)",
+		"0x1F & 0b101 | ~0o7 xor 3 << 2 >> 1",
+		"-7 mod 3 + -7 rem 3 + -7 // 2",
+		"1 < 2 && not (3 >= 4) || 5 == 5",
+		"1 > 2 ? 3 : 4 != 4 ? 5 : 6",
+		"1 / 0",
+		"2 ^ 1e300 * 0",
+		"0xFFFFFFFFFFFFFFFF + 1",
+		"m^2 * 3",
+	} {
+		f.Add(seed)
+	}
+	modes := []Evaluator{
+		{Mode: ModeFloat, MaxSteps: 10000},
+		{Mode: ModeInteger, MaxSteps: 10000},
+		{Mode: ModeBig, MaxSteps: 10000, Precision: 128},
+	}
+	f.Fuzz(func(t *testing.T, input string) {
+		p := parser.Parser{MaxInputLength: 1000, MaxDepth: 100}
+		tokens, err := p.Tokenize(input)
+		if err != nil {
+			return
+		}
+		rpn, err := p.ReformToRPN(tokens)
+		if err != nil {
+			return
+		}
+		for _, e := range modes {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			res, err := e.EvaluateContext(ctx, rpn)
+			cancel()
+			if err == nil && res == nil {
+				t.Errorf("%q evaluated without result or error in mode %d", input, e.Mode)
+			}
+			if err == nil {
+				_ = res.String()
+			}
+		}
+	})
+}
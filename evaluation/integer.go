@@ -0,0 +1,217 @@
+package evaluation
+
+import (
+	"fmt"
+	"github.com/niklasstich/calculator/util"
+	"math"
+)
+
+// intFuncLookup contains the binary operations of ModeInteger. Operands are sign extended for signed types, the
+// result is wrapped to the IntType afterwards.
+var intFuncLookup = map[util.Op]func(a, b uint64, t util.IntType) (uint64, error){
+	util.OpAddition: func(a, b uint64, t util.IntType) (uint64, error) {
+		return a + b, nil
+	},
+	util.OpSubtraction: func(a, b uint64, t util.IntType) (uint64, error) {
+		return a - b, nil
+	},
+	util.OpMultiplication: func(a, b uint64, t util.IntType) (uint64, error) {
+		return a * b, nil
+	},
+	util.OpDivision: func(a, b uint64, t util.IntType) (uint64, error) {
+		if b == 0 {
+			return 0, ErrDivByZero
+		}
+		if t.Signed {
+			return uint64(int64(a) / int64(b)), nil
+		}
+		return a / b, nil
+	},
+	util.OpIntegerDivision: func(a, b uint64, t util.IntType) (uint64, error) {
+		if b == 0 {
+			return 0, ErrDivByZero
+		}
+		if !t.Signed {
+			return a / b, nil
+		}
+		//round towards negative infinity instead of zero
+		q := int64(a) / int64(b)
+		if int64(a)%int64(b) != 0 && (int64(a) < 0) != (int64(b) < 0) {
+			q--
+		}
+		return uint64(q), nil
+	},
+	util.OpModulo: func(a, b uint64, t util.IntType) (uint64, error) {
+		if b == 0 {
+			return 0, ErrDivByZero
+		}
+		if !t.Signed {
+			return a % b, nil
+		}
+		//the result has the sign of the divisor
+		r := int64(a) % int64(b)
+		if r != 0 && (r < 0) != (int64(b) < 0) {
+			r += int64(b)
+		}
+		return uint64(r), nil
+	},
+	util.OpRemainder: func(a, b uint64, t util.IntType) (uint64, error) {
+		if b == 0 {
+			return 0, ErrDivByZero
+		}
+		if t.Signed {
+			return uint64(int64(a) % int64(b)), nil
+		}
+		return a % b, nil
+	},
+	util.OpExponentiation: func(a, b uint64, t util.IntType) (uint64, error) {
+		if t.Signed && int64(b) < 0 {
+			return 0, fmt.Errorf("%w: negative exponent %d", ErrNotAnInteger, int64(b))
+		}
+		//exponentiation by squaring, overflows wrap around like multiplication does
+		res := uint64(1)
+		for ; b > 0; b >>= 1 {
+			if b&1 == 1 {
+				res *= a
+			}
+			a *= a
+		}
+		return res, nil
+	},
+	util.OpBitwiseAnd: func(a, b uint64, t util.IntType) (uint64, error) {
+		return a & b, nil
+	},
+	util.OpBitwiseOr: func(a, b uint64, t util.IntType) (uint64, error) {
+		return a | b, nil
+	},
+	util.OpBitwiseXor: func(a, b uint64, t util.IntType) (uint64, error) {
+		return a ^ b, nil
+	},
+	util.OpShiftLeft: func(a, b uint64, t util.IntType) (uint64, error) {
+		if t.Signed && int64(b) < 0 {
+			return 0, ErrNegativeShift
+		}
+		return a << b, nil
+	},
+	util.OpShiftRight: func(a, b uint64, t util.IntType) (uint64, error) {
+		if t.Signed && int64(b) < 0 {
+			return 0, ErrNegativeShift
+		}
+		if t.Signed {
+			//arithmetic shift for signed types
+			return uint64(int64(a) >> b), nil
+		}
+		return a >> b, nil
+	},
+}
+
+// intUnaryLookup contains the unary operations of ModeInteger
+var intUnaryLookup = map[util.Op]func(a uint64) uint64{
+	util.OpNegation: func(a uint64) uint64 {
+		return -a
+	},
+	util.OpBitwiseNot: func(a uint64) uint64 {
+		return ^a
+	},
+}
+
+func (e *Evaluator) intType() util.IntType {
+	if e.IntType.Bits == 0 {
+		return util.Int64
+	}
+	return e.IntType
+}
+
+// evaluateIntegerToken works like evaluateToken in ModeInteger
+func (e *Evaluator) evaluateIntegerToken(f *frame, token util.Token, stack *util.TokenStack) error {
+	t := e.intType()
+	before := e.snapshot(stack)
+	if token.TokenType == util.TokenTypeOperand {
+		operand := token
+		if token.TokenName != "" {
+			var err error
+			if operand, err = variable(f, token.TokenName); err != nil {
+				return err
+			}
+		}
+		v, err := toInteger(&operand, t)
+		if err != nil {
+			return err
+		}
+		stack.Push(integerToken(v, t))
+		e.trace(token, f.depth, before, stack)
+		return nil
+	}
+	if err := f.limit.step(); err != nil {
+		return err
+	}
+	op := token.TokenOperator.Op
+	switch {
+	case op == util.OpAnd, op == util.OpOr, op == util.OpTernary, op == util.OpNot:
+		if err := e.logical(op, stack); err != nil {
+			return err
+		}
+	case isComparison(op):
+		op1 := stack.Pop()
+		op2 := stack.Pop()
+		stack.Push(e.truth(compared(op, intCompare(op2.TokenInteger.Value, op1.TokenInteger.Value, t))))
+	case intUnaryLookup[op] != nil:
+		a := stack.Pop()
+		stack.Push(integerToken(t.Wrap(intUnaryLookup[op](a.TokenInteger.Value)), t))
+	default:
+		binary := intFuncLookup[op]
+		if binary == nil {
+			return fmt.Errorf("%w: %v in integer mode", ErrUnsupportedOperator, token)
+		}
+		op1 := stack.Pop()
+		op2 := stack.Pop()
+		v, err := binary(op2.TokenInteger.Value, op1.TokenInteger.Value, t)
+		if err != nil {
+			return err
+		}
+		stack.Push(integerToken(t.Wrap(v), t))
+	}
+	e.trace(token, f.depth, before, stack)
+	return nil
+}
+
+// intCompare returns -1, 0 or 1 if a is less than, equal to or greater than b
+func intCompare(a, b uint64, t util.IntType) int {
+	switch {
+	case t.Signed && int64(a) < int64(b), !t.Signed && a < b:
+		return -1
+	case a == b:
+		return 0
+	}
+	return 1
+}
+
+// toInteger converts an operand to the IntType, wrapping it if it doesn't fit
+func toInteger(token *util.Token, t util.IntType) (uint64, error) {
+	if token.TokenName != "" {
+		return 0, fmt.Errorf("%w: variable %s in integer mode", ErrUnsupportedOperator, token.TokenName)
+	}
+	if token.TokenUnit != nil || isDate(token) {
+		return 0, fmt.Errorf("%w: %v in integer mode", ErrIncompatibleUnits, token)
+	}
+	if token.TokenInteger != nil {
+		return t.Wrap(token.TokenInteger.Value), nil
+	}
+	f := token.TokenOperand
+	if f != math.Trunc(f) || f >= 1<<64 || f < -(1<<63) {
+		return 0, fmt.Errorf("%w: %v", ErrNotAnInteger, token)
+	}
+	if f < 0 {
+		return t.Wrap(uint64(int64(f))), nil
+	}
+	return t.Wrap(uint64(f)), nil
+}
+
+func integerToken(v uint64, t util.IntType) util.Token {
+	i := util.Integer{Value: v, Type: t}
+	return util.Token{
+		TokenType:    util.TokenTypeOperand,
+		TokenOperand: i.Float(),
+		TokenInteger: &i,
+	}
+}
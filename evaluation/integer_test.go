@@ -0,0 +1,131 @@
+package evaluation
+
+import (
+	"errors"
+	"fmt"
+	"github.com/niklasstich/calculator/parser"
+	"github.com/niklasstich/calculator/util"
+	"testing"
+)
+
+func TestIntegerMode(t *testing.T) {
+	var tests = []struct {
+		input   string
+		intType util.IntType
+		result  string
+		err     error
+	}{
+		{"7 / 2", util.Int64, "3", nil},
+		{"-7 / 2", util.Int64, "-3", nil},
+		{"0xff + 1", util.Uint8, "0", nil},
+		{"127 + 1", util.Int8, "-128", nil},
+		{"0 - 1", util.Uint16, "65535", nil},
+		{"-1", util.Uint32, "4294967295", nil},
+		{"2 ^ 10", util.Int32, "1024", nil},
+		{"2 ^ 64", util.Uint64, "0", nil},
+		{"0xFFFFFFFFFFFFFFFF", util.Uint64, "18446744073709551615", nil},
+		{"0xFFFFFFFFFFFFFFFF", util.Int64, "-1", nil},
+		{"0b1010 & 0b0110", util.Uint8, "2", nil},
+		{"0b1010 | 0b0110", util.Uint8, "14", nil},
+		{"0b1010 xor 0b0110", util.Uint8, "12", nil},
+		{"~0", util.Uint8, "255", nil},
+		{"~0", util.Int8, "-1", nil},
+		{"1 << 7", util.Int8, "-128", nil},
+		{"-16 >> 2", util.Int8, "-4", nil},
+		{"0xf0 >> 4", util.Uint8, "15", nil},
+		{"1 << 2 + 1", util.Int32, "8", nil},
+		{"6 & 3 | 8", util.Int32, "10", nil},
+		{"-7 mod 3", util.Int32, "2", nil},
+		{"7 mod -3", util.Int32, "-2", nil},
+		{"-7 rem 3", util.Int32, "-1", nil},
+		{"-7 // 2", util.Int32, "-4", nil},
+		{"7 // 2", util.Uint8, "3", nil},
+		{"0xff mod 16", util.Uint8, "15", nil},
+		{"1 < 2", util.Int32, "true", nil},
+		{"-1 < 0", util.Int8, "true", nil},
+		{"0xff > 1", util.Uint8, "true", nil},
+		{"0xff > 1", util.Int8, "false", nil},
+		{"(3 >= 3) + 1", util.Int32, "2", nil},
+		{"1 == 2 || not 0", util.Int32, "true", nil},
+		{"1 & 2 == 2", util.Int32, "1", nil},
+		{"0 && 1 / 0", util.Int32, "false", nil},
+		{"1 || 1 / 0", util.Int32, "true", nil},
+		{"1 ? 5 : 1 / 0", util.Int32, "5", nil},
+		{"0 ? 1 / 0 : 6", util.Int32, "6", nil},
+		{"1 && 1 / 0", util.Int32, "", ErrDivByZero},
+		{"1 / 0", util.Int32, "", ErrDivByZero},
+		{"1 mod 0", util.Int32, "", ErrDivByZero},
+		{"1 rem 0", util.Uint8, "", ErrDivByZero},
+		{"1 // 0", util.Int64, "", ErrDivByZero},
+		{"1.5 + 1", util.Int32, "", ErrNotAnInteger},
+		{"2 ^ -1", util.Int32, "", ErrNotAnInteger},
+		{"1 << -1", util.Int32, "", ErrNegativeShift},
+		{"3 m", util.Int32, "", ErrIncompatibleUnits},
+		{"3 m in km", util.Int32, "", ErrIncompatibleUnits},
+	}
+
+	for _, tt := range tests {
+		testName := fmt.Sprintf("%s(%v)=%s", tt.input, tt.intType, tt.result)
+		t.Run(testName, func(t *testing.T) {
+			tokens, err := parser.TokenizeString(tt.input)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			tokens, err = parser.ReformToRPN(tokens)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			e := Evaluator{Mode: ModeInteger, IntType: tt.intType}
+			result, err := e.Evaluate(tokens)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Expected error %v, got %v", tt.err, err)
+			}
+			if err == nil && result.String() != tt.result {
+				t.Errorf("Expected %s, got %s", tt.result, result)
+			}
+		})
+	}
+}
+
+func TestBitwiseFloatMode(t *testing.T) {
+	var tests = []struct {
+		input, result string
+		err           error
+	}{
+		{"12 & 10", "8", nil},
+		{"12 | 3", "15", nil},
+		{"12 xor 10", "6", nil},
+		{"1 & 2 == 2", "1", nil},
+		{"4 | 1 < 2", "5", nil},
+		{"(4 | 1) < 2", "false", nil},
+		{"~5", "-6", nil},
+		{"1 << 4", "16", nil},
+		{"-2^2", "-4", nil},
+		{"2^-1", "0.5", nil},
+		{"-(3 - 5)", "2", nil},
+		{"1.5 & 1", "", ErrNotAnInteger},
+		{"2 m | 1", "", ErrIncompatibleUnits},
+		{"1 >> -1", "", ErrNegativeShift},
+	}
+
+	for _, tt := range tests {
+		testName := fmt.Sprintf("%s=%s", tt.input, tt.result)
+		t.Run(testName, func(t *testing.T) {
+			tokens, err := parser.TokenizeString(tt.input)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			tokens, err = parser.ReformToRPN(tokens)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			result, err := EvaluateRPNExpression(tokens)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Expected error %v, got %v", tt.err, err)
+			}
+			if err == nil && result.String() != tt.result {
+				t.Errorf("Expected %s, got %s", tt.result, result)
+			}
+		})
+	}
+}
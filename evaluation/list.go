@@ -0,0 +1,95 @@
+package evaluation
+
+import (
+	"errors"
+	"fmt"
+	"github.com/niklasstich/calculator/util"
+)
+
+var ErrListLength = errors.New("lists have different lengths")
+
+// list creates a list token of the elements
+func list(elements []util.Token) util.Token {
+	if elements == nil {
+		//a nil TokenList is a number
+		elements = []util.Token{}
+	}
+	return util.Token{
+		TokenType: util.TokenTypeOperand,
+		TokenList: elements,
+	}
+}
+
+// popList pops the topmost n operands, which were pushed from left to right, and creates a list of them
+func popList(stack *util.TokenStack, n int) util.Token {
+	elements := make([]util.Token, n)
+	for i := n - 1; i >= 0; i-- {
+		elements[i] = *stack.Pop()
+	}
+	return list(elements)
+}
+
+// broadcast calls f with the arguments. If some of them are lists, which all need the same shape, f is called with
+// the elements at each index instead and the results are returned as a list, arguments which are not lists are passed
+// at every index. So [1, 2] + 10 is [11, 12] and [1, 2] - [3, 4] is [-2, -2]. Lists of lists are handled recursively.
+func broadcast(args []util.Token, f func(args []util.Token) (util.Token, error)) (util.Token, error) {
+	n := -1
+	for _, arg := range args {
+		if arg.TokenList == nil {
+			continue
+		}
+		if n >= 0 && len(arg.TokenList) != n {
+			return util.Token{}, fmt.Errorf("%w: %d and %d elements", ErrListLength, n, len(arg.TokenList))
+		}
+		n = len(arg.TokenList)
+	}
+	if n < 0 {
+		return f(args)
+	}
+	elements := make([]util.Token, n)
+	for i := range elements {
+		element := make([]util.Token, len(args))
+		//a list of lists can't be combined with a list of numbers, like a matrix with a vector
+		lists, nested := 0, 0
+		for j, arg := range args {
+			element[j] = arg
+			if arg.TokenList != nil {
+				element[j] = arg.TokenList[i]
+				lists++
+				if element[j].TokenList != nil {
+					nested++
+				}
+			}
+		}
+		if nested > 0 && nested < lists {
+			return util.Token{}, fmt.Errorf("%w: %s and %s", ErrShape, shape(args[0]), shape(args[len(args)-1]))
+		}
+		var err error
+		if elements[i], err = broadcast(element, f); err != nil {
+			return util.Token{}, err
+		}
+	}
+	return list(elements), nil
+}
+
+// elementwise applies an operator of funcLookup to its n operands on top of the stack, operands which are lists are
+// broadcast
+func (e *Evaluator) elementwise(apply func(e *Evaluator, stack *util.TokenStack) error, n int,
+	stack *util.TokenStack) error {
+	args := popList(stack, n).TokenList
+	res, err := broadcast(args, func(args []util.Token) (util.Token, error) {
+		operands := util.TokenStack{}
+		for _, arg := range args {
+			operands.Push(arg)
+		}
+		if err := apply(e, &operands); err != nil {
+			return util.Token{}, err
+		}
+		return *operands.Pop(), nil
+	})
+	if err != nil {
+		return err
+	}
+	stack.Push(res)
+	return nil
+}
@@ -0,0 +1,432 @@
+package evaluation
+
+import (
+	"errors"
+	"fmt"
+	"github.com/niklasstich/calculator/util"
+	"math"
+)
+
+var ErrShape = errors.New("operands have incompatible shapes")
+var ErrSingular = errors.New("matrix is singular")
+
+// maxDimension is the largest number of rows and columns of a matrix created by identity
+const maxDimension = 1000
+
+// singular is the size of a pivot relative to the largest element of a matrix below which it counts as 0, so rounding
+// errors don't make singular matrices invertible
+const singular = 1e-12
+
+// matrix is the rows of a matrix value, which is a list of lists of numbers which all have the same length. Vectors are
+// lists of numbers, on the right of a matrix they are a column and on its left a row.
+type matrix [][]util.Token
+
+// asMatrix returns the rows of a matrix value, ok is false for all other values
+func asMatrix(t util.Token) (m matrix, ok bool) {
+	if len(t.TokenList) == 0 {
+		return nil, false
+	}
+	m = make(matrix, len(t.TokenList))
+	for i, row := range t.TokenList {
+		if !isVector(row) || len(row.TokenList) == 0 || len(row.TokenList) != len(t.TokenList[0].TokenList) {
+			return nil, false
+		}
+		m[i] = row.TokenList
+	}
+	return m, true
+}
+
+// isVector reports whether the value is a list of numbers
+func isVector(t util.Token) bool {
+	if t.TokenList == nil {
+		return false
+	}
+	for _, element := range t.TokenList {
+		if element.TokenList != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func (m matrix) token() util.Token {
+	rows := make([]util.Token, len(m))
+	for i, row := range m {
+		rows[i] = list(row)
+	}
+	return list(rows)
+}
+
+// shape describes a value in errors
+func shape(t util.Token) string {
+	if m, ok := asMatrix(t); ok {
+		return fmt.Sprintf("%dx%d matrix", len(m), len(m[0]))
+	}
+	if isVector(t) {
+		return fmt.Sprintf("vector of %d", len(t.TokenList))
+	}
+	if t.TokenList != nil {
+		return "list " + t.String()
+	}
+	return "number " + t.String()
+}
+
+// product multiplies the two topmost operands as matrices if one of them is a matrix and the other one a matrix or a
+// vector, and reports whether it did. Other operands stay on the stack, they are multiplied element by element.
+func product(stack *util.TokenStack) (bool, error) {
+	b := *stack.Pop()
+	a := *stack.Pop()
+	left, leftMatrix := asMatrix(a)
+	right, rightMatrix := asMatrix(b)
+	switch {
+	case leftMatrix && rightMatrix:
+	case leftMatrix && isVector(b):
+		right = make(matrix, len(b.TokenList))
+		for i, element := range b.TokenList {
+			right[i] = []util.Token{element}
+		}
+	case rightMatrix && isVector(a):
+		left = matrix{a.TokenList}
+	default:
+		stack.Push(a)
+		stack.Push(b)
+		return false, nil
+	}
+	if len(left[0]) != len(right) {
+		return true, fmt.Errorf("%w: cannot multiply %s by %s", ErrShape, shape(a), shape(b))
+	}
+	res, err := multiply(left, right)
+	if err != nil {
+		return true, err
+	}
+	switch {
+	case !rightMatrix:
+		//the product with a column is a column, which is written as a vector again
+		column := make([]util.Token, len(res))
+		for i, row := range res {
+			column[i] = row[0]
+		}
+		stack.Push(list(column))
+	case !leftMatrix:
+		stack.Push(list(res[0]))
+	default:
+		stack.Push(res.token())
+	}
+	return true, nil
+}
+
+// multiply returns the matrix product of left and right, left has as many columns as right has rows
+func multiply(left, right matrix) (matrix, error) {
+	res := make(matrix, len(left))
+	for i := range res {
+		res[i] = make([]util.Token, len(right[0]))
+		for j := range res[i] {
+			column := make([]util.Token, len(right))
+			for k := range right {
+				column[k] = right[k][j]
+			}
+			var err error
+			if res[i][j], err = dot(left[i], column); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return res, nil
+}
+
+// power raises a matrix on the stack to a number and reports whether it did, like product. Square matrices are
+// multiplied with themselves, a negative exponent raises their inverse. Other operands stay on the stack.
+func power(stack *util.TokenStack) (bool, error) {
+	b := *stack.Pop()
+	a := *stack.Pop()
+	m, ok := asMatrix(a)
+	if !ok || b.TokenList != nil {
+		stack.Push(a)
+		stack.Push(b)
+		return false, nil
+	}
+	if len(m) != len(m[0]) {
+		return true, fmt.Errorf("%w: cannot raise %s to a power, it needs a square matrix", ErrShape, shape(a))
+	}
+	if b.TokenUnit != nil || isDate(&b) || b.TokenOperand != math.Trunc(b.TokenOperand) ||
+		math.Abs(b.TokenOperand) >= 1<<53 {
+		return true, fmt.Errorf("%w: exponent %v of a matrix", ErrNotAnInteger, b)
+	}
+	n := int64(b.TokenOperand)
+	if n < 0 {
+		inverse, err := builtins["inverse"].Apply([]util.Token{a})
+		if err != nil {
+			return true, err
+		}
+		m, _ = asMatrix(inverse)
+		n = -n
+	}
+	//exponentiation by squaring, starting with the identity matrix
+	res := make(matrix, len(m))
+	for i := range res {
+		res[i] = make([]util.Token, len(m))
+		for j := range res[i] {
+			res[i][j] = quantity(0, nil)
+		}
+		res[i][i] = quantity(1, nil)
+	}
+	for ; n > 0; n /= 2 {
+		var err error
+		if n%2 == 1 {
+			if res, err = multiply(res, m); err != nil {
+				return true, err
+			}
+		}
+		if n > 1 {
+			if m, err = multiply(m, m); err != nil {
+				return true, err
+			}
+		}
+	}
+	stack.Push(res.token())
+	return true, nil
+}
+
+// dot returns the sum of the products of the elements of both vectors, which have the same length
+func dot(a, b []util.Token) (util.Token, error) {
+	if len(a) == 0 {
+		return quantity(0, nil), nil
+	}
+	res := times(a[0], b[0])
+	for i := 1; i < len(a); i++ {
+		var err error
+		if res, err = plus(res, times(a[i], b[i]), 1); err != nil {
+			return util.Token{}, err
+		}
+	}
+	return res, nil
+}
+
+// times multiplies two numbers with their units
+func times(a, b util.Token) util.Token {
+	return quantity(a.TokenOperand*b.TokenOperand, a.TokenUnit.Mul(b.TokenUnit))
+}
+
+// plus adds sign times b to a, the result is in the unit of a
+func plus(a, b util.Token, sign float64) (util.Token, error) {
+	if !a.TokenUnit.Compatible(b.TokenUnit) {
+		return util.Token{}, fmt.Errorf("%w: cannot add %v to %v", ErrIncompatibleUnits, b, a)
+	}
+	return quantity(a.TokenOperand+sign*b.TokenOperand*b.TokenUnit.ConversionFactor(a.TokenUnit), a.TokenUnit), nil
+}
+
+func init() {
+	builtins["transpose"] = &Builtin{Name: "transpose", Params: 1, Lists: true,
+		Apply: func(args []util.Token) (util.Token, error) {
+			m, ok := asMatrix(args[0])
+			if !ok && isVector(args[0]) && len(args[0].TokenList) > 0 {
+				//a vector is a row, its transpose is a column
+				m, ok = matrix{args[0].TokenList}, true
+			}
+			if !ok {
+				return util.Token{}, fmt.Errorf("%w: cannot transpose %s", ErrShape, shape(args[0]))
+			}
+			res := make(matrix, len(m[0]))
+			for i := range res {
+				res[i] = make([]util.Token, len(m))
+				for j := range m {
+					res[i][j] = m[j][i]
+				}
+			}
+			return res.token(), nil
+		}}
+	builtins["det"] = &Builtin{Name: "det", Params: 1, Lists: true,
+		Apply: func(args []util.Token) (util.Token, error) {
+			a, err := square("det", args[0])
+			if err != nil {
+				return util.Token{}, err
+			}
+			integers := integral(a)
+			det := eliminate(a)
+			if integers && math.Abs(det) < 1<<53 {
+				//the determinant of integers is an integer, this removes the rounding errors of the elimination
+				det = math.Round(det)
+			}
+			return quantity(det, nil), nil
+		}}
+	builtins["inverse"] = &Builtin{Name: "inverse", Params: 1, Lists: true,
+		Apply: func(args []util.Token) (util.Token, error) {
+			a, err := square("inverse", args[0])
+			if err != nil {
+				return util.Token{}, err
+			}
+			integers := integral(a)
+			n := len(a)
+			for i := range a {
+				a[i] = append(a[i], make([]float64, n)...)
+				a[i][n+i] = 1
+			}
+			det := eliminate(a)
+			if det == 0 {
+				return util.Token{}, fmt.Errorf("%w: %v has no inverse", ErrSingular, args[0])
+			}
+			//the inverse of integers is their adjugate, which has integer elements, divided by the determinant. Like
+			//in det, this removes the rounding errors of the elimination.
+			integers = integers && math.Abs(det) < 1<<53
+			det = math.Round(det)
+			res := make(matrix, n)
+			for i := range res {
+				res[i] = make([]util.Token, n)
+				for j := range res[i] {
+					v := a[i][n+j]
+					if adjugate := v * det; integers && math.Abs(adjugate) < 1<<53 {
+						v = math.Round(adjugate) / det
+					}
+					res[i][j] = quantity(v, nil)
+				}
+			}
+			return res.token(), nil
+		}}
+	//solve(A, b) returns x with A * x = b, b may have a unit which x gets as well
+	builtins["solve"] = &Builtin{Name: "solve", Params: 2, Lists: true,
+		Apply: func(args []util.Token) (util.Token, error) {
+			a, err := square("solve", args[0])
+			if err != nil {
+				return util.Token{}, err
+			}
+			if !isVector(args[1]) || len(args[1].TokenList) != len(a) {
+				return util.Token{}, fmt.Errorf("%w: cannot solve %s for %s", ErrShape, shape(args[0]),
+					shape(args[1]))
+			}
+			b, unit, err := numbers("solve", args[1:])
+			if err != nil {
+				return util.Token{}, err
+			}
+			for i := range a {
+				a[i] = append(a[i], b[i])
+			}
+			if eliminate(a) == 0 {
+				return util.Token{}, fmt.Errorf("%w: %v has no unique solution", ErrSingular, args[0])
+			}
+			x := make([]util.Token, len(a))
+			for i := range x {
+				x[i] = quantity(a[i][len(a)], unit)
+			}
+			return list(x), nil
+		}}
+	builtins["identity"] = &Builtin{Name: "identity", Params: 1,
+		Apply: func(args []util.Token) (util.Token, error) {
+			n := args[0].TokenOperand
+			if args[0].TokenUnit != nil || n != math.Trunc(n) || n < 1 || n > maxDimension {
+				return util.Token{}, fmt.Errorf("%w: identity of size %v, it has to be between 1 and %d",
+					ErrOutOfRange, args[0], maxDimension)
+			}
+			res := make(matrix, int(n))
+			for i := range res {
+				res[i] = make([]util.Token, int(n))
+				for j := range res[i] {
+					res[i][j] = quantity(0, nil)
+				}
+				res[i][i] = quantity(1, nil)
+			}
+			return res.token(), nil
+		}}
+	builtins["dot"] = &Builtin{Name: "dot", Params: 2, Lists: true,
+		Apply: func(args []util.Token) (util.Token, error) {
+			a, b := args[0], args[1]
+			if !isVector(a) || !isVector(b) || len(a.TokenList) != len(b.TokenList) {
+				return util.Token{}, fmt.Errorf("%w: dot product of %s and %s", ErrShape, shape(a), shape(b))
+			}
+			return dot(a.TokenList, b.TokenList)
+		}}
+	builtins["cross"] = &Builtin{Name: "cross", Params: 2, Lists: true,
+		Apply: func(args []util.Token) (util.Token, error) {
+			if !isVector(args[0]) || !isVector(args[1]) || len(args[0].TokenList) != 3 || len(args[1].TokenList) != 3 {
+				return util.Token{}, fmt.Errorf("%w: cross product of %s and %s, it needs two vectors of 3", ErrShape,
+					shape(args[0]), shape(args[1]))
+			}
+			a, b := args[0].TokenList, args[1].TokenList
+			res := make([]util.Token, 3)
+			for i := range res {
+				j, k := (i+1)%3, (i+2)%3
+				var err error
+				if res[i], err = plus(times(a[j], b[k]), times(a[k], b[j]), -1); err != nil {
+					return util.Token{}, err
+				}
+			}
+			return list(res), nil
+		}}
+}
+
+// square returns the numbers of a square matrix without units, which the functions solving linear equations need
+func square(name string, t util.Token) ([][]float64, error) {
+	m, ok := asMatrix(t)
+	if !ok || len(m) != len(m[0]) {
+		return nil, fmt.Errorf("%w: %s of %s, it needs a square matrix", ErrShape, name, shape(t))
+	}
+	a := make([][]float64, len(m))
+	for i, row := range m {
+		a[i] = make([]float64, len(row))
+		for j, element := range row {
+			if isDate(&element) {
+				return nil, fmt.Errorf("%w: %s takes numbers, got %v", ErrIncompatibleUnits, name, element)
+			}
+			if element.TokenUnit != nil {
+				return nil, fmt.Errorf("%w: %s of a matrix with unit %v", ErrIncompatibleUnits, name,
+					element.TokenUnit)
+			}
+			a[i][j] = element.TokenOperand
+		}
+	}
+	return a, nil
+}
+
+// integral reports whether all elements of a are integers
+func integral(a [][]float64) bool {
+	for _, row := range a {
+		for _, v := range row {
+			if v != math.Trunc(v) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// eliminate transforms the rows of a, whose first len(a) columns are a square matrix, with Gauss-Jordan elimination
+// until that matrix is the identity, so the further columns hold the solutions. It returns the determinant of the
+// square matrix, which is 0 if it is singular, in which case a is only partly transformed.
+func eliminate(a [][]float64) float64 {
+	n := len(a)
+	largest := 0.0
+	for _, row := range a {
+		for _, v := range row[:n] {
+			largest = math.Max(largest, math.Abs(v))
+		}
+	}
+	det := 1.0
+	for col := 0; col < n; col++ {
+		//the largest pivot keeps rounding errors small
+		pivot := col
+		for r := col + 1; r < n; r++ {
+			if math.Abs(a[r][col]) > math.Abs(a[pivot][col]) {
+				pivot = r
+			}
+		}
+		if math.Abs(a[pivot][col]) <= singular*largest {
+			return 0
+		}
+		if pivot != col {
+			a[pivot], a[col] = a[col], a[pivot]
+			det = -det
+		}
+		p := a[col][col]
+		det *= p
+		for c := range a[col] {
+			a[col][c] /= p
+		}
+		for r := range a {
+			if f := a[r][col]; r != col && f != 0 {
+				for c := range a[r] {
+					a[r][c] -= f * a[col][c]
+				}
+			}
+		}
+	}
+	return det
+}
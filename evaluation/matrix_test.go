@@ -0,0 +1,81 @@
+package evaluation
+
+import (
+	"errors"
+	"github.com/niklasstich/calculator/parser"
+	"testing"
+)
+
+func TestMatrices(t *testing.T) {
+	env := &Environment{}
+	p := parser.Parser{Scope: env}
+	var tests = []struct {
+		input, result string
+		err           error
+	}{
+		{"[[1, 2], [3, 4]] * [5, 6]", "[17, 39]", nil},
+		{"[5, 6] * [[1, 2], [3, 4]]", "[23, 34]", nil},
+		{"[[1, 2], [3, 4]] * [[0, 1], [1, 0]]", "[[2, 1], [4, 3]]", nil},
+		{"[[1, 2, 3]] * [[1], [2], [3]]", "[[14]]", nil},
+		{"2 [[1, 2], [3, 4]]", "[[2, 4], [6, 8]]", nil},
+		{"[[1, 2], [3, 4]] + [[10, 20], [30, 40]]", "[[11, 22], [33, 44]]", nil},
+		{"[[1, 2], [3, 4]] - identity(2)", "[[0, 2], [3, 3]]", nil},
+		{"[1, 2] * [3, 4]", "[3, 8]", nil},
+		{"[[1 m, 2 m]] * [3, 4]", "[11 m]", nil},
+		{"transpose([[1, 2, 3], [4, 5, 6]])", "[[1, 4], [2, 5], [3, 6]]", nil},
+		{"transpose([1, 2])", "[[1], [2]]", nil},
+		{"det([[1, 2], [3, 4]])", "-2", nil},
+		{"det([[2, 0, 1], [1, 3, 2], [1, 1, 2]])", "6", nil},
+		{"det([[1, 2], [2, 4]])", "0", nil},
+		{"inverse([[2, 0], [0, 4]])", "[[0.5, 0], [0, 0.25]]", nil},
+		{"inverse([[1, 2], [3, 4]])", "[[-2, 1], [1.5, -0.5]]", nil},
+		{"inverse([[4, 7], [2, 6]])", "[[0.6, -0.7], [-0.2, 0.4]]", nil},
+		{"6 inverse([[2, 0, 1], [1, 3, 2], [1, 1, 2]])", "[[4, 1, -3], [0, 3, -3], [-2, -2, 6]]", nil},
+		{"round(10 inverse([[4, 7], [2, 6]])) / 10", "[[0.6, -0.7], [-0.2, 0.4]]", nil},
+		{"[[1, 2], [3, 4]] * inverse([[1, 2], [3, 4]])", "[[1, 0], [0, 1]]", nil},
+		{"[[1, 2], [3, 4]] ^ 2", "[[7, 10], [15, 22]]", nil},
+		{"[[1, 1], [1, 0]] ^ 10", "[[89, 55], [55, 34]]", nil},
+		{"[[1, 2], [3, 4]] ^ 0", "[[1, 0], [0, 1]]", nil},
+		{"[[2, 0], [0, 4]] ^ -1", "[[0.5, 0], [0, 0.25]]", nil},
+		{"[[1 m, 0 m], [0 m, 2 m]] ^ 2", "[[1 m^2, 0 m^2], [0 m^2, 4 m^2]]", nil},
+		{"[1, 2] ^ 2", "[1, 4]", nil},
+		{"identity(3)", "[[1, 0, 0], [0, 1, 0], [0, 0, 1]]", nil},
+		{"dot([1, 2, 3], [4, 5, 6])", "32", nil},
+		{"dot([1 N, 2 N], [3 m, 4 m])", "11 N*m", nil},
+		{"cross([1, 0, 0], [0, 1, 0])", "[0, 0, 1]", nil},
+		{"cross([1, 2, 3], [4, 5, 6])", "[-3, 6, -3]", nil},
+		{"solve([[2, 1], [1, 3]], [3, 5])", "[0.8, 1.4]", nil},
+		{"solve([[0, 1], [1, 0]], [2 km, 3 km])", "[3 km, 2 km]", nil},
+		{"[[1, 2], [3, 4]] * [1, 2, 3]", "", ErrShape},
+		{"[[1, 2], [3, 4]] + [[1, 2, 3], [4, 5, 6]]", "", ErrListLength},
+		{"[[1, 2], [3, 4]] + [10, 20]", "", ErrShape},
+		{"det([[1, 2, 3], [4, 5, 6]])", "", ErrShape},
+		{"inverse([[1, 2], [2, 4]])", "", ErrSingular},
+		{"solve([[1, 1], [1, 1]], [1, 2])", "", ErrSingular},
+		{"solve([[1, 0], [0, 1]], [1, 2, 3])", "", ErrShape},
+		{"det([[1 m, 0], [0, 1]])", "", ErrIncompatibleUnits},
+		{"dot([1, 2], [1, 2, 3])", "", ErrShape},
+		{"cross([1, 2], [3, 4])", "", ErrShape},
+		{"identity(0)", "", ErrOutOfRange},
+		{"[[1, 2, 3], [4, 5, 6]] ^ 2", "", ErrShape},
+		{"[[1, 2], [3, 4]] ^ 0.5", "", ErrNotAnInteger},
+		{"[[1, 2], [2, 4]] ^ -1", "", ErrSingular},
+	}
+	for _, tt := range tests {
+		tokens, err := p.Tokenize(tt.input)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", tt.input, err)
+		}
+		expression, err := p.ReformToRPN(tokens)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", tt.input, err)
+		}
+		result, err := (&Evaluator{Env: env}).Evaluate(expression)
+		if !errors.Is(err, tt.err) {
+			t.Fatalf("%s: expected error %v, got %v", tt.input, tt.err, err)
+		}
+		if err == nil && result.String() != tt.result {
+			t.Errorf("%s: expected %s, got %s", tt.input, tt.result, result)
+		}
+	}
+}
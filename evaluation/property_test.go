@@ -0,0 +1,108 @@
+package evaluation
+
+import (
+	"errors"
+	"fmt"
+	"github.com/niklasstich/calculator/parser"
+	"github.com/niklasstich/calculator/util"
+	"strconv"
+	"testing"
+	"testing/quick"
+)
+
+// evaluateString parses and evaluates input with e
+func evaluateString(e Evaluator, input string) (*util.Token, error) {
+	tokens, err := parser.TokenizeString(input)
+	if err != nil {
+		return nil, err
+	}
+	tokens, err = parser.ReformToRPN(tokens)
+	if err != nil {
+		return nil, err
+	}
+	return e.Evaluate(tokens)
+}
+
+// value returns the result of any Mode as float64
+func value(t *util.Token) float64 {
+	switch {
+	case t.TokenInteger != nil:
+		return t.TokenInteger.Float()
+	case t.TokenBig != nil:
+		f, _ := t.TokenBig.Float64()
+		return f
+	}
+	return t.TokenOperand
+}
+
+func TestCommutativity(t *testing.T) {
+	modes := []Evaluator{{Mode: ModeFloat}, {Mode: ModeInteger}, {Mode: ModeBig}}
+	for _, op := range []string{"+", "*"} {
+		for _, e := range modes {
+			t.Run(fmt.Sprintf("%s(%d)", op, e.Mode), func(t *testing.T) {
+				commutes := func(a, b float64) bool {
+					x, y := strconv.FormatFloat(a, 'g', -1, 64), strconv.FormatFloat(b, 'g', -1, 64)
+					if e.Mode == ModeInteger {
+						x, y = strconv.FormatInt(int64(a), 10), strconv.FormatInt(int64(b), 10)
+					}
+					ab, err := evaluateString(e, x+" "+op+" "+y)
+					if err != nil {
+						t.Logf("%s %s %s: %v", x, op, y, err)
+						return false
+					}
+					ba, err := evaluateString(e, y+" "+op+" "+x)
+					if err != nil {
+						t.Logf("%s %s %s: %v", y, op, x, err)
+						return false
+					}
+					return ab.String() == ba.String()
+				}
+				if err := quick.Check(commutes, nil); err != nil {
+					t.Error(err)
+				}
+			})
+		}
+	}
+}
+
+// TestModesAgree makes sure all modes give the same result for integer operands, the operands are small enough for
+// every intermediate result to be exact in float64
+func TestModesAgree(t *testing.T) {
+	modes := []Evaluator{{Mode: ModeFloat}, {Mode: ModeInteger}, {Mode: ModeBig}}
+	for _, format := range []string{
+		"%d + %d * %d",
+		"%d - %d - %d",
+		"(%d + %d) * -(%d)",
+		"%d // %d + %d",
+		"%d mod %d - %d",
+		"%d rem (%d + %d)",
+		"%d * %d // %d",
+		"-(%d) ^ 2 + %d * %d",
+	} {
+		t.Run(format, func(t *testing.T) {
+			agree := func(a, b, c int16) bool {
+				input := fmt.Sprintf(format, a, b, c)
+				want, wantErr := evaluateString(modes[0], input)
+				if wantErr != nil && !errors.Is(wantErr, ErrDivByZero) {
+					t.Logf("%s: %v", input, wantErr)
+					return false
+				}
+				for _, e := range modes[1:] {
+					got, err := evaluateString(e, input)
+					if !errors.Is(err, wantErr) {
+						t.Logf("%s: expected error %v in mode %d, got %v", input, wantErr, e.Mode, err)
+						return false
+					}
+					if err == nil && value(got) != value(want) {
+						t.Logf("%s: expected %v in mode %d, got %v", input, want, e.Mode, got)
+						return false
+					}
+				}
+				return true
+			}
+			if err := quick.Check(agree, nil); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package evaluation
+
+import (
+	"errors"
+	"fmt"
+	"github.com/niklasstich/calculator/util"
+	"math"
+	"sort"
+)
+
+var ErrNotEnoughValues = errors.New("not enough values")
+var ErrOutOfRange = errors.New("argument is out of range")
+
+// statistics are the functions of lists, their arguments are numbers and lists which are treated as one list of all
+// their numbers, so sum([1, 2], 3) is 6. The values need compatible units, the result is in the unit of the first one.
+var statistics = map[string]struct {
+	// min is the number of values needed
+	min int
+	f   func(values []float64) float64
+	// power is the power of the unit of the values the result has, count has no unit and variance the square
+	power int
+}{
+	"count": {0, func(values []float64) float64 { return float64(len(values)) }, 0},
+	"sum":   {0, sum, 1},
+	"mean":  {1, mean, 1},
+	"median": {1, func(values []float64) float64 {
+		return percentile(values, 50)
+	}, 1},
+	"mode": {1, mode, 1},
+	"min": {1, func(values []float64) float64 {
+		return sorted(values)[0]
+	}, 1},
+	"max": {1, func(values []float64) float64 {
+		return sorted(values)[len(values)-1]
+	}, 1},
+	"variance": {2, func(values []float64) float64 {
+		return squares(values) / float64(len(values)-1)
+	}, 2},
+	"stdev": {2, func(values []float64) float64 {
+		return math.Sqrt(squares(values) / float64(len(values)-1))
+	}, 1},
+	"pvariance": {1, func(values []float64) float64 {
+		return squares(values) / float64(len(values))
+	}, 2},
+	"pstdev": {1, func(values []float64) float64 {
+		return math.Sqrt(squares(values) / float64(len(values)))
+	}, 1},
+}
+
+func init() {
+	for name, s := range statistics {
+		name, s := name, s
+		builtins[name] = &Builtin{Name: name, Params: Variadic, Lists: true,
+			Apply: func(args []util.Token) (util.Token, error) {
+				values, unit, err := numbers(name, args)
+				if err != nil {
+					return util.Token{}, err
+				}
+				if len(values) < s.min {
+					return util.Token{}, fmt.Errorf("%w: %s needs at least %d, got %d", ErrNotEnoughValues, name,
+						s.min, len(values))
+				}
+				return quantity(s.f(values), unit.Pow(s.power)), nil
+			}}
+	}
+	//percentile(list, p) is the value below which p percent of the values are, p may be a list of percentages
+	builtins["percentile"] = &Builtin{Name: "percentile", Params: 2, Lists: true,
+		Apply: func(args []util.Token) (util.Token, error) {
+			values, unit, err := numbers("percentile", args[:1])
+			if err != nil {
+				return util.Token{}, err
+			}
+			if len(values) == 0 {
+				return util.Token{}, fmt.Errorf("%w: percentile of an empty list", ErrNotEnoughValues)
+			}
+			return broadcast(args[1:], func(args []util.Token) (util.Token, error) {
+				p := args[0]
+				if p.TokenUnit != nil || !(p.TokenOperand >= 0 && p.TokenOperand <= 100) {
+					return util.Token{}, fmt.Errorf("%w: percentile %v is not between 0 and 100", ErrOutOfRange, p)
+				}
+				return quantity(percentile(values, p.TokenOperand), unit), nil
+			})
+		}}
+}
+
+// numbers returns the numbers of the arguments of a statistical function, nested lists included, in the unit of the
+// first one
+func numbers(name string, args []util.Token) ([]float64, *util.Unit, error) {
+	var flat []util.Token
+	var flatten func(tokens []util.Token)
+	flatten = func(tokens []util.Token) {
+		for _, t := range tokens {
+			if t.TokenList != nil {
+				flatten(t.TokenList)
+			} else {
+				flat = append(flat, t)
+			}
+		}
+	}
+	flatten(args)
+	if len(flat) == 0 {
+		return nil, nil, nil
+	}
+	unit := flat[0].TokenUnit
+	values := make([]float64, len(flat))
+	for i, t := range flat {
+		if isDate(&t) {
+			return nil, nil, fmt.Errorf("%w: %s takes numbers, got %v", ErrIncompatibleUnits, name, t)
+		}
+		if !t.TokenUnit.Compatible(unit) {
+			return nil, nil, fmt.Errorf("%w: %s of %v and %v", ErrIncompatibleUnits, name, flat[0], t)
+		}
+		values[i] = t.TokenOperand * t.TokenUnit.ConversionFactor(unit)
+	}
+	return values, unit, nil
+}
+
+func sum(values []float64) float64 {
+	total := 0.0
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
+
+func mean(values []float64) float64 {
+	return sum(values) / float64(len(values))
+}
+
+// squares returns the sum of the squared differences of the values from their mean
+func squares(values []float64) float64 {
+	m := mean(values)
+	total := 0.0
+	for _, v := range values {
+		total += (v - m) * (v - m)
+	}
+	return total
+}
+
+// mode returns the most frequent value, of several values which are equally frequent the one that comes first
+func mode(values []float64) float64 {
+	counts := make(map[float64]int, len(values))
+	for _, v := range values {
+		counts[v]++
+	}
+	best := values[0]
+	for _, v := range values {
+		if counts[v] > counts[best] {
+			best = v
+		}
+	}
+	return best
+}
+
+// percentile interpolates linearly between the closest ranks like PERCENTILE.INC of spreadsheets, so the 50th
+// percentile is the median
+func percentile(values []float64, p float64) float64 {
+	s := sorted(values)
+	rank := p / 100 * float64(len(s)-1)
+	i := int(math.Floor(rank))
+	if i >= len(s)-1 {
+		return s[len(s)-1]
+	}
+	return s[i] + (rank-float64(i))*(s[i+1]-s[i])
+}
+
+func sorted(values []float64) []float64 {
+	s := append([]float64(nil), values...)
+	sort.Float64s(s)
+	return s
+}
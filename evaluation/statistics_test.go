@@ -0,0 +1,110 @@
+package evaluation
+
+import (
+	"errors"
+	"github.com/niklasstich/calculator/parser"
+	"github.com/niklasstich/calculator/util"
+	"testing"
+)
+
+func TestLists(t *testing.T) {
+	env := &Environment{}
+	env.SetVariable("xs", list([]util.Token{quantity(1, nil), quantity(2, nil), quantity(3, nil)}))
+	p := parser.Parser{Scope: env}
+	var tests = []struct {
+		input, result string
+		err           error
+	}{
+		{"[1, 2, 3]", "[1, 2, 3]", nil},
+		{"[]", "[]", nil},
+		{"[1 + 1, [2, -3]]", "[2, [2, -3]]", nil},
+		{"[1, 2, 3] * 2", "[2, 4, 6]", nil},
+		{"10 - [1, 2]", "[9, 8]", nil},
+		{"[1, 2] + [10, 20]", "[11, 22]", nil},
+		{"2[1, 2]^2", "[2, 8]", nil},
+		{"-xs", "[-1, -2, -3]", nil},
+		{"[1, 2] km in m", "[1000 m, 2000 m]", nil},
+		{"[[1, 2], [3, 4]] + 10", "[[11, 12], [13, 14]]", nil},
+		{"xs > 1", "[false, true, true]", nil},
+		{"200 + [10, 50]%", "[220, 300]", nil},
+		{"sqrt([4, 9])", "[2, 3]", nil},
+		{"[1, 2] + [1, 2, 3]", "", ErrListLength},
+		{"[1, 2 m] + 1", "", ErrIncompatibleUnits},
+	}
+	for _, tt := range tests {
+		tokens, err := p.Tokenize(tt.input)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", tt.input, err)
+		}
+		expression, err := p.ReformToRPN(tokens)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", tt.input, err)
+		}
+		result, err := (&Evaluator{Env: env}).Evaluate(expression)
+		if !errors.Is(err, tt.err) {
+			t.Fatalf("%s: expected error %v, got %v", tt.input, tt.err, err)
+		}
+		if err == nil && result.String() != tt.result {
+			t.Errorf("%s: expected %s, got %s", tt.input, tt.result, result)
+		}
+	}
+
+	//lists are stored as text like numbers
+	v, err := ParseValue("[1.5, -2 km, [true], []]")
+	if err != nil || v.String() != "[1.5, -2 km, [true], []]" {
+		t.Errorf("Expected [1.5, -2 km, [true], []], got %v: %v", v, err)
+	}
+	if v, err := ParseValue("[1, 2] * 2"); err == nil {
+		t.Errorf("Expected an error, got %v", v)
+	}
+}
+
+func TestStatistics(t *testing.T) {
+	p := parser.Parser{Scope: &Environment{}}
+	var tests = []struct {
+		input, result string
+		err           error
+	}{
+		{"sum([1, 2, 3, 4])", "10", nil},
+		{"sum([1, 2], 3, [[4]])", "10", nil},
+		{"sum([])", "0", nil},
+		{"count([5, 5, 5])", "3", nil},
+		{"mean([2, 4, 9])", "5", nil},
+		{"median([3, 1, 2])", "2", nil},
+		{"median([4, 1, 3, 2])", "2.5", nil},
+		{"mode([1, 2, 2, 3, 3])", "2", nil},
+		{"min([3, -1, 2])", "-1", nil},
+		{"max(3, 7, 2)", "7", nil},
+		{"variance([2, 4, 4, 4, 5, 5, 7, 9])", "4.571428571428571", nil},
+		{"pvariance([2, 4, 4, 4, 5, 5, 7, 9])", "4", nil},
+		{"stdev([1, 3])", "1.4142135623730951", nil},
+		{"pstdev([2, 4, 4, 4, 5, 5, 7, 9])", "2", nil},
+		{"percentile([1, 2, 3, 4, 5], 25)", "2", nil},
+		{"percentile([15, 20, 35, 40, 50], 40)", "29", nil},
+		{"percentile([1, 2, 3, 4], [0, 50, 100])", "[1, 2.5, 4]", nil},
+		{"mean([1 km, 500 m])", "0.75 km", nil},
+		{"pvariance([1 m, 3 m])", "1 m^2", nil},
+		{"count([1 m, 2 s])", "", ErrIncompatibleUnits},
+		{"mean([])", "", ErrNotEnoughValues},
+		{"variance([1])", "", ErrNotEnoughValues},
+		{"percentile([1, 2], 101)", "", ErrOutOfRange},
+		{"percentile([1, 2])", "", ErrArgumentCount},
+	}
+	for _, tt := range tests {
+		tokens, err := p.Tokenize(tt.input)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", tt.input, err)
+		}
+		expression, err := p.ReformToRPN(tokens)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", tt.input, err)
+		}
+		result, err := (&Evaluator{Env: p.Scope.(*Environment)}).Evaluate(expression)
+		if !errors.Is(err, tt.err) {
+			t.Fatalf("%s: expected error %v, got %v", tt.input, tt.err, err)
+		}
+		if err == nil && result.String() != tt.result {
+			t.Errorf("%s: expected %s, got %s", tt.input, tt.result, result)
+		}
+	}
+}
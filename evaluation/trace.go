@@ -0,0 +1,65 @@
+package evaluation
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/niklasstich/calculator/util"
+	"strings"
+)
+
+// Step is a single step of an evaluation, either an operand pushed onto the stack or an operator applied to the
+// operands on top of it. The steps of the operands of an operator come before its own step, operands skipped by
+// &&, || and ?: have no steps.
+type Step struct {
+	Token util.Token
+	// Depth is the number of function calls the step is nested in, the steps of a function body come right before
+	// the step of the call
+	Depth int
+	// Before and After are the stack from the bottom to the top
+	Before []util.Token
+	After  []util.Token
+	// Result is the value pushed by an operator, it is nil for operands
+	Result *util.Token
+}
+
+// String prints the token and the stack before and after it, the steps of function bodies are indented
+func (s Step) String() string {
+	return fmt.Sprintf("%s%-6v [%s] -> [%s]", strings.Repeat("  ", s.Depth), s.Token,
+		strings.Join(util.TokenStrings(s.Before), " "), strings.Join(util.TokenStrings(s.After), " "))
+}
+
+// MarshalJSON writes all tokens of the step as text
+func (s Step) MarshalJSON() ([]byte, error) {
+	var result *string
+	if s.Result != nil {
+		r := s.Result.String()
+		result = &r
+	}
+	return json.Marshal(struct {
+		Token  string   `json:"token"`
+		Depth  int      `json:"depth"`
+		Before []string `json:"before"`
+		After  []string `json:"after"`
+		Result *string  `json:"result,omitempty"`
+	}{s.Token.String(), s.Depth, util.TokenStrings(s.Before), util.TokenStrings(s.After), result})
+}
+
+// snapshot returns the contents of the stack if the evaluation is traced, they are the Before of the next step
+func (e *Evaluator) snapshot(stack *util.TokenStack) []util.Token {
+	if e.Trace == nil {
+		return nil
+	}
+	return stack.Tokens()
+}
+
+// trace reports the step of token, which changed the stack from before to its current contents
+func (e *Evaluator) trace(token util.Token, depth int, before []util.Token, stack *util.TokenStack) {
+	if e.Trace == nil {
+		return
+	}
+	step := Step{Token: token, Depth: depth, Before: before, After: stack.Tokens()}
+	if token.TokenType == util.TokenTypeOperator {
+		step.Result = &step.After[len(step.After)-1]
+	}
+	e.Trace(step)
+}
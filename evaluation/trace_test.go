@@ -0,0 +1,77 @@
+package evaluation
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestTrace(t *testing.T) {
+	var tests = []struct {
+		input string
+		mode  Mode
+		steps []string
+	}{
+		{"1 + 2 * 3", ModeFloat, []string{
+			"1      [] -> [1]",
+			"2      [1] -> [1 2]",
+			"3      [1 2] -> [1 2 3]",
+			"*      [1 2 3] -> [1 6]",
+			"+      [1 6] -> [7]",
+		}},
+		{"1 > 2 && 1/0", ModeFloat, []string{
+			"1      [] -> [1]",
+			"2      [1] -> [1 2]",
+			">      [1 2] -> [false]",
+			"&&     [false] -> [false]",
+		}},
+		{"true ? 1 : 2", ModeFloat, []string{
+			"true   [] -> [true]",
+			"1      [true] -> [true 1]",
+			"?:     [true 1] -> [1]",
+		}},
+		{"-5 // 2", ModeInteger, []string{
+			"5      [] -> [5]",
+			"neg    [5] -> [-5]",
+			"2      [-5] -> [-5 2]",
+			"//     [-5 2] -> [-3]",
+		}},
+		{"0.1 + 0.2", ModeBig, []string{
+			"0.1    [] -> [0.1]",
+			"0.2    [0.1] -> [0.1 0.2]",
+			"+      [0.1 0.2] -> [0.3]",
+		}},
+	}
+	for _, tt := range tests {
+		var steps []string
+		e := Evaluator{Mode: tt.mode, Trace: func(s Step) {
+			steps = append(steps, s.String())
+		}}
+		if _, err := evaluateString(e, tt.input); err != nil {
+			t.Fatalf("%s: unexpected error %v", tt.input, err)
+		}
+		if strings.Join(steps, "\n") != strings.Join(tt.steps, "\n") {
+			t.Errorf("%s: wanted steps\n%s\ngot\n%s", tt.input, strings.Join(tt.steps, "\n"), strings.Join(steps, "\n"))
+		}
+	}
+}
+
+func TestStepJSON(t *testing.T) {
+	var steps []Step
+	e := Evaluator{Trace: func(s Step) {
+		steps = append(steps, s)
+	}}
+	if _, err := evaluateString(e, "2 * 3"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	b, err := json.Marshal(steps)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := `[{"token":"2","depth":0,"before":[],"after":["2"]},` +
+		`{"token":"3","depth":0,"before":["2"],"after":["2","3"]},` +
+		`{"token":"*","depth":0,"before":["2","3"],"after":["6"],"result":"6"}]`
+	if string(b) != want {
+		t.Errorf("Wanted %s, got %s", want, b)
+	}
+}
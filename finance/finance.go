@@ -0,0 +1,213 @@
+// Package finance implements the time value of money functions of spreadsheets like PMT and IRR, with the same
+// arguments and the same sign convention: money received is positive and money paid is negative, so the payments of a
+// loan have the opposite sign of the loan itself. Rates are per period, like 5%/12 for monthly payments at 5% a year.
+package finance
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+var ErrInvalidArgument = errors.New("invalid argument")
+var ErrNoConvergence = errors.New("no solution was found")
+
+// Due selects when in a period payments are made
+type Due int
+
+const (
+	// EndOfPeriod is the default of spreadsheets, the type 0
+	EndOfPeriod Due = iota
+	// StartOfPeriod is the type 1 of spreadsheets
+	StartOfPeriod
+)
+
+const (
+	// DefaultGuess is the rate Rate and IRR start searching at, like spreadsheets do
+	DefaultGuess = 0.1
+	//maxIterations and tolerance end the search for a rate, spreadsheets give up after 20 iterations
+	maxIterations = 100
+	tolerance     = 1e-12
+)
+
+// Payment returns the payment per period of a loan of pv which leaves fv after nper periods, like PMT
+func Payment(rate, nper, pv, fv float64, due Due) (float64, error) {
+	if nper == 0 {
+		return 0, fmt.Errorf("%w: payment for 0 periods", ErrInvalidArgument)
+	}
+	if rate == 0 {
+		return -(pv + fv) / nper, nil
+	}
+	g := math.Pow(1+rate, nper)
+	return -rate * (fv + pv*g) / (due.factor(rate) * (g - 1)), nil
+}
+
+// FutureValue returns the value after nper periods of pv and the payments pmt, like FV
+func FutureValue(rate, nper, pmt, pv float64, due Due) float64 {
+	return -balance(rate, nper, pmt, pv, 0, due)
+}
+
+// PresentValue returns the value now of the payments pmt and fv after nper periods, like PV
+func PresentValue(rate, nper, pmt, fv float64, due Due) float64 {
+	if rate == 0 {
+		return -(fv + pmt*nper)
+	}
+	g := math.Pow(1+rate, nper)
+	return -(fv + pmt*due.factor(rate)*(g-1)/rate) / g
+}
+
+// Periods returns the number of periods until the payments pmt turn pv into fv, like NPER
+func Periods(rate, pmt, pv, fv float64, due Due) (float64, error) {
+	if rate == 0 {
+		if pmt == 0 {
+			return 0, fmt.Errorf("%w: without interest and payments the value never changes", ErrInvalidArgument)
+		}
+		return -(pv + fv) / pmt, nil
+	}
+	p := pmt * due.factor(rate)
+	ratio := (p - fv*rate) / (p + pv*rate)
+	if !(ratio > 0) || rate <= -1 {
+		return 0, fmt.Errorf("%w: the payments %v never turn %v into %v", ErrInvalidArgument, pmt, pv, fv)
+	}
+	return math.Log(ratio) / math.Log(1+rate), nil
+}
+
+// Rate returns the interest rate per period at which the payments pmt turn pv into fv after nper periods, like RATE.
+// It is searched for starting at guess.
+func Rate(nper, pmt, pv, fv float64, due Due, guess float64) (float64, error) {
+	if nper <= 0 {
+		return 0, fmt.Errorf("%w: rate for %v periods", ErrInvalidArgument, nper)
+	}
+	r, err := root(func(rate float64) float64 {
+		return balance(rate, nper, pmt, pv, fv, due)
+	}, guess)
+	if err != nil {
+		return 0, fmt.Errorf("rate: %w", err)
+	}
+	return r, nil
+}
+
+// NPV returns the net present value of the cash flows at the end of the following periods, like NPV. Like in
+// spreadsheets the first value is discounted by one period already, an investment made now has to be added to the
+// result.
+func NPV(rate float64, values []float64) float64 {
+	total := 0.0
+	for i, v := range values {
+		total += v / math.Pow(1+rate, float64(i+1))
+	}
+	return total
+}
+
+// IRR returns the rate at which the net present value of the cash flows of consecutive periods is 0, like IRR. The
+// first value is not discounted, it is the investment made now. The search for the rate starts at guess.
+func IRR(values []float64, guess float64) (float64, error) {
+	positive, negative := false, false
+	for _, v := range values {
+		positive = positive || v > 0
+		negative = negative || v < 0
+	}
+	if !positive || !negative {
+		return 0, fmt.Errorf("%w: the cash flows need a positive and a negative value", ErrInvalidArgument)
+	}
+	r, err := root(func(rate float64) float64 {
+		total := 0.0
+		for i, v := range values {
+			total += v / math.Pow(1+rate, float64(i))
+		}
+		return total
+	}, guess)
+	if err != nil {
+		return 0, fmt.Errorf("irr: %w", err)
+	}
+	return r, nil
+}
+
+// factor is the share of a period the payments earn interest for in addition to the periods after them
+func (d Due) factor(rate float64) float64 {
+	if d == StartOfPeriod {
+		return 1 + rate
+	}
+	return 1
+}
+
+// balance returns the value of pv, the payments and fv at the end of nper periods, which is 0 if they are balanced
+func balance(rate, nper, pmt, pv, fv float64, due Due) float64 {
+	if rate == 0 {
+		return pv + pmt*nper + fv
+	}
+	g := math.Pow(1+rate, nper)
+	return pv*g + pmt*due.factor(rate)*(g-1)/rate + fv
+}
+
+// root finds a rate at which f is 0 with Newton's method, the derivative is approximated by a central difference.
+// Rates stay above -100%, at which all money is lost.
+func root(f func(rate float64) float64, guess float64) (float64, error) {
+	if !(guess > -1) {
+		return 0, fmt.Errorf("%w: guess %v is not above -100%%", ErrInvalidArgument, guess)
+	}
+	rate := guess
+	for i := 0; i < maxIterations; i++ {
+		h := 1e-6 * math.Max(1e-3, math.Abs(rate))
+		slope := (f(rate+h) - f(rate-h)) / (2 * h)
+		if slope == 0 || math.IsNaN(slope) || math.IsInf(slope, 0) {
+			break
+		}
+		next := rate - f(rate)/slope
+		if math.IsNaN(next) {
+			break
+		}
+		if next <= -1 {
+			//half way to -100% instead, this is not a solution even if the steps get small
+			rate = (rate - 1) / 2
+			continue
+		}
+		if math.Abs(next-rate) <= tolerance*math.Max(1, math.Abs(next)) {
+			return next, nil
+		}
+		rate = next
+	}
+	return 0, fmt.Errorf("%w after %d iterations starting at %v", ErrNoConvergence, maxIterations, guess)
+}
+
+// Installment is a row of an amortization schedule
+type Installment struct {
+	Period int
+	// Payment is the amount paid, which is split into Interest and Principal
+	Payment, Interest, Principal float64
+	// Balance is what is left of the loan after the payment
+	Balance float64
+}
+
+// Amortize returns the amortization schedule of a loan of pv which is paid back in nper periods down to fv, the
+// amounts are positive for a positive pv
+func Amortize(rate float64, nper int, pv, fv float64, due Due) ([]Installment, error) {
+	if nper <= 0 {
+		return nil, fmt.Errorf("%w: amortization over %d periods", ErrInvalidArgument, nper)
+	}
+	pmt, err := Payment(rate, float64(nper), pv, fv, due)
+	if err != nil {
+		return nil, err
+	}
+	schedule := make([]Installment, nper)
+	remaining := pv
+	for i := range schedule {
+		interest := remaining * rate
+		if due == StartOfPeriod && i == 0 {
+			//the first payment is made right away, before any interest is due
+			interest = 0
+		}
+		remaining -= -pmt - interest
+		if math.Abs(remaining) < 1e-9*math.Abs(pv) {
+			//rounding errors would leave a tiny balance at the end
+			remaining = 0
+		}
+		schedule[i] = Installment{
+			Period:    i + 1,
+			Payment:   -pmt,
+			Interest:  interest,
+			Principal: -pmt - interest,
+			Balance:   remaining,
+		}
+	}
+	return schedule, nil
+}
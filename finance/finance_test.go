@@ -0,0 +1,108 @@
+package finance
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// the expected values are those spreadsheets show, rounded to their number of decimals
+func TestSpreadsheet(t *testing.T) {
+	must := func(v float64, err error) float64 {
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return v
+	}
+	var tests = []struct {
+		formula  string
+		got      float64
+		want     float64
+		decimals int
+	}{
+		{"PMT(8%/12, 10, 10000)", must(Payment(0.08/12, 10, 10000, 0, EndOfPeriod)), -1037.03, 2},
+		{"PMT(6%/12, 18*12, 0, 50000)", must(Payment(0.06/12, 18*12, 0, 50000, EndOfPeriod)), -129.08, 2},
+		{"PMT(10%/12, 3, 2000, 0, 1)", must(Payment(0.1/12, 3, 2000, 0, StartOfPeriod)), -672.21, 2},
+		{"PMT(0, 12, 1200)", must(Payment(0, 12, 1200, 0, EndOfPeriod)), -100, 2},
+		{"FV(6%/12, 10, -200, -500, 1)", FutureValue(0.06/12, 10, -200, -500, StartOfPeriod), 2581.40, 2},
+		{"FV(12%/12, 12, -1000)", FutureValue(0.12/12, 12, -1000, 0, EndOfPeriod), 12682.50, 2},
+		{"FV(11%/12, 35, -2000, 0, 1)", FutureValue(0.11/12, 35, -2000, 0, StartOfPeriod), 82846.25, 2},
+		{"PV(8%/12, 12*20, 500)", PresentValue(0.08/12, 12*20, 500, 0, EndOfPeriod), -59777.15, 2},
+		{"PV(0, 10, -100, -50)", PresentValue(0, 10, -100, -50, EndOfPeriod), 1050, 2},
+		{"NPER(12%/12, -100, -1000, 10000, 1)", must(Periods(0.01, -100, -1000, 10000, StartOfPeriod)), 59.6738657, 7},
+		{"NPER(12%/12, -100, -1000)", must(Periods(0.01, -100, -1000, 0, EndOfPeriod)), -9.57859404, 8},
+		{"RATE(4*12, -200, 8000)", must(Rate(48, -200, 8000, 0, EndOfPeriod, DefaultGuess)), 0.0077, 4},
+		{"RATE(4*12, -200, 8000)*12", 12 * must(Rate(48, -200, 8000, 0, EndOfPeriod, DefaultGuess)), 0.0924, 4},
+		{"NPV(10%, -10000, 3000, 4200, 6800)", NPV(0.1, []float64{-10000, 3000, 4200, 6800}), 1188.44, 2},
+		{"NPV(8%, 8000, 9200, 10000, 12000, 14500) - 40000",
+			NPV(0.08, []float64{8000, 9200, 10000, 12000, 14500}) - 40000, 1922.06, 2},
+		{"IRR(-70000, 12000, 15000, 18000, 21000)",
+			must(IRR([]float64{-70000, 12000, 15000, 18000, 21000}, DefaultGuess)), -0.021, 3},
+		{"IRR(-70000, 12000, 15000, 18000, 21000, 26000)",
+			must(IRR([]float64{-70000, 12000, 15000, 18000, 21000, 26000}, DefaultGuess)), 0.0866, 4},
+		{"IRR(-70000, 12000, 15000, -10%)", must(IRR([]float64{-70000, 12000, 15000}, -0.1)), -0.4435, 4},
+	}
+	for _, tt := range tests {
+		if got := math.Round(tt.got*math.Pow(10, float64(tt.decimals))) / math.Pow(10, float64(tt.decimals)); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.formula, tt.want, tt.got)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	var tests = []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"payment for 0 periods", second(Payment(0.1, 0, 100, 0, EndOfPeriod)), ErrInvalidArgument},
+		{"periods without interest and payments", second(Periods(0, 0, 100, 0, EndOfPeriod)), ErrInvalidArgument},
+		{"periods which never pay back", second(Periods(0.1, -5, 100, 0, EndOfPeriod)), ErrInvalidArgument},
+		{"irr without investment", second(IRR([]float64{100, 200}, DefaultGuess)), ErrInvalidArgument},
+		{"rate of an impossible loan", second(Rate(10, 100, 100, 100, EndOfPeriod, DefaultGuess)), ErrNoConvergence},
+		{"guess below -100%", second(IRR([]float64{-100, 200}, -2)), ErrInvalidArgument},
+	}
+	for _, tt := range tests {
+		if !errors.Is(tt.err, tt.want) {
+			t.Errorf("%s: expected error %v, got %v", tt.name, tt.want, tt.err)
+		}
+	}
+}
+
+func second(_ float64, err error) error {
+	return err
+}
+
+func TestAmortize(t *testing.T) {
+	schedule, err := Amortize(0.1/12, 24, 2000, 0, EndOfPeriod)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(schedule) != 24 {
+		t.Fatalf("Expected 24 installments, got %d", len(schedule))
+	}
+	//PPMT(10%/12, 1, 24, 2000) and IPMT(10%/12, 1, 24, 2000)
+	first := schedule[0]
+	if math.Abs(first.Payment-92.29) > 0.005 || math.Abs(first.Interest-16.67) > 0.005 ||
+		math.Abs(first.Principal-75.62) > 0.005 || math.Abs(first.Balance-1924.38) > 0.005 {
+		t.Errorf("Unexpected first installment %+v", first)
+	}
+	principal := 0.0
+	for _, installment := range schedule {
+		principal += installment.Principal
+	}
+	if last := schedule[23]; last.Period != 24 || last.Balance != 0 || math.Abs(principal-2000) > 1e-9 {
+		t.Errorf("Expected the loan to be paid back, got %+v and a principal of %v", last, principal)
+	}
+
+	schedule, err = Amortize(0.1/12, 3, 2000, 0, StartOfPeriod)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if schedule[0].Interest != 0 || schedule[2].Balance != 0 {
+		t.Errorf("Unexpected schedule %+v", schedule)
+	}
+	if _, err := Amortize(0.1, 0, 100, 0, EndOfPeriod); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("Expected error %v, got %v", ErrInvalidArgument, err)
+	}
+}
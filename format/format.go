@@ -0,0 +1,298 @@
+// Package format turns evaluation results into text. All front ends use a Formatter, so results look the same
+// everywhere for the same settings.
+package format
+
+import (
+	"github.com/niklasstich/calculator/util"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Mode selects how a Formatter prints non-integer results
+type Mode int
+
+const (
+	// ModeShortest prints the shortest representation that parses back to the same value, with an exponent only for
+	// very large or small values
+	ModeShortest Mode = iota
+	// ModeFixed prints Precision digits after the decimal mark
+	ModeFixed
+	// ModeSignificant prints Precision significant digits
+	ModeSignificant
+	// ModeScientific prints one digit before the decimal mark, Precision digits after it and an exponent
+	ModeScientific
+	// ModeEngineering is like ModeScientific, but the exponent is always a multiple of 3
+	ModeEngineering
+	// ModeFraction prints the value as a fraction like 3/4 with a denominator of at most MaxDenominator
+	ModeFraction
+)
+
+// DefaultMaxDenominator is used by ModeFraction if MaxDenominator is not set
+const DefaultMaxDenominator = 10000
+
+// Formatter formats result tokens. The zero value prints results in ModeShortest with English decimal points.
+type Formatter struct {
+	Mode Mode
+	// Precision is the number of digits used by ModeFixed, ModeSignificant, ModeScientific and ModeEngineering,
+	// a negative Precision prints as many digits as needed in ModeScientific and ModeEngineering
+	Precision int
+	// MaxDenominator is the largest denominator tried in ModeFraction
+	MaxDenominator int64
+	// Base is used for integer results of programmer mode, 10 if not set
+	Base int
+	// Grouping enables the group separator of the Locale between groups of thousands
+	Grouping bool
+	// Locale defines the decimal mark and group separator, util.LocaleEnglish if not set
+	Locale util.Locale
+}
+
+// Format prints the token according to the settings of the Formatter, including its unit. The elements of lists are
+// separated by the argument separator of the Locale, so they can be read again.
+func (f Formatter) Format(t *util.Token) string {
+	if t.TokenList != nil {
+		locale := f.Locale
+		if locale.DecimalMark == 0 {
+			locale = util.LocaleEnglish
+		}
+		elements := make([]string, len(t.TokenList))
+		for i := range t.TokenList {
+			elements[i] = f.Format(&t.TokenList[i])
+		}
+		return "[" + strings.Join(elements, string(locale.ArgumentSeparator)+" ") + "]"
+	}
+	if t.TokenType != util.TokenTypeOperand || t.TokenBoolean || t.TokenTime != nil || t.TokenZone != nil {
+		return t.String()
+	}
+	var value string
+	if t.TokenInteger != nil {
+		value = f.formatInteger(*t.TokenInteger)
+	} else if t.TokenBig != nil {
+		value = f.formatBig(t.TokenBig)
+	} else {
+		value = f.FormatFloat(t.TokenOperand)
+	}
+	if t.TokenUnit != nil {
+		return value + " " + t.TokenUnit.String()
+	}
+	return value
+}
+
+// FormatFloat prints a single number according to the settings of the Formatter
+func (f Formatter) FormatFloat(v float64) string {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	var s string
+	switch f.Mode {
+	case ModeFixed:
+		s = strconv.FormatFloat(v, 'f', f.Precision, 64)
+	case ModeSignificant:
+		s = significant(v, f.Precision)
+	case ModeScientific:
+		s = strconv.FormatFloat(v, 'e', f.Precision, 64)
+	case ModeEngineering:
+		s = engineering(v, f.Precision)
+	case ModeFraction:
+		maxDen := f.MaxDenominator
+		if maxDen <= 0 {
+			maxDen = DefaultMaxDenominator
+		}
+		if num, den, ok := fraction(v, maxDen); ok {
+			if den == 1 {
+				return f.localize(strconv.FormatInt(num, 10))
+			}
+			return f.localize(strconv.FormatInt(num, 10)) + "/" + f.localize(strconv.FormatInt(den, 10))
+		}
+		s = shortest(v)
+	default:
+		s = shortest(v)
+	}
+	return f.localize(s)
+}
+
+func (f Formatter) formatInteger(i util.Integer) string {
+	if f.Base != 0 && f.Base != 10 {
+		return i.Format(f.Base)
+	}
+	return f.localize(i.Format(10))
+}
+
+// formatBig prints arbitrary precision results with all their digits in ModeShortest, ModeFixed and ModeScientific,
+// the other modes use the float64 value. Values beyond util.MaxBigExp are printed by util.BigText in every mode.
+func (f Formatter) formatBig(v *big.Float) string {
+	if exp := v.MantExp(nil); exp < -util.MaxBigExp || exp > util.MaxBigExp {
+		return f.localize(util.BigText(v))
+	}
+	var s string
+	switch f.Mode {
+	case ModeShortest:
+		s = v.Text('f', -1)
+		//the binary exponent approximates the range of shortest, 1e-4 to 1e21
+		if exp := v.MantExp(nil); v.Sign() != 0 && (exp < -13 || exp > 70) {
+			s = v.Text('e', -1)
+		}
+	case ModeFixed:
+		s = v.Text('f', f.Precision)
+	case ModeScientific:
+		s = v.Text('e', f.Precision)
+	default:
+		fv, _ := v.Float64()
+		return f.FormatFloat(fv)
+	}
+	return f.localize(s)
+}
+
+// localize replaces the decimal point of a number formatted by strconv with the decimal mark of the Locale and
+// inserts group separators into the integer part if Grouping is enabled
+func (f Formatter) localize(s string) string {
+	locale := f.Locale
+	if locale.DecimalMark == 0 {
+		locale = util.LocaleEnglish
+	}
+	mantissa, exponent := s, ""
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		mantissa, exponent = s[:i], s[i:]
+	}
+	intPart, fracPart := mantissa, ""
+	if i := strings.IndexByte(mantissa, '.'); i >= 0 {
+		intPart, fracPart = mantissa[:i], mantissa[i+1:]
+	}
+	if f.Grouping {
+		intPart = group(intPart, locale.GroupSeparator)
+	}
+	if fracPart != "" {
+		return intPart + string(locale.DecimalMark) + fracPart + exponent
+	}
+	return intPart + exponent
+}
+
+// group inserts sep between groups of three digits, s may have a sign
+func group(s string, sep int32) string {
+	sign := ""
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		sign, s = s[:1], s[1:]
+	}
+	if len(s) <= 3 {
+		return sign + s
+	}
+	var b strings.Builder
+	b.WriteString(sign)
+	first := len(s) % 3
+	if first > 0 {
+		b.WriteString(s[:first])
+	}
+	for i := first; i < len(s); i += 3 {
+		if i > 0 {
+			b.WriteRune(sep)
+		}
+		b.WriteString(s[i : i+3])
+	}
+	return b.String()
+}
+
+// shortest prints v with as few digits as possible, using an exponent only outside of 1e-4 to 1e21
+func shortest(v float64) string {
+	if abs := math.Abs(v); abs != 0 && (abs < 1e-4 || abs >= 1e21) {
+		return strconv.FormatFloat(v, 'e', -1, 64)
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// significant rounds v to digits significant digits and prints it without exponent unless it is very large or small
+func significant(v float64, digits int) string {
+	if digits < 1 {
+		digits = 1
+	}
+	if v == 0 {
+		return strconv.FormatFloat(0, 'f', digits-1, 64)
+	}
+	rounded, _ := strconv.ParseFloat(strconv.FormatFloat(v, 'e', digits-1, 64), 64)
+	exp := int(math.Floor(math.Log10(math.Abs(rounded))))
+	if exp < -4 || exp >= 21 {
+		return strconv.FormatFloat(rounded, 'e', digits-1, 64)
+	}
+	decimals := digits - 1 - exp
+	if decimals < 0 {
+		decimals = 0
+	}
+	return strconv.FormatFloat(rounded, 'f', decimals, 64)
+}
+
+// engineering prints v with an exponent that is a multiple of 3 and a mantissa between 1 and 1000
+func engineering(v float64, precision int) string {
+	if v == 0 {
+		return strconv.FormatFloat(0, 'f', precision, 64) + "e+00"
+	}
+	digits, exp := decimalDigits(v, -1)
+	shift := ((exp % 3) + 3) % 3
+	if precision >= 0 {
+		digits, exp = decimalDigits(v, shift+precision)
+		//rounding can change the exponent, e.g. 999.96 with one decimal
+		if newShift := ((exp % 3) + 3) % 3; newShift != shift {
+			shift = newShift
+			digits, exp = decimalDigits(v, shift+precision)
+		}
+	}
+	sign := ""
+	if digits[0] == '-' {
+		sign, digits = "-", digits[1:]
+	}
+	//move the decimal point shift digits to the right
+	for len(digits) < shift+1 {
+		digits += "0"
+	}
+	mantissa := digits[:shift+1]
+	if len(digits) > shift+1 {
+		mantissa += "." + digits[shift+1:]
+	}
+	exp -= shift
+	expSign := "+"
+	if exp < 0 {
+		expSign = "-"
+		exp = -exp
+	}
+	e := strconv.Itoa(exp)
+	if len(e) < 2 {
+		e = "0" + e
+	}
+	return sign + mantissa + "e" + expSign + e
+}
+
+// decimalDigits returns the significant digits of v rounded to decimals+1 digits, including a sign but without
+// the decimal point, and the decimal exponent of the first digit
+func decimalDigits(v float64, decimals int) (string, int) {
+	s := strconv.FormatFloat(v, 'e', decimals, 64)
+	i := strings.IndexByte(s, 'e')
+	exp, _ := strconv.Atoi(s[i+1:])
+	return strings.Replace(s[:i], ".", "", 1), exp
+}
+
+// fraction approximates v with a continued fraction, it fails if there is no fraction with a denominator of at most
+// maxDen that is equal to v within float64 precision
+func fraction(v float64, maxDen int64) (num, den int64, ok bool) {
+	if math.Abs(v) >= 1<<53 {
+		return 0, 0, false
+	}
+	//convergents h/k of the continued fraction
+	h0, h1 := int64(0), int64(1)
+	k0, k1 := int64(1), int64(0)
+	x := v
+	for i := 0; i < 64; i++ {
+		a := math.Floor(x)
+		h0, h1 = h1, int64(a)*h1+h0
+		k0, k1 = k1, int64(a)*k1+k0
+		if k1 > maxDen {
+			return 0, 0, false
+		}
+		if math.Abs(float64(h1)/float64(k1)-v) <= 1e-12*math.Max(1, math.Abs(v)) {
+			return h1, k1, true
+		}
+		x = 1 / (x - a)
+		if math.IsInf(x, 0) {
+			break
+		}
+	}
+	return 0, 0, false
+}
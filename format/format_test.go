@@ -0,0 +1,99 @@
+package format
+
+import (
+	"fmt"
+	"github.com/niklasstich/calculator/util"
+	"math/big"
+	"testing"
+)
+
+func TestFormatFloat(t *testing.T) {
+	var tests = []struct {
+		f    Formatter
+		v    float64
+		want string
+	}{
+		{Formatter{}, 1234.5678901234567890, "1234.567890123457"},
+		{Formatter{}, 3.6e6, "3600000"},
+		{Formatter{}, 1.5e22, "1.5e+22"},
+		{Formatter{}, 0.00001, "1e-05"},
+		{Formatter{Mode: ModeFixed, Precision: 2}, 3.14159, "3.14"},
+		{Formatter{Mode: ModeFixed, Precision: 2}, 5, "5.00"},
+		{Formatter{Mode: ModeFixed}, 2.5, "2"},
+		{Formatter{Mode: ModeSignificant, Precision: 3}, 1234567, "1230000"},
+		{Formatter{Mode: ModeSignificant, Precision: 3}, 0.0012345, "0.00123"},
+		{Formatter{Mode: ModeSignificant, Precision: 3}, 9.996, "10.0"},
+		{Formatter{Mode: ModeSignificant, Precision: 2}, 1.5e-7, "1.5e-07"},
+		{Formatter{Mode: ModeScientific, Precision: 3}, 123456, "1.235e+05"},
+		{Formatter{Mode: ModeScientific, Precision: -1}, 0.00025, "2.5e-04"},
+		{Formatter{Mode: ModeEngineering, Precision: 2}, 12346, "12.35e+03"},
+		{Formatter{Mode: ModeEngineering, Precision: -1}, 0.00025, "250e-06"},
+		{Formatter{Mode: ModeEngineering, Precision: 1}, 999.96, "1.0e+03"},
+		{Formatter{Mode: ModeEngineering, Precision: 0}, -4.7e-9, "-5e-09"},
+		{Formatter{Mode: ModeFraction}, 0.75, "3/4"},
+		{Formatter{Mode: ModeFraction}, -1.5, "-3/2"},
+		{Formatter{Mode: ModeFraction}, 1.0 / 3, "1/3"},
+		{Formatter{Mode: ModeFraction}, 4, "4"},
+		{Formatter{Mode: ModeFraction, MaxDenominator: 100}, 0.1234, "0.1234"},
+		{Formatter{Grouping: true}, 1234567.891, "1,234,567.891"},
+		{Formatter{Grouping: true}, -123456, "-123,456"},
+		{Formatter{Grouping: true}, 999, "999"},
+		{Formatter{Locale: util.LocaleGerman}, 3.5, "3,5"},
+		{Formatter{Grouping: true, Locale: util.LocaleGerman, Mode: ModeFixed, Precision: 2}, 1234.5, "1.234,50"},
+		{Formatter{Grouping: true, Locale: util.LocaleSwiss}, 1234567, "1'234'567"},
+		{Formatter{Locale: util.LocaleFrench, Mode: ModeScientific, Precision: 1}, 1500, "1,5e+03"},
+	}
+
+	for i, tt := range tests {
+		testname := fmt.Sprintf("%d: %s", i+1, tt.want)
+		t.Run(testname, func(t *testing.T) {
+			if got := tt.f.FormatFloat(tt.v); got != tt.want {
+				t.Errorf("Wanted %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	third := new(big.Float).SetPrec(100).Quo(big.NewFloat(1), big.NewFloat(3))
+	large := new(big.Float).SetPrec(100).SetInt64(1 << 62)
+	km := &util.Unit{Terms: []util.UnitTerm{{Symbol: "km", Power: 1}}, Factor: 1000,
+		Dimension: util.Dimension{util.DimLength: 1}}
+
+	var tests = []struct {
+		f    Formatter
+		t    util.Token
+		want string
+	}{
+		{Formatter{}, util.Token{TokenType: util.TokenTypeOperand, TokenOperand: 5}, "5"},
+		{Formatter{Mode: ModeFixed, Precision: 1}, util.Token{TokenType: util.TokenTypeOperand, TokenOperand: 9,
+			TokenUnit: km}, "9.0 km"},
+		{Formatter{Base: 16}, util.Token{TokenType: util.TokenTypeOperand,
+			TokenInteger: &util.Integer{Value: util.Int8.Wrap(255), Type: util.Int8}}, "0xff"},
+		{Formatter{Grouping: true}, util.Token{TokenType: util.TokenTypeOperand,
+			TokenInteger: &util.Integer{Value: 1 << 20, Type: util.Int32}}, "1,048,576"},
+		{Formatter{Mode: ModeFixed, Precision: 2}, util.Token{TokenType: util.TokenTypeOperand,
+			TokenInteger: &util.Integer{Value: 3, Type: util.Int32}}, "3"},
+		{Formatter{}, util.Token{TokenType: util.TokenTypeOperand, TokenBig: third},
+			"0.3333333333333333333333333333335"},
+		{Formatter{Mode: ModeFixed, Precision: 25, Locale: util.LocaleGerman}, util.Token{
+			TokenType: util.TokenTypeOperand, TokenBig: third}, "0,3333333333333333333333333"},
+		{Formatter{Grouping: true}, util.Token{TokenType: util.TokenTypeOperand, TokenBig: large},
+			"4,611,686,018,427,387,904"},
+		{Formatter{Mode: ModeScientific, Precision: 3}, util.Token{TokenType: util.TokenTypeOperand, TokenBig: large},
+			"4.612e+18"},
+		{Formatter{}, util.Token{TokenType: util.TokenTypeOperator, TokenOperator: &util.Operator{Char: '+'}}, "+"},
+		{Formatter{Mode: ModeFixed, Precision: 1, Locale: util.LocaleGerman}, util.Token{
+			TokenType: util.TokenTypeOperand, TokenList: []util.Token{{TokenOperand: 1.5}, {TokenOperand: 2,
+				TokenUnit: km}, {TokenList: []util.Token{}}}}, "[1,5; 2,0 km; []]"},
+	}
+
+	for i, tt := range tests {
+		testname := fmt.Sprintf("%d: %s", i+1, tt.want)
+		t.Run(testname, func(t *testing.T) {
+			if got := tt.f.Format(&tt.t); got != tt.want {
+				t.Errorf("Wanted %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+// Package gui implements the window of the calculator with fyne. The input is run like a line of the command line, so
+// it may contain several statements and define functions, the result of the last statement is shown. Previous
+// calculations can be searched in the history panel and selected to edit them again, and the memory keys store the
+// last result in registers which can be used in expressions. In RPN mode the input is pushed onto a stack instead,
+// which is shown above the keys of its operators and commands. The plot panel draws functions of x, which can be
+// exported to PNG and SVG, and the table panel evaluates a formula over ranges of its variables. The window has no
+// controls for the mode, base, locale and percent mode, they are set in the fields of Calculator before it is shown.
+package gui
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"github.com/niklasstich/calculator/evaluation"
+	"github.com/niklasstich/calculator/format"
+	"github.com/niklasstich/calculator/history"
+	"github.com/niklasstich/calculator/memory"
+	"github.com/niklasstich/calculator/parser"
+	"github.com/niklasstich/calculator/plot"
+	"github.com/niklasstich/calculator/rpn"
+	"github.com/niklasstich/calculator/script"
+	"github.com/niklasstich/calculator/table"
+	"github.com/niklasstich/calculator/util"
+	"strings"
+)
+
+// Calculator holds the settings and the widgets of a calculator window
+type Calculator struct {
+	Parser    parser.Parser
+	Evaluator evaluation.Evaluator
+	Formatter format.Formatter
+	Env       *evaluation.Environment
+	// History records every expression, there is no history panel if it is nil
+	History *history.History
+	// Memory holds the registers of the memory keys, there are no memory keys if it is nil
+	Memory *memory.Registers
+	// Stack is used instead of evaluating the input in RPN mode
+	Stack *rpn.Stack
+	// Window shows the dialogs to export plots and has the clipboard tables are copied to, plots can't be exported and
+	// tables can't be copied from the panels if it is nil
+	Window fyne.Window
+
+	input  *widget.Entry
+	result *widget.Label
+	//last is the last result, which the memory keys use
+	last *util.Token
+	//register is the name of the register the memory keys use, registers shows the values of all registers
+	register  *widget.Entry
+	registers *widget.Label
+	//mode switches the RPN mode on, stack shows the Stack while it is on
+	mode  *widget.Check
+	rpn   bool
+	stack *widget.Label
+	//trace shows the steps of parsing and evaluating the last input in an expandable panel
+	trace *widget.Label
+	//search filters the history list, found holds the entries shown
+	search  *widget.Entry
+	entries *widget.List
+	found   []history.Entry
+	//plot holds the curves of the functions entered in the plot panel, which are drawn by graph
+	plot      *plot.Plot
+	functions *widget.Entry
+	graph     *graph
+	plotError *widget.Label
+	//tableData is the table generated from tableSpec, which tableView shows
+	tableData   *table.Table
+	tableSpec   *widget.Entry
+	tableView   *widget.Table
+	tableFormat *widget.Select
+	tableError  *widget.Label
+}
+
+// New returns a Calculator with an empty Environment
+func New() *Calculator {
+	c := &Calculator{Env: &evaluation.Environment{}, Stack: &rpn.Stack{}}
+	c.input = widget.NewEntry()
+	c.input.SetPlaceHolder("Expression")
+	c.input.OnSubmitted = func(string) {
+		c.Evaluate()
+	}
+	c.result = widget.NewLabel("")
+	c.register = widget.NewEntry()
+	c.register.SetPlaceHolder(memory.Default)
+	c.registers = widget.NewLabel("")
+	c.mode = widget.NewCheck("RPN", nil)
+	c.stack = widget.NewLabelWithStyle("", fyne.TextAlignTrailing, fyne.TextStyle{Monospace: true})
+	c.trace = widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Monospace: true})
+	c.search = widget.NewEntry()
+	c.search.SetPlaceHolder("Search")
+	c.search.OnChanged = func(string) {
+		c.refreshHistory()
+	}
+	c.entries = widget.NewList(
+		func() int { return len(c.found) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, o fyne.CanvasObject) {
+			o.(*widget.Label).SetText(c.found[id].String())
+		},
+	)
+	c.entries.OnSelected = func(id widget.ListItemID) {
+		c.Recall(c.found[id])
+		c.entries.Unselect(id)
+	}
+	c.plot = &plot.Plot{}
+	c.functions = widget.NewMultiLineEntry()
+	c.functions.SetPlaceHolder("Functions of x, one per line")
+	c.graph = newGraph(c)
+	c.plotError = widget.NewLabel("")
+	c.tableSpec = widget.NewEntry()
+	c.tableSpec.SetPlaceHolder("x^2 for x from 0 to 10 step 0.5")
+	c.tableSpec.OnSubmitted = func(string) {
+		c.Tabulate()
+	}
+	c.tableView = c.newTableView()
+	c.tableFormat = widget.NewSelect(tableFormats, nil)
+	c.tableFormat.SetSelected(tableFormats[0])
+	c.tableError = widget.NewLabel("")
+	return c
+}
+
+// Content returns the widgets of the window
+func (c *Calculator) Content() fyne.CanvasObject {
+	panels := widget.NewAccordion(
+		widget.NewAccordionItem("Trace", c.trace),
+		widget.NewAccordionItem("Plot", c.plotPanel()),
+		widget.NewAccordionItem("Table", c.tablePanel()),
+	)
+	if c.History != nil {
+		c.refreshHistory()
+		private := widget.NewCheck("Private", func(on bool) {
+			c.History.Private = on
+		})
+		private.SetChecked(c.History.Private)
+		//the list needs a size, it shows nothing in a box which only gives it its minimum size
+		list := container.NewBorder(container.NewBorder(nil, nil, nil, private, c.search), nil, nil, nil,
+			container.NewGridWrap(fyne.NewSize(400, 200), c.entries))
+		panels.Append(widget.NewAccordionItem("History", list))
+	}
+	content := container.NewVBox(
+		container.NewBorder(nil, nil, nil, container.NewHBox(c.mode, widget.NewButton("=", c.Evaluate)), c.input),
+		c.result,
+		c.rpnKeys(),
+	)
+	if c.Memory != nil {
+		content.Add(c.memoryKeys())
+	}
+	content.Add(panels)
+	return content
+}
+
+// Recall puts the expression of the entry into the input, so it can be changed and evaluated again
+func (c *Calculator) Recall(e history.Entry) {
+	c.input.SetText(e.Expression)
+}
+
+// refreshHistory shows the entries matching the search, the newest first
+func (c *Calculator) refreshHistory() {
+	found := c.History.Search(c.search.Text)
+	c.found = make([]history.Entry, len(found))
+	for i, e := range found {
+		c.found[len(found)-1-i] = e
+	}
+	c.entries.Refresh()
+}
+
+// Evaluate runs the input and shows its result and trace, in RPN mode it passes the input to the Stack instead
+func (c *Calculator) Evaluate() {
+	if c.rpn {
+		c.enter(c.input.Text)
+		return
+	}
+	var steps []string
+	runner := script.Runner{Parser: c.Parser, Evaluator: c.Evaluator, Env: c.Env}
+	runner.Parser.Trace = func(s parser.ParseStep) {
+		steps = append(steps, "parse "+s.String())
+	}
+	runner.Evaluator.Trace = func(s evaluation.Step) {
+		steps = append(steps, "eval  "+s.String())
+	}
+	var historyErr error
+	if c.History != nil {
+		runner.Record = func(res script.Result) {
+			if _, err := c.History.Add(res.Text, res.Value, res.Err); err != nil && historyErr == nil {
+				historyErr = err
+			}
+		}
+	}
+	results := runner.Run(c.input.Text)
+	c.trace.SetText(strings.Join(steps, "\n"))
+	if c.History != nil {
+		c.refreshHistory()
+	}
+	if historyErr != nil {
+		c.result.SetText("error: history: " + historyErr.Error())
+		return
+	}
+	if len(results) == 0 {
+		c.result.SetText("")
+		return
+	}
+	res := results[len(results)-1]
+	switch {
+	case res.Err != nil:
+		c.result.SetText("error: " + res.Err.Error())
+	case res.Definition != nil:
+		c.result.SetText(res.Definition.Source)
+	default:
+		c.last = res.Value
+		c.result.SetText(c.Formatter.Format(res.Value))
+	}
+}
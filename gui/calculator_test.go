@@ -0,0 +1,188 @@
+package gui
+
+import (
+	"bytes"
+	"fyne.io/fyne/v2/test"
+	"github.com/niklasstich/calculator/history"
+	"github.com/niklasstich/calculator/memory"
+	"strings"
+	"testing"
+)
+
+func TestEvaluate(t *testing.T) {
+	test.NewApp()
+	c := New()
+	c.Content()
+	var tests = []struct {
+		input, result, trace string
+	}{
+		{"1 + 2", "3", "parse end    output [1 2 +] operators []\neval  1      [] -> [1]"},
+		{"f(x) = 2x; f(4)", "8", "eval    x      [2] -> [2 4]\neval    *      [2 4] -> [8]\neval  f      [4] -> [8]"},
+		{"1 / 0", "error: division by 0", "eval  0      [1] -> [1 0]"},
+	}
+	for _, tt := range tests {
+		c.input.SetText("")
+		test.Type(c.input, tt.input)
+		c.Evaluate()
+		if c.result.Text != tt.result {
+			t.Errorf("%s: wanted result %q, got %q", tt.input, tt.result, c.result.Text)
+		}
+		if !strings.Contains(c.trace.Text, tt.trace) {
+			t.Errorf("%s: wanted %q in trace, got %q", tt.input, tt.trace, c.trace.Text)
+		}
+	}
+}
+
+func TestHistory(t *testing.T) {
+	test.NewApp()
+	c := New()
+	c.History = &history.History{Env: c.Env}
+	c.Content()
+	for _, input := range []string{"2 km", "ans * 3", "1 + 1"} {
+		c.input.SetText(input)
+		c.Evaluate()
+	}
+	if c.result.Text != "2" {
+		t.Errorf("Wanted result 2, got %q", c.result.Text)
+	}
+	test.Type(c.search, "km")
+	if len(c.found) != 2 || c.found[0].Result != "6 km" {
+		t.Fatalf("Unexpected entries %v", c.found)
+	}
+	c.entries.Select(1)
+	if c.input.Text != "2 km" {
+		t.Errorf("Wanted recalled input %q, got %q", "2 km", c.input.Text)
+	}
+}
+
+func TestMemoryKeys(t *testing.T) {
+	a := test.NewApp()
+	c := New()
+	c.Memory = memory.Load(a.Preferences(), c.Env)
+	c.Content()
+	c.MemoryKey("M+")
+	if c.result.Text != "error: there is no result to store" {
+		t.Errorf("Unexpected result %q", c.result.Text)
+	}
+	for _, step := range []string{"6", "M+", "M+", "4", "M-", "MR"} {
+		if strings.HasPrefix(step, "M") {
+			c.MemoryKey(step)
+			continue
+		}
+		c.input.SetText(step)
+		c.Evaluate()
+	}
+	if c.input.Text != "4M" || c.registers.Text != "M = 8" {
+		t.Fatalf("Unexpected input %q and registers %q", c.input.Text, c.registers.Text)
+	}
+	c.input.SetText("M * 2")
+	c.Evaluate()
+	c.register.SetText("M1")
+	c.MemoryKey("MS")
+	if c.registers.Text != "M = 8   M1 = 16" {
+		t.Errorf("Unexpected registers %q", c.registers.Text)
+	}
+	c.register.SetText("")
+	c.MemoryKey("MC")
+	if c.registers.Text != "M1 = 16" || a.Preferences().String("memory.registers") != "M1" {
+		t.Errorf("Unexpected registers %q", c.registers.Text)
+	}
+}
+
+func TestRPN(t *testing.T) {
+	test.NewApp()
+	c := New()
+	c.Memory = memory.Load(nil, c.Env)
+	c.Content()
+	c.mode.SetChecked(true)
+	for _, key := range []string{"3", "", "4", "+", "swap"} {
+		c.input.SetText(key)
+		c.Evaluate()
+	}
+	if c.stack.Text != "2: 7\n1: 3" {
+		t.Fatalf("Unexpected stack %q", c.stack.Text)
+	}
+	c.input.SetText("2")
+	c.RPNKey("×")
+	c.RPNKey("-")
+	c.MemoryKey("MS")
+	if c.stack.Text != "1: 1" || c.input.Text != "" || c.registers.Text != "M = 1" {
+		t.Errorf("Unexpected stack %q, input %q and registers %q", c.stack.Text, c.input.Text, c.registers.Text)
+	}
+	c.RPNKey("drop drop")
+	if c.result.Text != "error: not enough values on the stack: 1 needed, there are 0" || c.stack.Text != "1: 1" {
+		t.Errorf("Unexpected result %q and stack %q", c.result.Text, c.stack.Text)
+	}
+	c.RPNKey("undo")
+	c.mode.SetChecked(false)
+	c.input.SetText("M + 1")
+	c.Evaluate()
+	if c.stack.Text != "2: 7\n1: 6" || c.result.Text != "2" {
+		t.Errorf("Unexpected stack %q and result %q", c.stack.Text, c.result.Text)
+	}
+}
+
+func TestPlot(t *testing.T) {
+	test.NewApp()
+	c := New()
+	c.Content()
+	c.input.SetText("f(x) = 2x")
+	c.Evaluate()
+	c.functions.SetText("sin(x) / x\n\nf(x) / 10")
+	c.Plot()
+	if c.plotError.Text != "" || len(c.plot.Curves) != 2 {
+		t.Fatalf("Unexpected error %q", c.plotError.Text)
+	}
+	if v := c.plot.View; v.XMin != -10 || v.XMax != 10 || v.YMin > -2 || v.YMax < 2 {
+		t.Errorf("Unexpected view %v", v)
+	}
+	c.ZoomPlot(2)
+	c.PanPlot(0.5, 0)
+	if v := c.plot.View; v.XMin != 0 || v.XMax != 10 {
+		t.Errorf("Unexpected view %v", v)
+	}
+
+	var svg bytes.Buffer
+	if err := c.ExportPlot(&svg, ".SVG"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(svg.String(), ">sin(x) / x</text>") || !strings.Contains(svg.String(), ">f(x) / 10</text>") {
+		t.Errorf("Unexpected SVG %s", svg.String())
+	}
+	var img bytes.Buffer
+	if err := c.ExportPlot(&img, ".png"); err != nil || !bytes.HasPrefix(img.Bytes(), []byte("\x89PNG")) {
+		t.Errorf("Unexpected PNG: %v", err)
+	}
+
+	c.functions.SetText("y")
+	c.Plot()
+	if !strings.HasPrefix(c.plotError.Text, "error: expression contains invalid token") || len(c.plot.Curves) != 2 {
+		t.Errorf("Unexpected error %q", c.plotError.Text)
+	}
+}
+
+func TestTable(t *testing.T) {
+	test.NewApp()
+	c := New()
+	c.Window = test.NewWindow(nil)
+	c.Content()
+	c.CopyTable()
+	if c.tableError.Text != "error: there is no table to copy" {
+		t.Errorf("Unexpected error %q", c.tableError.Text)
+	}
+	test.Type(c.tableSpec, "x^2 for x from 0 to 1 step 0.5")
+	c.tableSpec.OnSubmitted(c.tableSpec.Text)
+	if c.tableError.Text != "" || len(c.tableData.Rows) != 3 {
+		t.Fatalf("Unexpected error %q", c.tableError.Text)
+	}
+	c.tableFormat.SetSelected("CSV")
+	c.CopyTable()
+	if got := c.Window.Clipboard().Content(); got != "x,x^2\n0,0\n0.5,0.25\n1,1\n" {
+		t.Errorf("Unexpected clipboard %q", got)
+	}
+	c.tableSpec.SetText("x^2 for x from 0")
+	c.Tabulate()
+	if !strings.HasPrefix(c.tableError.Text, "error: invalid table") || len(c.tableData.Rows) != 3 {
+		t.Errorf("Unexpected error %q", c.tableError.Text)
+	}
+}
@@ -0,0 +1,71 @@
+package gui
+
+import (
+	"errors"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"github.com/niklasstich/calculator/memory"
+	"strings"
+)
+
+var errNoResult = errors.New("there is no result to store")
+
+// memoryKeys returns the memory keys, they use the register named in the entry next to them and the last result
+func (c *Calculator) memoryKeys() fyne.CanvasObject {
+	c.refreshMemory()
+	keys := container.NewHBox(
+		widget.NewButton("MC", func() { c.MemoryKey("MC") }),
+		widget.NewButton("MR", func() { c.MemoryKey("MR") }),
+		widget.NewButton("M+", func() { c.MemoryKey("M+") }),
+		widget.NewButton("M-", func() { c.MemoryKey("M-") }),
+		widget.NewButton("MS", func() { c.MemoryKey("MS") }),
+	)
+	return container.NewVBox(container.NewBorder(nil, nil, nil, keys, c.register), c.registers)
+}
+
+// MemoryKey presses one of the memory keys MC, MR, M+, M- and MS. MR adds the name of the register to the input, so
+// its value can be used in the expression.
+func (c *Calculator) MemoryKey(key string) {
+	name := strings.TrimSpace(c.register.Text)
+	if name == "" {
+		name = memory.Default
+	}
+	var err error
+	switch key {
+	case "MC":
+		c.Memory.Clear(name)
+	case "MR":
+		if _, err = c.Memory.Recall(name); err == nil {
+			c.input.SetText(c.input.Text + name)
+		}
+	case "M+", "M-", "MS":
+		if c.last == nil {
+			err = errNoResult
+			break
+		}
+		switch key {
+		case "M+":
+			err = c.Memory.Add(name, *c.last)
+		case "M-":
+			err = c.Memory.Subtract(name, *c.last)
+		default:
+			err = c.Memory.Store(name, *c.last)
+		}
+	}
+	if err != nil {
+		c.result.SetText("error: " + err.Error())
+	}
+	c.refreshMemory()
+}
+
+// refreshMemory shows the values of all registers that aren't empty
+func (c *Calculator) refreshMemory() {
+	names := c.Memory.Names()
+	values := make([]string, len(names))
+	for i, name := range names {
+		v, _ := c.Memory.Recall(name)
+		values[i] = name + " = " + c.Formatter.Format(&v)
+	}
+	c.registers.SetText(strings.Join(values, "   "))
+}
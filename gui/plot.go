@@ -0,0 +1,193 @@
+package gui
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/niklasstich/calculator/plot"
+	"image"
+	"image/color"
+	"io"
+	"strings"
+)
+
+// zoomStep is the factor a zoom key or a step of the mouse wheel zooms by, panStep the fraction a pan key moves by
+const (
+	zoomStep = 1.25
+	panStep  = 0.1
+)
+
+// plotPanel returns the graph with the functions it shows and its keys
+func (c *Calculator) plotPanel() fyne.CanvasObject {
+	keys := container.NewHBox(
+		widget.NewButton("←", func() { c.PanPlot(-panStep, 0) }),
+		widget.NewButton("→", func() { c.PanPlot(panStep, 0) }),
+		widget.NewButton("↑", func() { c.PanPlot(0, panStep) }),
+		widget.NewButton("↓", func() { c.PanPlot(0, -panStep) }),
+		widget.NewButton("+", func() { c.ZoomPlot(zoomStep) }),
+		widget.NewButton("−", func() { c.ZoomPlot(1 / zoomStep) }),
+		widget.NewButton("Fit", c.FitPlot),
+	)
+	if c.Window != nil {
+		keys.Add(widget.NewButton("PNG", func() { c.exportDialog(".png") }))
+		keys.Add(widget.NewButton("SVG", func() { c.exportDialog(".svg") }))
+	}
+	return container.NewVBox(
+		container.NewBorder(nil, nil, nil, widget.NewButton("Plot", c.Plot), c.functions),
+		container.NewGridWrap(fyne.NewSize(400, 300), c.graph),
+		keys,
+		c.plotError,
+	)
+}
+
+// Plot draws the functions of x entered in the plot panel, one per line, and fits the view to them
+func (c *Calculator) Plot() {
+	var curves []*plot.Curve
+	p := c.Parser
+	p.Scope = c.Env
+	for _, line := range strings.Split(c.functions.Text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		curve, err := plot.Compile(p, line)
+		if err != nil {
+			c.plotError.SetText("error: " + err.Error())
+			return
+		}
+		curves = append(curves, curve)
+	}
+	c.plot.Curves = curves
+	c.FitPlot()
+}
+
+// FitPlot fits the range of y to the functions, the range of x stays as it is
+func (c *Calculator) FitPlot() {
+	c.plot.Evaluator = c.Evaluator
+	c.plot.Evaluator.Env = c.Env
+	c.plotError.SetText("")
+	if len(c.plot.Curves) > 0 {
+		if err := c.plot.Fit(); err != nil {
+			c.plotError.SetText("error: " + err.Error())
+		}
+	}
+	c.graph.Refresh()
+}
+
+// PanPlot moves the view of the plot by fractions of its width and height
+func (c *Calculator) PanPlot(dx, dy float64) {
+	c.plot.View = c.plotView().Pan(dx, dy)
+	c.graph.Refresh()
+}
+
+// ZoomPlot zooms the plot in around its center, a factor smaller than 1 zooms out
+func (c *Calculator) ZoomPlot(factor float64) {
+	c.plot.View = c.plotView().Zoom(factor)
+	c.graph.Refresh()
+}
+
+// ExportPlot writes the plot with its default size to w, as SVG if the extension is ".svg" and as PNG otherwise
+func (c *Calculator) ExportPlot(w io.Writer, extension string) error {
+	p := *c.plot
+	p.Width, p.Height = 0, 0
+	if strings.EqualFold(extension, ".svg") {
+		return p.SVG(w)
+	}
+	return p.PNG(w)
+}
+
+// exportDialog asks for a file and exports the plot to it
+func (c *Calculator) exportDialog(extension string) {
+	d := dialog.NewFileSave(func(w fyne.URIWriteCloser, err error) {
+		if err != nil || w == nil {
+			return
+		}
+		defer w.Close()
+		if err := c.ExportPlot(w, w.URI().Extension()); err != nil {
+			dialog.ShowError(err, c.Window)
+		}
+	}, c.Window)
+	d.SetFileName("plot" + extension)
+	d.Show()
+}
+
+func (c *Calculator) plotView() plot.View {
+	if c.plot.View == (plot.View{}) {
+		return plot.DefaultView
+	}
+	return c.plot.View
+}
+
+// graph shows the plot of a Calculator, it can be dragged to pan and scrolled to zoom
+type graph struct {
+	widget.BaseWidget
+	c      *Calculator
+	raster *canvas.Raster
+}
+
+func newGraph(c *Calculator) *graph {
+	g := &graph{c: c}
+	g.raster = canvas.NewRaster(g.draw)
+	g.ExtendBaseWidget(g)
+	return g
+}
+
+func (g *graph) CreateRenderer() fyne.WidgetRenderer {
+	return &graphRenderer{g}
+}
+
+// draw draws the plot in the size of the raster, which is in pixels
+func (g *graph) draw(w, h int) image.Image {
+	p := *g.c.plot
+	p.Width, p.Height = w, h
+	img, err := p.Image()
+	if err != nil {
+		//the raster is too small for the labels while the window is laid out
+		return image.NewUniform(color.White)
+	}
+	return img
+}
+
+func (g *graph) Dragged(e *fyne.DragEvent) {
+	size := g.Size()
+	if size.Width <= 0 || size.Height <= 0 {
+		return
+	}
+	//the plot follows the mouse, so it moves the other way than the view
+	g.c.PanPlot(-float64(e.Dragged.DX/size.Width), float64(e.Dragged.DY/size.Height))
+}
+
+func (g *graph) DragEnd() {
+}
+
+func (g *graph) Scrolled(e *fyne.ScrollEvent) {
+	if e.Scrolled.DY > 0 {
+		g.c.ZoomPlot(zoomStep)
+	} else if e.Scrolled.DY < 0 {
+		g.c.ZoomPlot(1 / zoomStep)
+	}
+}
+
+type graphRenderer struct {
+	g *graph
+}
+
+func (r *graphRenderer) Destroy() {
+}
+
+func (r *graphRenderer) Layout(size fyne.Size) {
+	r.g.raster.Resize(size)
+}
+
+func (r *graphRenderer) MinSize() fyne.Size {
+	return fyne.NewSize(100, 100)
+}
+
+func (r *graphRenderer) Objects() []fyne.CanvasObject {
+	return []fyne.CanvasObject{r.g.raster}
+}
+
+func (r *graphRenderer) Refresh() {
+	r.g.raster.Refresh()
+}
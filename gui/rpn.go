@@ -0,0 +1,57 @@
+package gui
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"strings"
+)
+
+// rpnKeys returns the stack and the keys of the RPN mode, they are hidden until the mode is switched on
+func (c *Calculator) rpnKeys() fyne.CanvasObject {
+	keys := container.NewHBox()
+	for _, word := range []string{"+", "-", "×", "÷", "^", "swap", "drop", "dup", "roll", "undo"} {
+		word := word
+		keys.Add(widget.NewButton(word, func() { c.RPNKey(word) }))
+	}
+	c.refreshStack()
+	panel := container.NewVBox(c.stack, keys)
+	c.mode.OnChanged = func(on bool) {
+		c.rpn = on
+		if on {
+			panel.Show()
+		} else {
+			panel.Hide()
+		}
+	}
+	c.mode.OnChanged(c.mode.Checked)
+	return panel
+}
+
+// RPNKey presses a key of the RPN mode, the input is pushed onto the stack before the key is applied like on a HP
+// calculator
+func (c *Calculator) RPNKey(word string) {
+	c.enter(strings.TrimSpace(c.input.Text + " " + word))
+}
+
+// enter passes the line to the Stack and clears the input if it succeeds, the value on top is the result used by the
+// memory keys
+func (c *Calculator) enter(line string) {
+	c.Stack.Parser = c.Parser
+	c.Stack.Evaluator = c.Evaluator
+	c.Stack.Evaluator.Env = c.Env
+	if err := c.Stack.Enter(line); err != nil {
+		c.result.SetText("error: " + err.Error())
+		return
+	}
+	c.input.SetText("")
+	c.result.SetText("")
+	if top, ok := c.Stack.Top(); ok {
+		c.last = &top
+	}
+	c.refreshStack()
+}
+
+func (c *Calculator) refreshStack() {
+	c.stack.SetText(c.Stack.Format(c.Formatter))
+}
@@ -0,0 +1,98 @@
+package gui
+
+import (
+	"errors"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"github.com/niklasstich/calculator/table"
+	"strings"
+)
+
+var errNoTable = errors.New("there is no table to copy")
+
+// tableFormats are the formats the table can be copied in, in the order they are offered
+var tableFormats = []string{"Markdown", "CSV", "JSON"}
+
+// tablePanel returns the table view with the input of its formula and ranges
+func (c *Calculator) tablePanel() fyne.CanvasObject {
+	keys := container.NewHBox(widget.NewButton("Table", c.Tabulate))
+	if c.Window != nil {
+		keys.Add(c.tableFormat)
+		keys.Add(widget.NewButton("Copy", c.CopyTable))
+	}
+	return container.NewVBox(
+		container.NewBorder(nil, nil, nil, keys, c.tableSpec),
+		container.NewGridWrap(fyne.NewSize(400, 200), c.tableView),
+		c.tableError,
+	)
+}
+
+// newTableView returns the widget showing the generated table, its first row is the header
+func (c *Calculator) newTableView() *widget.Table {
+	return widget.NewTable(
+		func() (int, int) {
+			if c.tableData == nil {
+				return 0, 0
+			}
+			return len(c.tableData.Rows) + 1, len(c.tableData.Variables) + 1
+		},
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.TableCellID, o fyne.CanvasObject) {
+			var cells []string
+			if id.Row == 0 {
+				cells = c.tableData.Header()
+			} else {
+				cells = c.tableData.Cells(c.tableData.Rows[id.Row-1], c.Formatter)
+			}
+			o.(*widget.Label).SetText(cells[id.Col])
+		},
+	)
+}
+
+// Tabulate generates the table written in the table panel, like "x^2 for x from 0 to 10 step 0.5"
+func (c *Calculator) Tabulate() {
+	g := table.Generator{Parser: c.Parser, Evaluator: c.Evaluator}
+	g.Parser.Scope = c.Env
+	g.Evaluator.Env = c.Env
+	t, err := g.Run(c.tableSpec.Text)
+	if err != nil {
+		c.tableError.SetText("error: " + err.Error())
+		return
+	}
+	c.tableError.SetText("")
+	c.tableData = t
+	//the formula needs a wider column than the variables
+	for col := range t.Variables {
+		c.tableView.SetColumnWidth(col, 80)
+	}
+	c.tableView.SetColumnWidth(len(t.Variables), 200)
+	c.tableView.Refresh()
+}
+
+// TableText returns the generated table in the format
+func (c *Calculator) TableText(output table.Format) (string, error) {
+	if c.tableData == nil {
+		return "", errNoTable
+	}
+	var b strings.Builder
+	if err := c.tableData.Write(&b, output, c.Formatter); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// CopyTable copies the generated table to the clipboard in the chosen format
+func (c *Calculator) CopyTable() {
+	output, err := table.ParseFormat(c.tableFormat.Selected)
+	if err != nil {
+		c.tableError.SetText("error: " + err.Error())
+		return
+	}
+	text, err := c.TableText(output)
+	if err != nil {
+		c.tableError.SetText("error: " + err.Error())
+		return
+	}
+	c.Window.Clipboard().SetContent(text)
+}
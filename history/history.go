@@ -0,0 +1,257 @@
+// Package history records calculations in a file with one JSON object per line, so they survive restarts. Every
+// entry gets a number which never changes, the result of entry 3 can be used as "$3" in expressions and the last
+// result as "ans".
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/niklasstich/calculator/evaluation"
+	"github.com/niklasstich/calculator/util"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrUnknownEntry = errors.New("no such history entry")
+
+// DefaultMaxEntries is the number of entries kept if History.MaxEntries is not set
+const DefaultMaxEntries = 1000
+
+// Answer is the name of the variable which holds the last result
+const Answer = "ans"
+
+// Entry is a single calculation, Result is the exact value as printed by util.Token.String and empty if Error is set
+type Entry struct {
+	Number     int       `json:"number"`
+	Time       time.Time `json:"time"`
+	Expression string    `json:"expression"`
+	Result     string    `json:"result,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// Variable returns the name of the variable which holds the result of the entry
+func (e Entry) Variable() string {
+	return "$" + strconv.Itoa(e.Number)
+}
+
+func (e Entry) String() string {
+	if e.Error != "" {
+		return fmt.Sprintf("%s  %s: error: %s", e.Variable(), e.Expression, e.Error)
+	}
+	return fmt.Sprintf("%s  %s = %s", e.Variable(), e.Expression, e.Result)
+}
+
+// History holds the entries of a history file. The results are stored in the variables of Env, so expressions can
+// refer to them.
+type History struct {
+	// Path is the file the entries are stored in, nothing is stored if it is empty
+	Path string
+	// MaxEntries is the number of entries kept, the oldest entries are removed once there are more
+	MaxEntries int
+	// Private stops writing new entries to the file. They are still kept until the program exits, so "ans" and the
+	// numbered results keep working.
+	Private bool
+	// Env gets a variable for every result, it may be nil
+	Env *evaluation.Environment
+	// Now returns the time of new entries, time.Now if it is not set
+	Now func() time.Time
+
+	entries []Entry
+}
+
+// Open reads the history stored at path, a missing file is an empty history
+func Open(path string, env *evaluation.Environment) (*History, error) {
+	h := &History{Path: path, Env: env}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		h.entries = append(h.entries, e)
+		h.define(e)
+	}
+	return h, scanner.Err()
+}
+
+// DefaultPath returns the file the history is stored in if no other file is chosen
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "calculator", "history.jsonl"), nil
+}
+
+// Add records a calculation, err is the error of its evaluation. The entry is appended to the file unless the
+// History is Private.
+func (h *History) Add(expression string, result *util.Token, err error) (Entry, error) {
+	e := Entry{Number: 1, Time: h.now(), Expression: expression}
+	if len(h.entries) > 0 {
+		e.Number = h.entries[len(h.entries)-1].Number + 1
+	}
+	if err != nil {
+		e.Error = err.Error()
+	} else {
+		e.Result = result.String()
+	}
+	h.entries = append(h.entries, e)
+	h.define(e)
+	if n := len(h.entries) - h.maxEntries(); n > 0 {
+		for _, old := range h.entries[:n] {
+			if h.Env != nil {
+				h.Env.DeleteVariable(old.Variable())
+			}
+		}
+		h.entries = append([]Entry(nil), h.entries[n:]...)
+		if !h.Private {
+			return e, h.rewrite()
+		}
+	}
+	if h.Private || h.Path == "" {
+		return e, nil
+	}
+	return e, h.appendEntry(e)
+}
+
+// Entries returns all entries from the oldest to the newest
+func (h *History) Entries() []Entry {
+	return append([]Entry(nil), h.entries...)
+}
+
+// Entry returns the entry with the given number
+func (h *History) Entry(number int) (Entry, error) {
+	for _, e := range h.entries {
+		if e.Number == number {
+			return e, nil
+		}
+	}
+	return Entry{}, fmt.Errorf("%w: %d", ErrUnknownEntry, number)
+}
+
+// Search returns the entries whose expression or result contains the query, ignoring case
+func (h *History) Search(query string) []Entry {
+	query = strings.ToLower(query)
+	var found []Entry
+	for _, e := range h.entries {
+		if strings.Contains(strings.ToLower(e.Expression), query) || strings.Contains(strings.ToLower(e.Result), query) {
+			found = append(found, e)
+		}
+	}
+	return found
+}
+
+// Clear removes all entries, their variables and the file
+func (h *History) Clear() error {
+	for _, e := range h.entries {
+		if h.Env != nil {
+			h.Env.DeleteVariable(e.Variable())
+		}
+	}
+	if h.Env != nil {
+		h.Env.DeleteVariable(Answer)
+	}
+	h.entries = nil
+	if h.Path == "" {
+		return nil
+	}
+	if err := os.Remove(h.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// define sets the variables of a successful entry
+func (h *History) define(e Entry) {
+	if h.Env == nil || e.Error != "" {
+		return
+	}
+	v, err := evaluation.ParseValue(e.Result)
+	if err != nil {
+		//results like NaN can't be read again, they have no variable
+		return
+	}
+	h.Env.SetVariable(e.Variable(), v)
+	h.Env.SetVariable(Answer, v)
+}
+
+// appendEntry writes a single entry to the end of the file
+func (h *History) appendEntry(e Entry) error {
+	if err := os.MkdirAll(filepath.Dir(h.Path), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(h.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// rewrite replaces the file with the current entries, it is written to a temporary file first so a crash never
+// leaves a truncated history behind
+func (h *History) rewrite() error {
+	if h.Path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(h.Path), 0700); err != nil {
+		return err
+	}
+	tmp := h.Path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, e := range h.entries {
+		if err := enc.Encode(e); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, h.Path)
+}
+
+func (h *History) maxEntries() int {
+	if h.MaxEntries <= 0 {
+		return DefaultMaxEntries
+	}
+	return h.MaxEntries
+}
+
+func (h *History) now() time.Time {
+	if h.Now == nil {
+		return time.Now()
+	}
+	return h.Now()
+}
@@ -0,0 +1,122 @@
+package history
+
+import (
+	"errors"
+	"github.com/niklasstich/calculator/evaluation"
+	"github.com/niklasstich/calculator/parser"
+	"github.com/niklasstich/calculator/script"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// run runs the line with the history recording every expression and returns the result of the last one
+func run(t *testing.T, h *History, line string) string {
+	t.Helper()
+	runner := script.Runner{Env: h.Env, Record: func(res script.Result) {
+		if _, err := h.Add(res.Text, res.Value, res.Err); err != nil {
+			t.Fatalf("Failed to add %s: %v", res.Text, err)
+		}
+	}}
+	value, err := script.Final(runner.Run(line))
+	if err != nil {
+		return "error: " + err.Error()
+	}
+	return value.String()
+}
+
+func TestHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "calculator", "history.jsonl")
+	h, err := Open(path, &evaluation.Environment{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	h.Now = func() time.Time { return time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC) }
+
+	var tests = []struct {
+		line, result string
+	}{
+		{"1 + 2; ans * 2", "6"},
+		{"-3 km", "-3 km"},
+		{"$3 in m", "-3000 m"},
+		{"0x10 + $1", "19"},
+		{"1 < 2", "true"},
+		{"1 / 0", "error: division by 0"},
+		{"ans", "true"},
+		{"$7", "error: expression contains invalid token: $7 at pos 0"},
+	}
+	for _, tt := range tests {
+		if got := run(t, h, tt.line); got != tt.result {
+			t.Errorf("%s: wanted %s, got %s", tt.line, tt.result, got)
+		}
+	}
+
+	//the results are still known after reading the file again
+	h, err = Open(path, &evaluation.Environment{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := run(t, h, "$3 + $4 / 3"); got != "-4 km" {
+		t.Errorf("Wanted -4 km, got %s", got)
+	}
+	e, err := h.Entry(7)
+	if err != nil || e.Error != "division by 0" || !e.Time.Equal(time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected entry %v: %v", e, err)
+	}
+	if _, err := h.Entry(20); !errors.Is(err, ErrUnknownEntry) {
+		t.Errorf("Expected error %v, got %v", ErrUnknownEntry, err)
+	}
+
+	var found []string
+	for _, e := range h.Search("KM") {
+		found = append(found, e.String())
+	}
+	want := "$3  -3 km = -3 km\n$10  $3 + $4 / 3 = -4 km"
+	if strings.Join(found, "\n") != want {
+		t.Errorf("Wanted\n%s\ngot\n%s", want, strings.Join(found, "\n"))
+	}
+
+	if err := h.Clear(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("History file still exists: %v", err)
+	}
+	if _, err := (&parser.Parser{Scope: h.Env}).Tokenize("ans"); !errors.Is(err, parser.ErrInvalidToken) {
+		t.Errorf("Expected error %v, got %v", parser.ErrInvalidToken, err)
+	}
+}
+
+func TestMaxEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	h := &History{Path: path, MaxEntries: 2, Env: &evaluation.Environment{}}
+	for _, line := range []string{"1", "2", "3"} {
+		run(t, h, line)
+	}
+	if got := run(t, h, "$1"); !strings.HasPrefix(got, "error:") {
+		t.Errorf("Removed entry $1 is still known: %s", got)
+	}
+	h, err := Open(path, &evaluation.Environment{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if n := len(h.Entries()); n != 2 {
+		t.Errorf("Wanted 2 entries, got %d", n)
+	}
+	if got := run(t, h, "$3 + ans"); got != "6" {
+		t.Errorf("Wanted 6, got %s", got)
+	}
+}
+
+func TestPrivate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	h := &History{Path: path, Private: true, Env: &evaluation.Environment{}}
+	if got := run(t, h, "2; ans * $1"); got != "4" {
+		t.Errorf("Wanted 4, got %s", got)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Private history was written: %v", err)
+	}
+}
@@ -4,10 +4,9 @@ import (
 	"fmt"
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
-	"fyne.io/fyne/v2/container"
-	"fyne.io/fyne/v2/widget"
-	"github.com/niklasstich/calculator/evaluation"
-	"github.com/niklasstich/calculator/parser"
+	"github.com/niklasstich/calculator/gui"
+	"github.com/niklasstich/calculator/history"
+	"github.com/niklasstich/calculator/memory"
 	"log"
 	"os"
 	"os/signal"
@@ -15,7 +14,7 @@ import (
 )
 
 func main() {
-	a := app.New()
+	a := app.NewWithID("io.github.niklasstich.calculator")
 	w := a.NewWindow("Calculator")
 	go func(a *fyne.App) {
 		sig := make(chan os.Signal, 1)
@@ -26,31 +25,15 @@ func main() {
 		(*a).Quit()
 	}(&a)
 
-	hello := widget.NewLabel("Hello world")
-	w.SetContent(container.NewVBox(
-		hello,
-		widget.NewButton("Click me", func() {
-			hello.SetText("Thanks :)")
-		}),
-	))
-	w.ShowAndRun()
-}
-
-func Evaluate(input string) string {
-	tokens, err := parser.TokenizeString(input)
-	if err != nil {
-		log.Fatalf("Failed to tokenize input: %v\n", err)
-	}
-
-	tokens, err = parser.ReformToRPN(tokens)
-	if err != nil {
-		log.Fatalf("Failed to reform input: %v\n", err)
+	c := gui.New()
+	c.Window = w
+	//the memory registers are kept in the preferences of the app
+	c.Memory = memory.Load(a.Preferences(), c.Env)
+	if path, err := history.DefaultPath(); err != nil {
+		log.Printf("Failed to find the history: %v\n", err)
+	} else if c.History, err = history.Open(path, c.Env); err != nil {
+		log.Printf("Failed to read the history: %v\n", err)
 	}
-
-	result, err := evaluation.EvaluateRPNExpression(tokens)
-	if err != nil {
-		log.Fatalf("Failed to evaluate input: %v\n", err)
-	}
-
-	return result.String()
+	w.SetContent(c.Content())
+	w.ShowAndRun()
 }
@@ -0,0 +1,180 @@
+// Package memory implements the memory keys of a desk calculator. Every register is a variable of the evaluation
+// Environment, so "M * 2" or "M1 * 2" can be typed in expressions, and its value is kept in Preferences so it is still
+// there after a restart.
+package memory
+
+import (
+	"errors"
+	"fmt"
+	"github.com/niklasstich/calculator/evaluation"
+	"github.com/niklasstich/calculator/parser"
+	"github.com/niklasstich/calculator/units"
+	"github.com/niklasstich/calculator/util"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+var ErrInvalidName = errors.New("invalid register name")
+var ErrEmptyRegister = errors.New("register is empty")
+
+// Default is the register used by the memory keys if no other register is chosen
+const Default = "M"
+
+// keys of the Preferences, every register is stored as registerKey followed by its name
+const (
+	namesKey    = "memory.registers"
+	registerKey = "memory.register."
+)
+
+// Preferences stores the registers, fyne.Preferences implements it
+type Preferences interface {
+	String(key string) string
+	SetString(key string, value string)
+	RemoveValue(key string)
+}
+
+// Registers holds named memory registers, which are empty until a value is stored in them
+type Registers struct {
+	// Env gets a variable for every register that isn't empty
+	Env *evaluation.Environment
+	// Evaluator adds and subtracts values for Add and Subtract, its Env isn't used
+	Evaluator evaluation.Evaluator
+	// Preferences stores the registers, they only exist until the program exits if it is nil
+	Preferences Preferences
+
+	values map[string]util.Token
+}
+
+// Load returns the Registers stored in prefs and sets their variables in env
+func Load(prefs Preferences, env *evaluation.Environment) *Registers {
+	r := &Registers{Env: env, Preferences: prefs}
+	if prefs == nil {
+		return r
+	}
+	for _, name := range strings.Fields(prefs.String(namesKey)) {
+		v, err := evaluation.ParseValue(prefs.String(registerKey + name))
+		if err != nil {
+			//a value that can't be read anymore is dropped like an empty register
+			continue
+		}
+		r.set(name, v)
+	}
+	return r
+}
+
+// Store replaces the value of the register, like the MS key
+func (r *Registers) Store(name string, value util.Token) error {
+	if !validName(name) {
+		return fmt.Errorf("%w: %q", ErrInvalidName, name)
+	}
+	r.set(name, value)
+	r.save()
+	return nil
+}
+
+// Add adds value to the register, like the M+ key. An empty register is treated as 0.
+func (r *Registers) Add(name string, value util.Token) error {
+	return r.apply(name, value, &util.Operator{Char: '+', Precedence: 1, LeftAssociative: true, Op: util.OpAddition})
+}
+
+// Subtract subtracts value from the register, like the M- key. An empty register is treated as 0.
+func (r *Registers) Subtract(name string, value util.Token) error {
+	return r.apply(name, value, &util.Operator{Char: '-', Precedence: 1, LeftAssociative: true, Op: util.OpSubtraction})
+}
+
+// Recall returns the value of the register, like the MR key
+func (r *Registers) Recall(name string) (util.Token, error) {
+	v, ok := r.values[name]
+	if !ok {
+		return util.Token{}, fmt.Errorf("%w: %s", ErrEmptyRegister, name)
+	}
+	return v, nil
+}
+
+// Clear empties the register, like the MC key
+func (r *Registers) Clear(name string) {
+	if _, ok := r.values[name]; !ok {
+		return
+	}
+	delete(r.values, name)
+	if r.Env != nil {
+		r.Env.DeleteVariable(name)
+	}
+	if r.Preferences != nil {
+		r.Preferences.RemoveValue(registerKey + name)
+	}
+	r.save()
+}
+
+// Names returns the names of all registers that aren't empty, sorted
+func (r *Registers) Names() []string {
+	names := make([]string, 0, len(r.values))
+	for name := range r.values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// apply replaces the register with the result of the operator applied to its value and value
+func (r *Registers) apply(name string, value util.Token, operator *util.Operator) error {
+	if !validName(name) {
+		return fmt.Errorf("%w: %q", ErrInvalidName, name)
+	}
+	old, ok := r.values[name]
+	if !ok {
+		old = util.Token{TokenType: util.TokenTypeOperand, TokenUnit: value.TokenUnit}
+	}
+	e := r.Evaluator
+	e.Env = nil
+	e.Trace = nil
+	v, err := e.Evaluate(parser.RPNExpression{old, value, {TokenType: util.TokenTypeOperator, TokenOperator: operator}})
+	if err != nil {
+		return err
+	}
+	r.set(name, *v)
+	r.save()
+	return nil
+}
+
+func (r *Registers) set(name string, value util.Token) {
+	if r.values == nil {
+		r.values = make(map[string]util.Token)
+	}
+	r.values[name] = value
+	if r.Env != nil {
+		r.Env.SetVariable(name, value)
+	}
+}
+
+// save writes the names and values of all registers to the Preferences
+func (r *Registers) save() {
+	if r.Preferences == nil {
+		return
+	}
+	names := r.Names()
+	for _, name := range names {
+		r.Preferences.SetString(registerKey+name, r.values[name].String())
+	}
+	r.Preferences.SetString(namesKey, strings.Join(names, " "))
+}
+
+// validName reports whether name can be used in expressions, which means it is a word of letters, digits and '_'
+// starting with a letter that isn't an operator, a constant or a unit like "km"
+func validName(name string) bool {
+	for i, c := range name {
+		if !unicode.IsLetter(c) && c != '_' && (i == 0 || !unicode.IsDigit(c)) {
+			return false
+		}
+	}
+	if name == "" {
+		return false
+	}
+	if _, ok := units.Lookup(name); ok {
+		return false
+	}
+	//words the parser doesn't know are free
+	_, err := parser.TokenizeString(name)
+	return err != nil
+}
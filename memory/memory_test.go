@@ -0,0 +1,107 @@
+package memory
+
+import (
+	"errors"
+	"github.com/niklasstich/calculator/evaluation"
+	"github.com/niklasstich/calculator/script"
+	"github.com/niklasstich/calculator/units"
+	"github.com/niklasstich/calculator/util"
+	"testing"
+)
+
+// testPreferences keeps the preferences in a map
+type testPreferences map[string]string
+
+func (p testPreferences) String(key string) string {
+	return p[key]
+}
+
+func (p testPreferences) SetString(key string, value string) {
+	p[key] = value
+}
+
+func (p testPreferences) RemoveValue(key string) {
+	delete(p, key)
+}
+
+// evaluate runs the expression with the Environment and returns its result as text
+func evaluate(t *testing.T, env *evaluation.Environment, expression string) string {
+	t.Helper()
+	value, err := script.Final((&script.Runner{Env: env}).Run(expression))
+	if err != nil {
+		return "error: " + err.Error()
+	}
+	return value.String()
+}
+
+func TestRegisters(t *testing.T) {
+	prefs := testPreferences{}
+	env := &evaluation.Environment{}
+	r := Load(prefs, env)
+	number := func(f float64) util.Token {
+		return util.Token{TokenType: util.TokenTypeOperand, TokenOperand: f}
+	}
+
+	if err := r.Add(Default, number(5)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := r.Subtract(Default, number(2)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := r.Store("M1", number(10)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := r.Add("M1", number(0.5)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := evaluate(t, env, "M1 * 2 + M"); got != "24" {
+		t.Errorf("Wanted 24, got %s", got)
+	}
+	if v, err := r.Recall(Default); err != nil || v.String() != "3" {
+		t.Errorf("Wanted 3, got %v: %v", v, err)
+	}
+
+	//the registers are read again from the preferences
+	env = &evaluation.Environment{}
+	r = Load(prefs, env)
+	if got := r.Names(); len(got) != 2 || got[0] != "M" || got[1] != "M1" {
+		t.Errorf("Unexpected registers %v", got)
+	}
+	if got := evaluate(t, env, "M1 - M"); got != "7.5" {
+		t.Errorf("Wanted 7.5, got %s", got)
+	}
+
+	r.Clear(Default)
+	if _, err := r.Recall(Default); !errors.Is(err, ErrEmptyRegister) {
+		t.Errorf("Expected error %v, got %v", ErrEmptyRegister, err)
+	}
+	if got := evaluate(t, env, "M"); got == "3" {
+		t.Errorf("Cleared register M is still a variable")
+	}
+	if _, ok := prefs[registerKey+Default]; ok || prefs[namesKey] != "M1" {
+		t.Errorf("Cleared register M is still stored: %v", prefs)
+	}
+}
+
+func TestRegisterUnits(t *testing.T) {
+	r := Load(nil, &evaluation.Environment{})
+	m, _ := units.Lookup("m")
+	if err := r.Add("dist", util.Token{TokenType: util.TokenTypeOperand, TokenOperand: 2, TokenUnit: m}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := r.Add("dist", util.Token{TokenType: util.TokenTypeOperand, TokenOperand: 3}); err == nil {
+		t.Errorf("Added a number to a length")
+	}
+}
+
+func TestValidName(t *testing.T) {
+	for name, valid := range map[string]bool{
+		"M": true, "M1": true, "total_2": true,
+		"": false, "1M": false, "$1": false, "mod": false, "pi": false, "true": false, "M 1": false,
+		"km": false, "m": false, "s": false,
+	} {
+		if validName(name) != valid {
+			t.Errorf("%q: wanted valid %v", name, valid)
+		}
+	}
+}
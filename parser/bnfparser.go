@@ -7,31 +7,103 @@ import (
 )
 
 var ErrUnmatchedParenthesis = errors.New("there were unmatched parenthesis in the expression")
+var ErrMisplacedSeparator = errors.New("argument separator outside of the brackets of a call or list")
+var ErrUnmatchedTernary = errors.New("'?' and ':' of a ternary operator don't match")
+var ErrNestingTooDeep = errors.New("brackets are nested too deep")
 
 type RPNExpression []util.Token
 
+// negation replaces a '-' which appears where an operand is expected. It binds looser than '^', so -2^2 is -4.
+var negation = &util.Operator{
+	Char:            '-',
+	Name:            "neg",
+	Precedence:      3,
+	LeftAssociative: false,
+	Bracket:         false,
+	Unary:           true,
+	Op:              util.OpNegation,
+}
+
+// ternary replaces the '?' on the operator stack once the matching ':' is found, its operands are the condition and
+// both alternatives
+var ternary = &util.Operator{
+	Name:            "?:",
+	Precedence:      -13,
+	LeftAssociative: false,
+	Bracket:         false,
+	Op:              util.OpTernary,
+}
+
 // ReformToRPN uses the Shunting-yard algorithm by Dijkstra to convert a tokenized infix expression to RPN
 func ReformToRPN(expression []util.Token) (rpn RPNExpression, err error) {
+	return (&Parser{}).ReformToRPN(expression)
+}
+
+// ReformToRPN works like the function ReformToRPN, but limits the nesting depth of brackets to MaxDepth
+func (p *Parser) ReformToRPN(expression []util.Token) (rpn RPNExpression, err error) {
 	rpn = make([]util.Token, 0, len(expression))
 	opStack := util.TokenStack{}
-	for _, t := range expression {
+	//expectOperand is true at the start and after binary operators, where a '+' or '-' has to be a sign
+	expectOperand := true
+	//args counts the arguments inside each open bracket, afterOpen is true right after a left bracket
+	args := make([]int, 0, 4)
+	//calls tells for every open bracket whether it belongs to a call or a list, only there arguments are separated
+	calls := make([]bool, 0, 4)
+	afterOpen := false
+	for i, t := range expression {
+		if i > 0 {
+			prev := expression[i-1]
+			afterOpen = prev.TokenType == util.TokenTypeOperator && prev.TokenOperator.Op == util.OpLeftBracket
+		}
 		if t.TokenType == util.TokenTypeOperand {
 			//we can just push all operands straight to the output
 			rpn = append(rpn, t)
+			expectOperand = false
 		} else {
+			if expectOperand && t.TokenOperator.Op == util.OpAddition {
+				//a leading plus doesn't change anything
+				p.trace(&t, rpn, &opStack)
+				continue
+			}
+			if expectOperand && t.TokenOperator.Op == util.OpSubtraction {
+				t.TokenOperator = negation
+			}
 			o2 := opStack.Peek()
-			switch t.TokenOperator.Op {
-			case util.OpLeftBracket:
+			switch {
+			case t.TokenOperator.Op == util.OpLeftBracket:
 				{
 					opStack.Push(t)
+					args = append(args, 1)
+					calls = append(calls, o2 != nil &&
+						(o2.TokenOperator.Op == util.OpCall || o2.TokenOperator.Op == util.OpList))
+					if p.MaxDepth > 0 && len(args) > p.MaxDepth {
+						return nil, fmt.Errorf("%w: at most %d levels are allowed", ErrNestingTooDeep, p.MaxDepth)
+					}
+					expectOperand = true
 				}
-			case util.OpRightBracket:
+			case t.TokenOperator.Op == util.OpRightBracket:
 				{
+					if len(args) == 0 {
+						return nil, fmt.Errorf("%v: Missing left bracket", ErrUnmatchedParenthesis)
+					}
+					n := args[len(args)-1]
+					if afterOpen {
+						n = 0
+					}
+					args = args[:len(args)-1]
+					calls = calls[:len(calls)-1]
+					expectOperand = false
 					for {
 						//if o2 is a left bracket, discard both brackets
 						if o2.TokenOperator.Op == util.OpLeftBracket {
+							if (o2.TokenOperator.Char == '[') != (t.TokenOperator.Char == ']') {
+								return nil, fmt.Errorf("%v: '%c' closed by '%c'", ErrUnmatchedParenthesis,
+									o2.TokenOperator.Char, t.TokenOperator.Char)
+							}
 							opStack.Pop()
 							break
+						} else if o2.TokenOperator.Op == util.OpCondition {
+							return nil, fmt.Errorf("%w: '?' without ':' before ')'", ErrUnmatchedTernary)
 						} else {
 							//otherwise keep popping operators from the stack to the output until we find a left bracket
 							opStack.Pop()
@@ -42,9 +114,59 @@ func ReformToRPN(expression []util.Token) (rpn RPNExpression, err error) {
 							o2 = opStack.Peek()
 						}
 					}
+					//the brackets of a function call or a list belong to it, so the call is complete now
+					if call := opStack.Peek(); call != nil &&
+						(call.TokenOperator.Op == util.OpCall || call.TokenOperator.Op == util.OpList) {
+						opStack.Pop()
+						operator := *call.TokenOperator
+						operator.Arguments = n
+						call.TokenOperator = &operator
+						rpn = append(rpn, *call)
+					}
+				}
+			case t.TokenOperator.Op == util.OpArgumentSeparator:
+				{
+					//plain brackets like "(1, 2)" hold a single expression
+					if len(calls) == 0 || !calls[len(calls)-1] {
+						return nil, fmt.Errorf("%w: '%v'", ErrMisplacedSeparator, t)
+					}
+					//finish the previous argument by popping operators until the enclosing left bracket
+					for o2.TokenOperator.Op != util.OpLeftBracket {
+						if o2.TokenOperator.Op == util.OpCondition {
+							return nil, fmt.Errorf("%w: '?' without ':' before '%v'", ErrUnmatchedTernary, t)
+						}
+						opStack.Pop()
+						rpn = append(rpn, *o2)
+						o2 = opStack.Peek()
+					}
+					args[len(args)-1]++
+					expectOperand = true
+				}
+			case t.TokenOperator.Op == util.OpTernary:
+				{
+					//finish the first alternative by popping operators until the matching '?', which becomes the
+					//ternary operator, so the second alternative is parsed like the right operand of a binary operator
+					for o2 == nil || o2.TokenOperator.Op != util.OpCondition {
+						if o2 == nil || o2.TokenOperator.Bracket {
+							return nil, fmt.Errorf("%w: ':' without '?'", ErrUnmatchedTernary)
+						}
+						opStack.Pop()
+						rpn = append(rpn, *o2)
+						o2 = opStack.Peek()
+					}
+					o2.TokenOperator = ternary
+					expectOperand = true
+				}
+			case t.TokenOperator.Unary, t.TokenOperator.Op == util.OpCall, t.TokenOperator.Op == util.OpList:
+				{
+					//prefix operators can't pop anything, their operand hasn't been seen yet
+					opStack.Push(t)
+					expectOperand = true
 				}
 			default:
 				{
+					//postfix operators are followed by another operator, all others by an operand
+					expectOperand = !isPostfix(t.TokenOperator.Op)
 					//keep popping ops into output while:
 					for o2 != nil && //there are ops on the stack
 						!o2.TokenOperator.Bracket && //and they aren't brackets
@@ -59,6 +181,7 @@ func ReformToRPN(expression []util.Token) (rpn RPNExpression, err error) {
 				}
 			}
 		}
+		p.trace(&t, rpn, &opStack)
 	}
 	//pop remaining operators
 	for opStack.HasElements() {
@@ -67,7 +190,16 @@ func ReformToRPN(expression []util.Token) (rpn RPNExpression, err error) {
 		if op.TokenOperator.Bracket {
 			return nil, fmt.Errorf("%v: Missing right bracket", ErrUnmatchedParenthesis)
 		}
+		if op.TokenOperator.Op == util.OpCondition {
+			return nil, fmt.Errorf("%w: '?' without ':'", ErrUnmatchedTernary)
+		}
 		rpn = append(rpn, *op)
 	}
+	p.trace(nil, rpn, &opStack)
 	return
 }
+
+// isPostfix reports whether the operator follows its only operand
+func isPostfix(op util.Op) bool {
+	return op == util.OpFactorial || op == util.OpPercent
+}
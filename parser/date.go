@@ -0,0 +1,139 @@
+package parser
+
+import (
+	"fmt"
+	"github.com/niklasstich/calculator/units"
+	"github.com/niklasstich/calculator/util"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// clockNames are the current date and the current time, they are operands with a TokenName like variables and get
+// their value from the clock of the evaluator
+var clockNames = map[string]bool{
+	"today": true,
+	"now":   true,
+}
+
+// dateLiteral matches a date like 2026-12-24, optionally followed by the time of day like 15:30 or 15:30:45.5, which
+// is separated by a space or a 'T' like in ISO 8601
+var dateLiteral = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}(?:[T ]\d{2}:\d{2}(?::\d{2}(?:\.\d{1,9})?)?)?`)
+
+// scanDate reads the date literal starting at pos, which may be followed by a time zone like UTC or Europe/Berlin.
+// Dates without a time zone are in time.Local. ok is false if there is no date at pos, so "2026-12" is still a
+// subtraction.
+func scanDate(input string, pos int) (token util.Token, end int, ok bool, err error) {
+	literal := dateLiteral.FindString(input[pos:])
+	end = pos + len(literal)
+	if literal == "" || end < len(input) && isNumerical(int32(input[end])) {
+		return token, pos, false, nil
+	}
+	layout := "2006-01-02"
+	if len(literal) > len(layout) {
+		layout += literal[len(layout):len(layout)+1] + "15:04"
+		if len(literal) > len(layout) {
+			layout += ":05"
+		}
+	}
+	loc := time.Local
+	if zone, next, found := scanZone(input, skipWhitespace(input, end)); found {
+		loc, end = zone, next
+	}
+	//the fraction of a second is accepted by Parse even though the layout has none
+	t, err := time.ParseInLocation(layout, literal, loc)
+	if err != nil {
+		return token, end, true, fmt.Errorf("%w: invalid date %s at pos %d", ErrMalformedNumber, literal, pos)
+	}
+	return util.Token{TokenType: util.TokenTypeOperand, TokenTime: &t}, end, true, nil
+}
+
+// scanZone reads the name of a time zone of the local tzdata starting at pos. Only UTC and names with a '/' like
+// Europe/Berlin or America/Argentina/Buenos_Aires are time zones, so words like "in" are never looked up.
+func scanZone(input string, pos int) (loc *time.Location, end int, ok bool) {
+	end = pos
+	for end < len(input) {
+		c, size := utf8.DecodeRuneInString(input[end:])
+		if !isLetter(c) && !isNumerical(c) && c != '/' && c != '-' && c != '+' {
+			break
+		}
+		end += size
+	}
+	//"UTC-3h" is the time zone followed by a subtraction, so shorter names ending before a sign are tried as well
+	for name := input[pos:end]; name != ""; name = name[:strings.LastIndexAny(name, "+-")+1] {
+		name = strings.TrimRight(name, "+-")
+		if c, _ := utf8.DecodeRuneInString(name); !isLetter(c) || name != "UTC" && !strings.Contains(name, "/") {
+			continue
+		}
+		if loc, err := time.LoadLocation(name); err == nil {
+			return loc, pos + len(name), true
+		}
+	}
+	return nil, pos, false
+}
+
+// scanDuration continues the duration whose first part is token, like "3h 20m" or "1 d 6 h 30 min". It ends at pos.
+// The parts have time units which get smaller from part to part, "m" is a minute in them. The duration is returned in
+// the unit of its last part, ok is false if there is no second part. The parts are read like scanNumber does.
+func (p *Parser) scanDuration(input string, pos int, token util.Token, locale util.Locale,
+	separates bool) (util.Token, int, bool) {
+	first, ok := durationUnit(token.TokenUnit)
+	if !ok {
+		return token, pos, false
+	}
+	seconds, unit := token.TokenOperand*first.Factor, first
+	end := pos
+	for {
+		start := skipWhitespace(input, end)
+		if start >= len(input) || !isNumerical(int32(input[start])) || literalBase(input, start) != 0 {
+			break
+		}
+		part, next, err := scanNumber(input, start, locale, separates)
+		if err != nil {
+			break
+		}
+		word := skipWhitespace(input, next)
+		u, ok := durationUnit(durationWord(input, word))
+		if !ok || u.Factor >= unit.Factor || p.isUserWord(input, word) {
+			break
+		}
+		seconds += part.TokenOperand * u.Factor
+		unit = u
+		end = scanWord(input, word)
+	}
+	if unit == first {
+		return token, pos, false
+	}
+	return util.Token{TokenType: util.TokenTypeOperand, TokenOperand: seconds / unit.Factor, TokenUnit: unit}, end, true
+}
+
+// durationWord returns the unit of the word at pos, "m" is a minute in durations instead of a metre
+func durationWord(input string, pos int) *util.Unit {
+	end := scanWord(input, pos)
+	word := input[pos:end]
+	if end < len(input) && input[end] == '^' {
+		//"20 m^2" is an area
+		return nil
+	}
+	if word == "m" {
+		word = "min"
+	}
+	if word == "" || wordLookUp[word] != nil {
+		return nil
+	}
+	u, _ := units.Lookup(word)
+	return u
+}
+
+// durationUnit returns the unit of a part of a duration, which is a single unit of time. The metre of the first part
+// is a minute as well, so "5m 30s" is a duration.
+func durationUnit(u *util.Unit) (*util.Unit, bool) {
+	if u == nil || len(u.Terms) != 1 || u.Terms[0].Power != 1 {
+		return nil, false
+	}
+	if u.Terms[0].Symbol == "m" {
+		u, _ = units.Lookup("min")
+	}
+	return u, u.Dimension == util.TimeDimension
+}
@@ -0,0 +1,62 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestDates(t *testing.T) {
+	if _, err := time.LoadLocation("America/New_York"); err != nil {
+		t.Skipf("no tzdata: %v", err)
+	}
+	var tests = []struct {
+		input, want string
+		err         error
+	}{
+		{"2026-12-24 - today in days", "[2026-12-24 today - 1 d in]", nil},
+		{"2026-12-24T15:30 UTC + 2 h", "[2026-12-24 15:30 UTC 2 h +]", nil},
+		{"2026-12-24 08:00:30.25 Europe/Berlin", "[2026-12-24 08:00:30.25 Europe/Berlin]", nil},
+		{"now in America/New_York", "[now America/New_York in]", nil},
+		{"now in UTC-3h", "[now UTC 3 h - in]", nil},
+		{"2026-12 - 1", "[2026 12 - 1 -]", nil},
+		{"3h 20m * 4", "[200 min 4 *]", nil},
+		{"1 d 6 h 30 min", "[1830 min]", nil},
+		{"5m 30s", "[330 s]", nil},
+		{"20 m", "[20 m]", nil},
+		{"2 m * 3 s", "[2 m 3 s *]", nil},
+		{"2 h 3 d +", "[2 h 3 d +]", nil},
+		{"2 h 3 m^2 +", "[2 h 3 m^2 +]", nil},
+		{"2026-02-30", "", ErrMalformedNumber},
+		{"2026-12-24 Europe/Nowhere", "", ErrInvalidToken},
+	}
+	for _, tt := range tests {
+		tokens, err := TokenizeString(tt.input)
+		if !errors.Is(err, tt.err) {
+			t.Fatalf("%s: expected error %v, got %v", tt.input, tt.err, err)
+		}
+		if err != nil {
+			continue
+		}
+		rpn, err := ReformToRPN(tokens)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", tt.input, err)
+		}
+		if got := fmt.Sprint(rpn); got != tt.want {
+			t.Errorf("%s: wanted %s, got %s", tt.input, tt.want, got)
+		}
+		//dates are printed so they can be read again
+		infix, err := Printer{}.ReformToInfix(rpn)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", tt.input, err)
+		}
+		tokens, err = TokenizeString(infix)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", infix, err)
+		}
+		if again, _ := ReformToRPN(tokens); fmt.Sprint(again) != tt.want {
+			t.Errorf("%s: printed as %s, which is %v", tt.input, infix, again)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+var ErrInvalidDefinition = errors.New("invalid function definition")
+
+// Definition is a user defined function like "f(x, y) = x^2 + y^2". Body is in RPN, its parameters are operands
+// with a TokenName.
+type Definition struct {
+	Name   string
+	Params []string
+	Body   RPNExpression
+	// Source is the text the function was defined with
+	Source string
+}
+
+// definitionScope makes the function being defined known in its own body, so it can call itself
+type definitionScope struct {
+	outer Scope
+	name  string
+}
+
+func (s definitionScope) IsFunction(name string) bool {
+	return name == s.name || s.outer != nil && s.outer.IsFunction(name)
+}
+
+// ParseDefinition parses input if it is a function definition, which is recognized by a '=' that is not part of a
+// comparison like "==" or "<=". ok is false if input is not a definition and should be parsed as an expression.
+func (p *Parser) ParseDefinition(input string) (def *Definition, ok bool, err error) {
+	eq := definitionSign(input)
+	if eq < 0 {
+		return nil, false, nil
+	}
+	if p.MaxInputLength > 0 && len(input) > p.MaxInputLength {
+		return nil, true, fmt.Errorf("%w: %d bytes, at most %d are allowed", ErrInputTooLong, len(input), p.MaxInputLength)
+	}
+	locale, err := p.locale()
+	if err != nil {
+		return nil, true, err
+	}
+	def = &Definition{Source: strings.TrimSpace(input)}
+
+	//the head is the name followed by the parameters in brackets
+	head := input[:eq]
+	pos := skipWhitespace(head, 0)
+	if c, _ := utf8.DecodeRuneInString(head[pos:]); pos >= len(head) || !isLetter(c) {
+		return nil, true, fmt.Errorf("%w: expected a function name at pos %d", ErrInvalidDefinition, pos)
+	}
+	end := scanWord(head, pos)
+	def.Name = head[pos:end]
+	if !isDefinable(def.Name) {
+		return nil, true, fmt.Errorf("%w: %s is reserved", ErrInvalidDefinition, def.Name)
+	}
+	pos = skipWhitespace(head, end)
+	if pos >= len(head) || head[pos] != '(' {
+		return nil, true, fmt.Errorf("%w: expected '(' after %s at pos %d", ErrInvalidDefinition, def.Name, pos)
+	}
+	pos = skipWhitespace(head, pos+1)
+	for pos < len(head) && head[pos] != ')' {
+		if c, _ := utf8.DecodeRuneInString(head[pos:]); !isLetter(c) {
+			return nil, true, fmt.Errorf("%w: expected a parameter name at pos %d", ErrInvalidDefinition, pos)
+		}
+		end = scanWord(head, pos)
+		param := head[pos:end]
+		if !isDefinable(param) {
+			return nil, true, fmt.Errorf("%w: %s is reserved", ErrInvalidDefinition, param)
+		}
+		for _, other := range def.Params {
+			if other == param {
+				return nil, true, fmt.Errorf("%w: duplicate parameter %s", ErrInvalidDefinition, param)
+			}
+		}
+		def.Params = append(def.Params, param)
+		pos = skipWhitespace(head, end)
+		if c, size := utf8.DecodeRuneInString(head[pos:]); c == locale.ArgumentSeparator {
+			pos = skipWhitespace(head, pos+size)
+			if pos < len(head) && head[pos] == ')' {
+				return nil, true, fmt.Errorf("%w: expected a parameter name at pos %d", ErrInvalidDefinition, pos)
+			}
+		} else if c != ')' {
+			return nil, true, fmt.Errorf("%w: expected '%c' or ')' at pos %d", ErrInvalidDefinition,
+				locale.ArgumentSeparator, pos)
+		}
+	}
+	if pos >= len(head) {
+		return nil, true, fmt.Errorf("%w: missing ')'", ErrInvalidDefinition)
+	}
+	if rest := strings.TrimSpace(head[pos+1:]); rest != "" {
+		return nil, true, fmt.Errorf("%w: unexpected %s before '='", ErrInvalidDefinition, rest)
+	}
+
+	def.Body, err = p.parseBody(def.Name, def.Params, input[eq+1:])
+	if err != nil {
+		return nil, true, err
+	}
+	return def, true, nil
+}
+
+// ParseFunction parses body as the body of a function with the given parameters, so an expression like "sin(x)/x"
+// can be evaluated for many values of x without defining a function in the Scope
+func (p *Parser) ParseFunction(name string, params []string, body string) (*Definition, error) {
+	if p.MaxInputLength > 0 && len(body) > p.MaxInputLength {
+		return nil, fmt.Errorf("%w: %d bytes, at most %d are allowed", ErrInputTooLong, len(body), p.MaxInputLength)
+	}
+	for _, param := range params {
+		if !isDefinable(param) {
+			return nil, fmt.Errorf("%w: %s is reserved", ErrInvalidDefinition, param)
+		}
+	}
+	rpn, err := p.parseBody(name, params, body)
+	if err != nil {
+		return nil, err
+	}
+	return &Definition{Name: name, Params: params, Body: rpn, Source: strings.TrimSpace(body)}, nil
+}
+
+// parseBody tokenizes the body of a function with the parameters as variables
+func (p *Parser) parseBody(name string, params []string, body string) (RPNExpression, error) {
+	if strings.TrimSpace(body) == "" {
+		return nil, fmt.Errorf("%w: missing body", ErrInvalidDefinition)
+	}
+	bodyParser := *p
+	bodyParser.params = params
+	bodyParser.Scope = definitionScope{outer: p.Scope, name: name}
+	tokens, err := bodyParser.Tokenize(body)
+	if err != nil {
+		return nil, err
+	}
+	return bodyParser.ReformToRPN(tokens)
+}
+
+// definitionSign returns the position of the first '=' which is not part of a comparison, or -1
+func definitionSign(input string) int {
+	for i := 0; i < len(input); i++ {
+		if input[i] != '=' {
+			continue
+		}
+		if i+1 < len(input) && input[i+1] == '=' {
+			//skip the second character of "=="
+			i++
+			continue
+		}
+		if i > 0 && strings.IndexByte("<>!", input[i-1]) >= 0 {
+			continue
+		}
+		return i
+	}
+	return -1
+}
+
+// isDefinable reports whether name can be used for a function or parameter, operator keywords can't be redefined
+func isDefinable(name string) bool {
+	_, boolean := booleans[name]
+	return wordLookUp[name] == nil && !boolean
+}
@@ -0,0 +1,121 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// testScope knows the functions in the map
+type testScope map[string]bool
+
+func (s testScope) IsFunction(name string) bool {
+	return s[name]
+}
+
+func TestParseDefinition(t *testing.T) {
+	var tests = []struct {
+		input  string
+		ok     bool
+		name   string
+		params string
+		body   string
+		err    error
+	}{
+		{"f(x, y) = x^2 + y^2", true, "f", "[x y]", "[x 2 ^ y 2 ^ +]", nil},
+		{" g ( t ) = 2t ", true, "g", "[t]", "[2 t *]", nil},
+		{"fact(n) = n <= 1 ? 1 : n * fact(n - 1)", true, "fact", "[n]",
+			"[n 1 <= 1 n n 1 - fact * ?:]", nil},
+		{"h() = sq(2)", true, "h", "[]", "[2 sq]", nil},
+		{"1 == 1", false, "", "", "", nil},
+		{"1 <= 2 != 3 >= 4", false, "", "", "", nil},
+		{"f(x) =", true, "", "", "", ErrInvalidDefinition},
+		{"f x = x", true, "", "", "", ErrInvalidDefinition},
+		{"f(x, x) = x", true, "", "", "", ErrInvalidDefinition},
+		{"f(x,) = x", true, "", "", "", ErrInvalidDefinition},
+		{"f(x) y = x", true, "", "", "", ErrInvalidDefinition},
+		{"mod(x) = x", true, "", "", "", ErrInvalidDefinition},
+		{"f(true) = 1", true, "", "", "", ErrInvalidDefinition},
+		{"2 = 3", true, "", "", "", ErrInvalidDefinition},
+		{"f(x) = y", true, "", "", "", ErrInvalidToken},
+	}
+
+	for i, tt := range tests {
+		testname := fmt.Sprintf("%d:\"%s\"", i+1, tt.input)
+		t.Run(testname, func(t *testing.T) {
+			p := Parser{Scope: testScope{"sq": true}}
+			def, ok, err := p.ParseDefinition(tt.input)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Expected error %v, got %v", tt.err, err)
+			}
+			if ok != tt.ok {
+				t.Errorf("Expected ok to be %v", tt.ok)
+			}
+			if def == nil {
+				return
+			}
+			if def.Name != tt.name {
+				t.Errorf("Wanted name %s, got %s", tt.name, def.Name)
+			}
+			if got := fmt.Sprint(def.Params); got != tt.params {
+				t.Errorf("Wanted parameters %s, got %s", tt.params, got)
+			}
+			if got := fmt.Sprint(def.Body); got != tt.body {
+				t.Errorf("Wanted body %s, got %s", tt.body, got)
+			}
+		})
+	}
+}
+
+func TestFunctionCall(t *testing.T) {
+	var tests = []struct {
+		input, want string
+		err         error
+	}{
+		{"f(1, 2)", "[1 2 f]", nil},
+		{"2f(3) + g()", "[2 3 f * g +]", nil},
+		{"f(f(1, 2), (3 + 4))", "[1 2 f 3 4 + f]", nil},
+		{"g(2)", "[2 g]", nil},
+		{"3 g", "[3 g]", nil},
+		{"g", "[1 g]", nil},
+		{"f + 1", "", ErrInvalidToken},
+	}
+
+	for i, tt := range tests {
+		testname := fmt.Sprintf("%d:\"%s\"", i+1, tt.input)
+		t.Run(testname, func(t *testing.T) {
+			p := Parser{Scope: testScope{"f": true, "g": true}}
+			tokens, err := p.Tokenize(tt.input)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Expected error %v, got %v", tt.err, err)
+			}
+			if err != nil {
+				return
+			}
+			rpn, err := ReformToRPN(tokens)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if fmt.Sprint(rpn) != tt.want {
+				t.Errorf("Wanted %s, got %v", tt.want, rpn)
+			}
+		})
+	}
+}
+
+func TestParseFunction(t *testing.T) {
+	p := Parser{Scope: testScope{"sq": true}}
+	def, err := p.ParseFunction("f", []string{"x"}, " sq(x) / x ")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := fmt.Sprint(def.Body); got != "[x sq x /]" || def.Source != "sq(x) / x" {
+		t.Errorf("Unexpected function %s: %s", def.Source, got)
+	}
+	if _, err := p.ParseFunction("f", []string{"x"}, "x + y"); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Expected error %v, got %v", ErrInvalidToken, err)
+	}
+	if _, err := p.ParseFunction("f", []string{"mod"}, "1"); !errors.Is(err, ErrInvalidDefinition) {
+		t.Errorf("Expected error %v, got %v", ErrInvalidDefinition, err)
+	}
+}
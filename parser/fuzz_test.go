@@ -0,0 +1,105 @@
+package parser
+
+import (
+	"errors"
+	"github.com/niklasstich/calculator/util"
+	"reflect"
+	"testing"
+)
+
+// seeds are the starting points for the fuzz targets, further inputs are in testdata/fuzz
+var seeds = []string{
+	"2+4",
+	"(1 + 2) * 3",
+	"-2^-2",
+	"3!%",
+	"5 km + 300 m",
+	"2pi(1)",
+	"0x1F & 0b101 | ~0o7",
+	"1.5e-3 mod 2",
+	"17 // 5 rem 3",
+	"1 < 2 && not (3 >= 4) || 5 == 5",
+	"1 > 2 ? 3 : 4 ? 5 : 6",
+	"10 % + 5",
+	"5 GB in MB",
+	"f(1, 2)",
+	"((",
+	")(",
+	"1 ? : 2",
+	",",
+	"",
+}
+
+// FuzzTokenizeString makes sure the tokenizer never panics and that every token it returns is complete
+func FuzzTokenizeString(f *testing.F) {
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, input string) {
+		tokens, err := TokenizeString(input)
+		if err != nil {
+			return
+		}
+		for i, token := range tokens {
+			if token.TokenType == util.TokenTypeOperator && token.TokenOperator == nil {
+				t.Errorf("token %d of %q is an operator without Operator", i, input)
+			}
+		}
+	})
+}
+
+// FuzzReformToRPN makes sure the parser never panics on any sequence of tokens the tokenizer accepts, and that the
+// RPN of an expression doesn't change when it is put in brackets or printed by a Printer and read again
+func FuzzReformToRPN(f *testing.F) {
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, input string) {
+		p := Parser{Scope: testScope{"f": true}}
+		tokens, err := p.Tokenize(input)
+		if err != nil {
+			return
+		}
+		rpn, err := p.ReformToRPN(tokens)
+		if err != nil {
+			return
+		}
+		for i, token := range rpn {
+			if token.TokenOperator != nil && token.TokenOperator.Bracket {
+				t.Errorf("token %d of the RPN of %q is a bracket", i, input)
+			}
+		}
+		tokens, err = p.Tokenize("(" + input + ")")
+		if err != nil {
+			t.Fatalf("%q can't be put in brackets: %v", input, err)
+		}
+		bracketed, err := p.ReformToRPN(tokens)
+		if err != nil {
+			t.Fatalf("%q can't be put in brackets: %v", input, err)
+		}
+		if !reflect.DeepEqual(rpn, bracketed) {
+			t.Errorf("RPN of %q is %v, but %v in brackets", input, rpn, bracketed)
+		}
+		for _, printer := range []Printer{{}, {Compact: true}, {Unicode: true}} {
+			infix, err := printer.ReformToInfix(rpn)
+			if errors.Is(err, ErrInvalidRPN) {
+				//ReformToRPN doesn't check the number of operands, like in "1 +"
+				return
+			}
+			if err != nil {
+				t.Fatalf("%q can't be printed: %v", input, err)
+			}
+			tokens, err = p.Tokenize(infix)
+			if err != nil {
+				t.Fatalf("%q printed as %q can't be read: %v", input, infix, err)
+			}
+			printed, err := p.ReformToRPN(tokens)
+			if err != nil {
+				t.Fatalf("%q printed as %q can't be read: %v", input, infix, err)
+			}
+			if !reflect.DeepEqual(rpn, printed) {
+				t.Errorf("RPN of %q is %v, but %v printed as %q", input, rpn, printed, infix)
+			}
+		}
+	})
+}
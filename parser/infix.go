@@ -0,0 +1,215 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"github.com/niklasstich/calculator/util"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidRPN = errors.New("expression is not valid RPN")
+
+// constantNames are the names of the constants in ASCII and Unicode, the Printer uses them instead of their digits
+var constantNames = map[string][2]string{
+	piDigits:  {"pi", "π"},
+	tauDigits: {"tau", "τ"},
+	eDigits:   {"e", "e"},
+}
+
+// ConstantName returns the name of the constant the digits of a TokenLiteral belong to
+func ConstantName(literal string) (name string, ok bool) {
+	names, ok := constantNames[literal]
+	return names[0], ok
+}
+
+// Printer turns RPN expressions back into infix notation with only the brackets needed to keep the meaning. Its
+// output is read by TokenizeString and ReformToRPN into the same RPN again. The zero value puts spaces around all
+// binary operators and only uses ASCII characters.
+type Printer struct {
+	// Compact leaves out the spaces around + - * / // and ^, the other operators are always surrounded by spaces
+	Compact bool
+	// Unicode prints ×, ÷ and − instead of *, / and -, and π and τ instead of pi and tau
+	Unicode bool
+}
+
+// infixNode is a printed subexpression, precedence is that of its outermost operator
+type infixNode struct {
+	text       string
+	precedence float64
+	//quantity is set for operands with a unit, an exponent directly behind them would become part of the unit
+	quantity bool
+}
+
+// atom is the precedence of operands and calls, which never need brackets
+const atom = math.MaxFloat64
+
+// ReformToInfix prints the expression in infix notation with the zero value of Printer
+func ReformToInfix(expression RPNExpression) (string, error) {
+	return Printer{}.ReformToInfix(expression)
+}
+
+// ReformToInfix prints the expression in infix notation. Operands are printed as they were written if possible,
+// integer literals in other bases are printed in hexadecimal.
+func (p Printer) ReformToInfix(expression RPNExpression) (string, error) {
+	stack := make([]infixNode, 0, len(expression))
+	pop := func(n int) []infixNode {
+		operands := make([]infixNode, n)
+		copy(operands, stack[len(stack)-n:])
+		stack = stack[:len(stack)-n]
+		return operands
+	}
+	for _, t := range expression {
+		if t.TokenType == util.TokenTypeOperand {
+			stack = append(stack, p.operand(t))
+			continue
+		}
+		o := t.TokenOperator
+		if o == nil || o.Bracket || o.Op == util.OpArgumentSeparator || o.Op == util.OpCondition {
+			return "", fmt.Errorf("%w: unexpected '%v'", ErrInvalidRPN, t)
+		}
+		if len(stack) < o.Arity() {
+			return "", fmt.Errorf("%w: not enough operands for '%v'", ErrInvalidRPN, t)
+		}
+		//implicit multiplications are printed as '*', so they have to be bracketed like it
+		if o == tightMultiplication {
+			o = opLookUp['*']
+		}
+		var node infixNode
+		switch {
+		case o.Op == util.OpCall:
+			args := pop(o.Arguments)
+			texts := make([]string, len(args))
+			for i, arg := range args {
+				texts[i] = arg.text
+			}
+			//the space is needed even in compact form, "f(1,000)" would be ambiguous
+			node = infixNode{text: o.Name + "(" + strings.Join(texts, ", ") + ")", precedence: atom}
+		case o.Op == util.OpList:
+			elements := pop(o.Arguments)
+			texts := make([]string, len(elements))
+			for i, element := range elements {
+				texts[i] = element.text
+			}
+			node = infixNode{text: "[" + strings.Join(texts, ", ") + "]", precedence: atom}
+		case o.Op == util.OpTernary:
+			args := pop(3)
+			//only a ternary condition needs brackets, the alternatives are delimited by '?' and ':'
+			node = infixNode{
+				text:       bracket(args[0], args[0].precedence <= o.Precedence) + " ? " + args[1].text + " : " + args[2].text,
+				precedence: o.Precedence,
+			}
+		case isPostfix(o.Op):
+			operand := pop(1)[0]
+			//"%%" would be read as the modulo symbol
+			needed := operand.precedence < o.Precedence || o.Op == util.OpPercent && strings.HasSuffix(operand.text, "%")
+			node = infixNode{text: bracket(operand, needed) + p.symbol(o), precedence: o.Precedence}
+		case o.Unary:
+			operand := pop(1)[0]
+			symbol := p.symbol(o)
+			if o.Name != "" && o.Op != util.OpNegation {
+				symbol += " "
+			}
+			node = infixNode{text: symbol + bracket(operand, operand.precedence < o.Precedence), precedence: o.Precedence}
+		default:
+			operands := pop(2)
+			left, right := operands[0], operands[1]
+			leftNeeded := left.precedence < o.Precedence || left.precedence == o.Precedence && !o.LeftAssociative ||
+				left.quantity && o.Op == util.OpExponentiation
+			rightNeeded := right.precedence < o.Precedence || right.precedence == o.Precedence && o.LeftAssociative
+			separator := " "
+			if p.Compact && isArithmetic(o.Op) {
+				separator = ""
+			}
+			node = infixNode{
+				text:       bracket(left, leftNeeded) + separator + p.symbol(o) + separator + bracket(right, rightNeeded),
+				precedence: o.Precedence,
+			}
+		}
+		stack = append(stack, node)
+	}
+	if len(stack) != 1 {
+		return "", fmt.Errorf("%w: %d results instead of 1", ErrInvalidRPN, len(stack))
+	}
+	return stack[0].text, nil
+}
+
+// operand prints a single operand, negative numbers get the precedence of a negation
+func (p Printer) operand(t util.Token) infixNode {
+	switch {
+	case t.TokenName != "":
+		return infixNode{text: t.TokenName, precedence: atom}
+	case t.TokenBoolean, t.TokenTime != nil, t.TokenZone != nil:
+		return infixNode{text: t.String(), precedence: atom}
+	}
+	var value string
+	switch {
+	case t.TokenLiteral != "":
+		value = t.TokenLiteral
+		if names, ok := constantNames[t.TokenLiteral]; ok {
+			value = names[0]
+			if p.Unicode {
+				value = names[1]
+			}
+		}
+	case t.TokenInteger != nil:
+		//only literals in other bases than 10 have no TokenLiteral, the base itself isn't known anymore
+		value = t.TokenInteger.Format(16)
+	case t.TokenBig != nil:
+		value = util.BigText(t.TokenBig)
+	case t.TokenUnit != nil && t.TokenOperand == 1:
+		//a unit on its own
+		return infixNode{text: t.TokenUnit.String(), precedence: atom, quantity: true}
+	default:
+		value = strconv.FormatFloat(t.TokenOperand, 'g', -1, 64)
+	}
+	node := infixNode{text: value, precedence: atom}
+	if strings.HasPrefix(value, "-") {
+		if p.Unicode {
+			node.text = "−" + value[1:]
+		}
+		node.precedence = negation.Precedence
+	}
+	if t.TokenUnit != nil {
+		node.text += " " + t.TokenUnit.String()
+		node.quantity = true
+	}
+	return node
+}
+
+// symbol returns how the operator is written
+func (p Printer) symbol(o *util.Operator) string {
+	if p.Unicode {
+		switch o.Op {
+		case util.OpMultiplication:
+			return "×"
+		case util.OpDivision:
+			return "÷"
+		case util.OpSubtraction, util.OpNegation:
+			return "−"
+		}
+	}
+	if o.Op == util.OpNegation {
+		return "-"
+	}
+	return o.String()
+}
+
+// bracket returns the text of the node, in brackets if needed
+func bracket(node infixNode, needed bool) string {
+	if needed {
+		return "(" + node.text + ")"
+	}
+	return node.text
+}
+
+// isArithmetic reports whether the operator can be written without spaces around it
+func isArithmetic(op util.Op) bool {
+	switch op {
+	case util.OpAddition, util.OpSubtraction, util.OpMultiplication, util.OpDivision, util.OpIntegerDivision,
+		util.OpExponentiation:
+		return true
+	}
+	return false
+}
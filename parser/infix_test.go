@@ -0,0 +1,137 @@
+package parser
+
+import (
+	"errors"
+	"github.com/niklasstich/calculator/util"
+	"reflect"
+	"testing"
+)
+
+func TestReformToInfix(t *testing.T) {
+	var tests = []struct {
+		input   string
+		printer Printer
+		want    string
+	}{
+		{"2+4", Printer{}, "2 + 4"},
+		{"((2)) * (3 + 4)", Printer{}, "2 * (3 + 4)"},
+		{"(2 * 3) + 4", Printer{}, "2 * 3 + 4"},
+		{"1 - (2 - 3)", Printer{}, "1 - (2 - 3)"},
+		{"(1 - 2) - 3", Printer{}, "1 - 2 - 3"},
+		{"1 / (2 * 3)", Printer{}, "1 / (2 * 3)"},
+		{"2 ^ (3 ^ 4)", Printer{}, "2 ^ 3 ^ 4"},
+		{"(2 ^ 3) ^ 4", Printer{}, "(2 ^ 3) ^ 4"},
+		{"-2^2", Printer{}, "-2 ^ 2"},
+		{"(-2)^2", Printer{}, "(-2) ^ 2"},
+		{"2^-1", Printer{}, "2 ^ -1"},
+		{"-(1 + 2)", Printer{}, "-(1 + 2)"},
+		{"--3", Printer{}, "--3"},
+		{"+3", Printer{}, "3"},
+		{"(-3)!", Printer{}, "(-3)!"},
+		{"(3!)%", Printer{}, "3!%"},
+		{"(10%)%", Printer{}, "(10%)%"},
+		{"200 + 10%", Printer{}, "200 + 10%"},
+		{"~(1 | 2) & 3", Printer{}, "~(1 | 2) & 3"},
+		{"0x1F + 0b11", Printer{}, "0x1f + 0x3"},
+		{"1.50 + 1e3", Printer{}, "1.50 + 1e3"},
+		{"2pi", Printer{}, "2 * pi"},
+		{"tau / e", Printer{Unicode: true}, "τ ÷ e"},
+		{"5 km + 300 m in m", Printer{}, "5 km + 300 m in m"},
+		{"(5 km)^2", Printer{Compact: true}, "(5 km)^2"},
+		{"km^2 * 3", Printer{}, "km^2 * 3"},
+		{"7 %% 3 // 2", Printer{}, "7 mod 3 // 2"},
+		{"not (1 < 2) && true", Printer{}, "not 1 < 2 && true"},
+		{"(not 1) < 2", Printer{}, "(not 1) < 2"},
+		{"1 < 2 == (3 > 4)", Printer{}, "1 < 2 == 3 > 4"},
+		{"1 ? 2 : 3 ? 4 : 5", Printer{}, "1 ? 2 : 3 ? 4 : 5"},
+		{"(1 ? 2 : 3) ? 4 : 5", Printer{}, "(1 ? 2 : 3) ? 4 : 5"},
+		{"1 + (true ? 2 : 3)", Printer{}, "1 + (true ? 2 : 3)"},
+		{"2 * (3 + 4) - 5 / -6", Printer{Compact: true}, "2*(3+4)-5/-6"},
+		{"1 << 2 mod 3", Printer{Compact: true}, "1 << 2 mod 3"},
+		{"2 * (3 - -4) / 5", Printer{Unicode: true}, "2 × (3 − −4) ÷ 5"},
+		{"2 × (3 − −4) ÷ 5", Printer{}, "2 * (3 - -4) / 5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tokens, err := TokenizeString(tt.input)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			rpn, err := ReformToRPN(tokens)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			got, err := tt.printer.ReformToInfix(rpn)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+			//the printed expression has to mean the same
+			tokens, err = TokenizeString(got)
+			if err != nil {
+				t.Fatalf("Unexpected error reading %s: %v", got, err)
+			}
+			again, err := ReformToRPN(tokens)
+			if err != nil {
+				t.Fatalf("Unexpected error reading %s: %v", got, err)
+			}
+			if !reflect.DeepEqual(rpn, again) {
+				t.Errorf("RPN of %s is %v, expected %v", got, again, rpn)
+			}
+		})
+	}
+}
+
+func TestReformToInfixCalls(t *testing.T) {
+	p := Parser{Scope: testScope{"f": true, "g": true}}
+	tokens, err := p.Tokenize("f(1 + 2, g(), -f(3, 4)!) * 2")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	rpn, err := p.ReformToRPN(tokens)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	got, err := Printer{Compact: true}.ReformToInfix(rpn)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := "f(1+2, g(), -f(3, 4)!)*2"; got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+
+	tokens, err = p.Tokenize("f([1, [2 + 3]], [])")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	rpn, err = p.ReformToRPN(tokens)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	got, err = Printer{}.ReformToInfix(rpn)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := "f([1, [2 + 3]], [])"; got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+}
+
+func TestReformToInfixInvalid(t *testing.T) {
+	two := util.Token{TokenType: util.TokenTypeOperand, TokenOperand: 2}
+	plus := util.Token{TokenType: util.TokenTypeOperator, TokenOperator: opLookUp['+']}
+	open := util.Token{TokenType: util.TokenTypeOperator, TokenOperator: opLookUp['(']}
+	for _, expr := range []RPNExpression{
+		{},
+		{two, plus},
+		{two, two},
+		{two, open},
+	} {
+		if _, err := ReformToInfix(expr); !errors.Is(err, ErrInvalidRPN) {
+			t.Errorf("Expected error %v for %v, got %v", ErrInvalidRPN, expr, err)
+		}
+	}
+}
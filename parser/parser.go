@@ -0,0 +1,83 @@
+package parser
+
+import (
+	"errors"
+	"github.com/niklasstich/calculator/util"
+)
+
+var ErrInvalidLocale = errors.New("locale uses the same character for different purposes")
+
+// ImplicitMode selects how adjacent operands like "2(3+4)" or "3pi" are handled
+type ImplicitMode int
+
+const (
+	// ImplicitSamePrecedence multiplies adjacent operands with the precedence of '*', so 1/2pi is (1/2)*pi
+	ImplicitSamePrecedence ImplicitMode = iota
+	// ImplicitHighPrecedence multiplies adjacent operands before '*' and '/', so 1/2pi is 1/(2*pi)
+	ImplicitHighPrecedence
+	// ImplicitStrict rejects adjacent operands with ErrImplicitMultiplication
+	ImplicitStrict
+)
+
+// Scope tells the tokenizer about names defined by the user, they take precedence over constants and units, so a
+// function can be called g even though that is the symbol of the gram.
+type Scope interface {
+	// IsFunction reports whether name is a function which can be called like "f(2, 3)"
+	IsFunction(name string) bool
+}
+
+// VariableScope is implemented by a Scope which also knows variables, like the results of previous calculations.
+// Variables can only be used in expressions, the body of a function definition only knows its parameters.
+type VariableScope interface {
+	IsVariable(name string) bool
+}
+
+// Parser holds the settings used to tokenize and parse expressions, the zero value parses English input.
+type Parser struct {
+	// Locale defines the decimal mark, the group separator and the argument separator of the input,
+	// util.LocaleEnglish if not set
+	Locale util.Locale
+	// ImplicitMultiplication selects how a multiplication is inserted between a number, a bracket or an identifier
+	// and a following bracket or identifier. Two numbers are never multiplied, so RPN input like "3 4 +" still works.
+	ImplicitMultiplication ImplicitMode
+	// ModuloSymbol is the symbol of the floored modulo operator besides "mod", "%%" if not set. Setting it to "%"
+	// replaces the percent operator, like in most programming languages.
+	ModuloSymbol string
+	// Scope resolves user defined names, only the built in names are known if it is not set
+	Scope Scope
+
+	// MaxInputLength is the maximum length of the input in bytes, MaxTokens the maximum number of tokens and MaxDepth
+	// the maximum nesting depth of brackets. They protect against inputs which take too long to process, a value of 0
+	// means there is no limit.
+	MaxInputLength int
+	MaxTokens      int
+	MaxDepth       int
+	// Trace is called with every ParseStep of ReformToRPN if it is set
+	Trace func(ParseStep)
+
+	//params are the parameters of the function definition being parsed, they are variables in its body
+	params []string
+}
+
+func (p *Parser) moduloSymbol() string {
+	if p.ModuloSymbol == "" {
+		return "%%"
+	}
+	return p.ModuloSymbol
+}
+
+func (p *Parser) isVariable(name string) bool {
+	v, ok := p.Scope.(VariableScope)
+	return ok && v.IsVariable(name)
+}
+
+func (p *Parser) locale() (util.Locale, error) {
+	l := p.Locale
+	if l.DecimalMark == 0 {
+		l = util.LocaleEnglish
+	}
+	if l.DecimalMark == l.GroupSeparator || l.DecimalMark == l.ArgumentSeparator {
+		return l, ErrInvalidLocale
+	}
+	return l, nil
+}
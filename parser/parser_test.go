@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
 	"github.com/niklasstich/calculator/util"
 	"reflect"
@@ -442,6 +443,126 @@ func TestTokenizerIntoParser(t *testing.T) {
 			"[3 4 2 * 1 5 - 2 3 ^ ^ / +]",
 			nil, nil,
 		},
+		{
+			"3 km / 20 min in km/h",
+			"[3 km 20 min / 1 km 1 h / in]",
+			nil, nil,
+		},
+		{
+			"-2^2",
+			"[2 2 ^ neg]",
+			nil, nil,
+		},
+		{
+			"2*-(1+-3)",
+			"[2 1 3 neg + neg *]",
+			nil, nil,
+		},
+		{
+			"~1 << 2 + 3 & 0xff",
+			"[1 ~ 2 3 + << 255 &]",
+			nil, nil,
+		},
+		{
+			"1 | 2 xor 3 & 4",
+			"[1 2 3 4 & xor |]",
+			nil, nil,
+		},
+		{
+			"1 & 2 == 2",
+			"[1 2 2 == &]",
+			nil, nil,
+		},
+		{
+			"4 | 1 < 2 && 3 xor 3",
+			"[4 1 2 < | 3 3 xor &&]",
+			nil, nil,
+		},
+		{
+			"200 + 10% * 2",
+			"[200 10 % 2 * +]",
+			nil, nil,
+		},
+		{
+			"(1+2, 3)",
+			"[]",
+			nil, fmt.Errorf("%w: ','", ErrMisplacedSeparator),
+		},
+		{
+			"1, 2",
+			"[]",
+			nil, fmt.Errorf("%w: ','", ErrMisplacedSeparator),
+		},
+		{
+			"(1,5)",
+			"[]",
+			nil, fmt.Errorf("%w: ','", ErrMisplacedSeparator),
+		},
+		{
+			"1 m/s^2 * 2 s to km/h",
+			"[1 m 1 s^2 / 2 s * 1 km 1 h / to]",
+			nil, nil,
+		},
+		{
+			"120 > 100 ? 5*0.9 : 5",
+			"[120 100 > 5 0.9 * 5 ?:]",
+			nil, nil,
+		},
+		{
+			"1 ? 2 : 3 ? 4 : 5",
+			"[1 2 3 4 5 ?: ?:]",
+			nil, nil,
+		},
+		{
+			"1 ? 2 ? 3 : 4 : 5",
+			"[1 2 3 4 ?: 5 ?:]",
+			nil, nil,
+		},
+		{
+			"not 1 < 2 && 3 >= 4 || 5 != 6",
+			"[1 2 < not 3 4 >= && 5 6 != ||]",
+			nil, nil,
+		},
+		{
+			"1 + 2 == 3 & 1 <= 2",
+			"[1 2 + 3 == 1 2 <= &]",
+			nil, nil,
+		},
+		{
+			"(1 ? 2 : 3) + 4",
+			"[1 2 3 ?: 4 +]",
+			nil, nil,
+		},
+		{
+			"1 ? 2",
+			"[]",
+			nil, fmt.Errorf("%w: '?' without ':'", ErrUnmatchedTernary),
+		},
+		{
+			"1 : 2",
+			"[]",
+			nil, fmt.Errorf("%w: ':' without '?'", ErrUnmatchedTernary),
+		},
+		{
+			"(1 ? 2) : 3",
+			"[]",
+			nil, fmt.Errorf("%w: '?' without ':' before ')'", ErrUnmatchedTernary),
+		},
+		{
+			"[1, 2 + 3] * 2",
+			"[1 2 3 + [] 2 *]",
+			nil, nil,
+		},
+		{
+			"[[1], []]",
+			"[1 [] [] []]",
+			nil, nil,
+		},
+		{
+			"[1, 2)",
+			"[]",
+			nil, fmt.Errorf("%v: '[' closed by ')'", ErrUnmatchedParenthesis),
+		},
 		//TODO: some more cases here, longer and more complex inputs
 	}
 
@@ -477,3 +598,73 @@ func TestTokenizerIntoParser(t *testing.T) {
 		})
 	}
 }
+
+func TestArgumentSeparators(t *testing.T) {
+	var tests = []struct {
+		locale util.Locale
+		input  string
+		want   string
+		err    error
+	}{
+		{util.LocaleEnglish, "f(1, (2))", "[1 2 f]", nil},
+		{util.LocaleEnglish, "[1, f(2, 3)]", "[1 2 3 f []]", nil},
+		{util.LocaleEnglish, "f((1, 2))", "", ErrMisplacedSeparator},
+		{util.LocaleEnglish, "[(1, 2)]", "", ErrMisplacedSeparator},
+		{util.LocaleGerman, "f(1; 2)", "[1 2 f]", nil},
+		{util.LocaleGerman, "(1; 2)", "", ErrMisplacedSeparator},
+		{util.LocaleGerman, "1; 2", "", ErrMisplacedSeparator},
+	}
+
+	for i, tt := range tests {
+		testname := fmt.Sprintf("%d: %s", i+1, tt.input)
+		t.Run(testname, func(t *testing.T) {
+			p := Parser{Locale: tt.locale, Scope: testScope{"f": true}}
+			tokens, err := p.Tokenize(tt.input)
+			if err != nil {
+				t.Fatalf("Unexpected error %v", err)
+			}
+			got, err := p.ReformToRPN(tokens)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Expected error %v, got %v", tt.err, err)
+			}
+			if err == nil && fmt.Sprint(got) != tt.want {
+				t.Errorf("Wanted %s, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestLimits(t *testing.T) {
+	var tests = []struct {
+		p     Parser
+		input string
+		err   error
+	}{
+		{Parser{MaxInputLength: 5}, "1 + 2", nil},
+		{Parser{MaxInputLength: 5}, "1 + 23", ErrInputTooLong},
+		{Parser{MaxTokens: 3}, "1 + 2", nil},
+		{Parser{MaxTokens: 3}, "1 + 2 + 3", ErrTooManyTokens},
+		{Parser{MaxTokens: 3}, "2pi(1)", ErrTooManyTokens},
+		{Parser{MaxDepth: 2}, "((1) + (2))", nil},
+		{Parser{MaxDepth: 2}, "(((1)))", ErrNestingTooDeep},
+		{Parser{MaxDepth: 2, Scope: testScope{"f": true}}, "f(f(f(1)))", ErrNestingTooDeep},
+		{Parser{MaxInputLength: 5}, "f(x) = x", ErrInputTooLong},
+	}
+
+	for i, tt := range tests {
+		testname := fmt.Sprintf("%d: %s", i+1, tt.input)
+		t.Run(testname, func(t *testing.T) {
+			_, isDefinition, err := tt.p.ParseDefinition(tt.input)
+			if !isDefinition {
+				var tokens []util.Token
+				tokens, err = tt.p.Tokenize(tt.input)
+				if err == nil {
+					_, err = tt.p.ReformToRPN(tokens)
+				}
+			}
+			if !errors.Is(err, tt.err) {
+				t.Errorf("Expected error %v, got %v", tt.err, err)
+			}
+		})
+	}
+}
@@ -3,13 +3,29 @@ package parser
 import (
 	"errors"
 	"fmt"
+	"github.com/niklasstich/calculator/units"
 	"github.com/niklasstich/calculator/util"
 	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
 var ErrInvalidToken = errors.New("expression contains invalid token")
+var ErrAmbiguousNumber = errors.New("number is ambiguous in this locale")
+var ErrMalformedNumber = errors.New("malformed number")
+var ErrImplicitMultiplication = errors.New("implicit multiplication is not allowed")
+var ErrInputTooLong = errors.New("input is too long")
+var ErrTooManyTokens = errors.New("input has too many tokens")
 
 var opLookUp = map[int32]*util.Operator{
+	'%': {
+		Char:            '%',
+		Precedence:      4,
+		LeftAssociative: true,
+		Bracket:         false,
+		Op:              util.OpPercent,
+	},
 	'!': {
 		Char:            '!',
 		Precedence:      4,
@@ -31,6 +47,21 @@ var opLookUp = map[int32]*util.Operator{
 		Bracket:         true,
 		Op:              util.OpRightBracket,
 	},
+	//square brackets enclose the elements of a list, the '[' is always preceded by listOperator
+	'[': {
+		Char:            '[',
+		Precedence:      5,
+		LeftAssociative: false,
+		Bracket:         true,
+		Op:              util.OpLeftBracket,
+	},
+	']': {
+		Char:            ']',
+		Precedence:      5,
+		LeftAssociative: false,
+		Bracket:         true,
+		Op:              util.OpRightBracket,
+	},
 	'^': {
 		Char:            '^',
 		Precedence:      3,
@@ -66,59 +97,786 @@ var opLookUp = map[int32]*util.Operator{
 		Bracket:         false,
 		Op:              util.OpSubtraction,
 	},
+	'~': {
+		Char:            '~',
+		Precedence:      3,
+		LeftAssociative: false,
+		Bracket:         false,
+		Unary:           true,
+		Op:              util.OpBitwiseNot,
+	},
+	//the bitwise operators bind looser than the comparisons like in C, so 1 & 2 == 2 is 1 & (2 == 2)
+	'&': {
+		Char:            '&',
+		Precedence:      -7,
+		LeftAssociative: true,
+		Bracket:         false,
+		Op:              util.OpBitwiseAnd,
+	},
+	'|': {
+		Char:            '|',
+		Precedence:      -9,
+		LeftAssociative: true,
+		Bracket:         false,
+		Op:              util.OpBitwiseOr,
+	},
+	'<': {
+		Char:            '<',
+		Precedence:      -5,
+		LeftAssociative: true,
+		Bracket:         false,
+		Op:              util.OpLess,
+	},
+	'>': {
+		Char:            '>',
+		Precedence:      -5,
+		LeftAssociative: true,
+		Bracket:         false,
+		Op:              util.OpGreater,
+	},
+	//'?' only marks the start of a ternary operator, ':' completes it in ReformToRPN
+	'?': {
+		Char:            '?',
+		Precedence:      -13,
+		LeftAssociative: false,
+		Bracket:         false,
+		Op:              util.OpCondition,
+	},
+	':': {
+		Char:            ':',
+		Precedence:      -13,
+		LeftAssociative: false,
+		Bracket:         false,
+		Op:              util.OpTernary,
+	},
+}
+
+// opAliases are the typographic variants of operators, they are read like the operator they stand for
+var opAliases = map[int32]int32{
+	'×': '*',
+	'÷': '/',
+	'−': '-',
+}
+
+// symbolLookUp contains all operators which are spelled with two characters, it is checked before opLookUp
+var symbolLookUp = map[string]*util.Operator{
+	"<<": {
+		Name:            "<<",
+		Precedence:      0,
+		LeftAssociative: true,
+		Bracket:         false,
+		Op:              util.OpShiftLeft,
+	},
+	">>": {
+		Name:            ">>",
+		Precedence:      0,
+		LeftAssociative: true,
+		Bracket:         false,
+		Op:              util.OpShiftRight,
+	},
+	"//": {
+		Name:            "//",
+		Precedence:      2,
+		LeftAssociative: true,
+		Bracket:         false,
+		Op:              util.OpIntegerDivision,
+	},
+	"<=": {
+		Name:            "<=",
+		Precedence:      -5,
+		LeftAssociative: true,
+		Bracket:         false,
+		Op:              util.OpLessEqual,
+	},
+	">=": {
+		Name:            ">=",
+		Precedence:      -5,
+		LeftAssociative: true,
+		Bracket:         false,
+		Op:              util.OpGreaterEqual,
+	},
+	"==": {
+		Name:            "==",
+		Precedence:      -6,
+		LeftAssociative: true,
+		Bracket:         false,
+		Op:              util.OpEqual,
+	},
+	"!=": {
+		Name:            "!=",
+		Precedence:      -6,
+		LeftAssociative: true,
+		Bracket:         false,
+		Op:              util.OpNotEqual,
+	},
+	"&&": {
+		Name:            "&&",
+		Precedence:      -11,
+		LeftAssociative: true,
+		Bracket:         false,
+		Op:              util.OpAnd,
+	},
+	"||": {
+		Name:            "||",
+		Precedence:      -12,
+		LeftAssociative: true,
+		Bracket:         false,
+		Op:              util.OpOr,
+	},
+}
+
+// modulo is the floored modulo operator, it is spelled "mod" or with the ModuloSymbol of the Parser
+var modulo = &util.Operator{
+	Name:            "mod",
+	Precedence:      2,
+	LeftAssociative: true,
+	Bracket:         false,
+	Op:              util.OpModulo,
+}
+
+// wordLookUp contains all operators which are spelled as a word instead of a single character
+var wordLookUp = map[string]*util.Operator{
+	"not": {
+		Name:            "not",
+		Precedence:      -10,
+		LeftAssociative: false,
+		Bracket:         false,
+		Unary:           true,
+		Op:              util.OpNot,
+	},
+	"mod": modulo,
+	"rem": {
+		Name:            "rem",
+		Precedence:      2,
+		LeftAssociative: true,
+		Bracket:         false,
+		Op:              util.OpRemainder,
+	},
+	"xor": {
+		Name:            "xor",
+		Precedence:      -8,
+		LeftAssociative: true,
+		Bracket:         false,
+		Op:              util.OpBitwiseXor,
+	},
+	//conversion has the lowest precedence of the arithmetic operators so "3 km + 2 m in m" converts the whole sum
+	"in": {
+		Name:            "in",
+		Precedence:      -4,
+		LeftAssociative: true,
+		Bracket:         false,
+		Op:              util.OpConversion,
+	},
+	"to": {
+		Name:            "to",
+		Precedence:      -4,
+		LeftAssociative: true,
+		Bracket:         false,
+		Op:              util.OpConversion,
+	},
+}
+
+// constants are replaced by their value while tokenizing, they have more digits than float64 can hold for arbitrary
+// precision evaluation
+var constants = map[string]string{
+	"pi":  piDigits,
+	"π":   piDigits,
+	"tau": tauDigits,
+	"τ":   tauDigits,
+	"e":   eDigits,
+}
+
+const (
+	piDigits  = "3.14159265358979323846264338327950288419716939937510582097494459230781640628620899862803482534211706798"
+	tauDigits = "6.28318530717958647692528676655900576839433879875021164194988918461563281257241799725606965068423413596"
+	eDigits   = "2.71828182845904523536028747135266249775724709369995957496696762772407663035354759457138217852516642743"
+)
+
+// booleans are the literals of boolean values
+var booleans = map[string]bool{
+	"true":  true,
+	"false": false,
+}
+
+// tightMultiplication is inserted for implicit multiplications with ImplicitHighPrecedence, it binds tighter than
+// '*' and '/' but looser than '^', so 1/2x^2 is 1/(2*(x^2))
+var tightMultiplication = &util.Operator{
+	Char:            '*',
+	Precedence:      2.5,
+	LeftAssociative: true,
+	Bracket:         false,
+	Op:              util.OpMultiplication,
+}
+
+// listOperator creates a list of the elements between the square brackets following it, ReformToRPN counts them like
+// the arguments of a call
+var listOperator = &util.Operator{
+	Name:       "[]",
+	Precedence: 5,
+	Op:         util.OpList,
 }
 
 // TokenizeString takes a string in arbitrary notation (infix, polish, reverse polish) and returns a slice of Token
 func TokenizeString(input string) (tokens []util.Token, err error) {
+	return (&Parser{}).Tokenize(input)
+}
+
+// Tokenize works like TokenizeString, but reads numbers and argument separators according to the Locale of the Parser
+func (p *Parser) Tokenize(input string) (tokens []util.Token, err error) {
+	locale, err := p.locale()
+	if err != nil {
+		return nil, err
+	}
+	if p.MaxInputLength > 0 && len(input) > p.MaxInputLength {
+		return nil, fmt.Errorf("%w: %d bytes, at most %d are allowed", ErrInputTooLong, len(input), p.MaxInputLength)
+	}
 	//prepare return value
 	tokens = make([]util.Token, 0, 20)
-	var numbuf string
-	numQueued := false
-	//iterate over all characters in the string, see if they are numerical or an operator
-	for i, c := range input {
-		if isNumerical(c) || isDot(c) {
-			//append new digit and remember that we have a number queued
-			numbuf += string(c)
-			numQueued = true
-		} else {
-			//if numQueued is true, we need to push a new symbol with the number first
-			if numQueued {
-				numQueued = false
-				num, err := strconv.ParseFloat(numbuf, 64)
+	//last is the kind of the previous token, it decides whether an implicit multiplication has to be inserted
+	last := kindNone
+	//arguments tells for every open bracket whether it belongs to a call or a list, only there the argument
+	//separator can separate two numbers
+	arguments := make([]bool, 0, 8)
+	//mark is the position of the decimal mark the last number ended with, like in "1,", or -1
+	mark := -1
+	emit := func(t util.Token, kind tokenKind, pos int) error {
+		if last == kindNumber && kind == kindNumber && mark >= 0 {
+			return fmt.Errorf("%w: '%c' at pos %d is the decimal mark, arguments are separated by '%c'",
+				ErrAmbiguousNumber, locale.DecimalMark, mark, locale.ArgumentSeparator)
+		}
+		switch {
+		case kind == kindOpen:
+			arguments = append(arguments, last == kindFunction)
+		case kind == kindClose && len(arguments) > 0:
+			arguments = arguments[:len(arguments)-1]
+		}
+		if endsOperand(last) && startsOperand(kind) && !(last == kindNumber && kind == kindNumber) {
+			if p.ImplicitMultiplication == ImplicitStrict {
+				//constants are named like they were written instead of printing their digits
+				text := t.String()
+				if name, ok := ConstantName(t.TokenLiteral); ok {
+					text = name
+				}
+				return fmt.Errorf("%w: before '%s' at pos %d", ErrImplicitMultiplication, text, pos)
+			}
+			tokens = append(tokens, util.Token{
+				TokenType:     util.TokenTypeOperator,
+				TokenOperator: p.implicitOperator(),
+			})
+		}
+		tokens = append(tokens, t)
+		last = kind
+		if p.MaxTokens > 0 && len(tokens) > p.MaxTokens {
+			return fmt.Errorf("%w: at pos %d, at most %d are allowed", ErrTooManyTokens, pos, p.MaxTokens)
+		}
+		return nil
+	}
+	//iterate over all characters in the string, see if they are numerical, a word or an operator
+	for i := 0; i < len(input); {
+		c, size := utf8.DecodeRuneInString(input[i:])
+		switch {
+		case isNumerical(c) || c == locale.DecimalMark:
+			if token, end, ok, err := scanDate(input, i); ok {
 				if err != nil {
-					return nil, fmt.Errorf("malformed expression near '%c': %w", c, err)
+					return nil, err
 				}
-				tokens = append(tokens, util.Token{
-					TokenType:    util.TokenTypeOperand,
-					TokenOperand: num,
-				})
-				numbuf = ""
+				if err := emit(token, kindNumber, i); err != nil {
+					return nil, err
+				}
+				i = end
+				mark = -1
+				continue
+			}
+			separates := len(arguments) > 0 && arguments[len(arguments)-1]
+			token, end, err := scanNumber(input, i, locale, separates)
+			if err != nil {
+				return nil, err
+			}
+			number := end
+			//a unit directly following a number is attached to it, so "3 km / 20 min" divides two quantities
+			start := skipWhitespace(input, end)
+			if unit, next, ok := scanUnit(input, start); ok && !p.isUserWord(input, start) {
+				token.TokenUnit = unit
+				end = next
+				//further parts of a duration like "3h 20m" are added to it
+				if duration, next, ok := p.scanDuration(input, end, token, locale, separates); ok {
+					token, end = duration, next
+				}
+			}
+			if err := emit(token, kindNumber, i); err != nil {
+				return nil, err
+			}
+			i = end
+			mark = -1
+			if c, size := utf8.DecodeLastRuneInString(input[:number]); c == locale.DecimalMark && end == number {
+				mark = number - size
 			}
-			if c == ' ' || c == '\n' {
+		case isLetter(c):
+			end := scanWord(input, i)
+			word := input[i:end]
+			if operator := wordLookUp[word]; operator != nil {
+				err := emit(util.Token{
+					TokenType:     util.TokenTypeOperator,
+					TokenOperator: operator,
+				}, kindOperator, i)
+				if err != nil {
+					return nil, err
+				}
+				i = end
+				continue
+			}
+			if p.isParam(word) {
+				err := emit(util.Token{
+					TokenType: util.TokenTypeOperand,
+					TokenName: word,
+				}, kindIdentifier, i)
+				if err != nil {
+					return nil, err
+				}
+				i = end
+				continue
+			}
+			if p.isCall(input, i) {
+				//the arguments are counted by ReformToRPN
+				err := emit(util.Token{
+					TokenType: util.TokenTypeOperator,
+					TokenOperator: &util.Operator{
+						Name:       word,
+						Precedence: 5,
+						Op:         util.OpCall,
+					},
+				}, kindFunction, i)
+				if err != nil {
+					return nil, err
+				}
+				i = end
+				continue
+			}
+			if p.isVariable(word) {
+				err := emit(util.Token{
+					TokenType: util.TokenTypeOperand,
+					TokenName: word,
+				}, kindIdentifier, i)
+				if err != nil {
+					return nil, err
+				}
+				i = end
+				continue
+			}
+			if clockNames[word] {
+				err := emit(util.Token{
+					TokenType: util.TokenTypeOperand,
+					TokenName: word,
+				}, kindIdentifier, i)
+				if err != nil {
+					return nil, err
+				}
+				i = end
 				continue
 			}
+			token := util.Token{
+				TokenType: util.TokenTypeOperand,
+			}
+			if b, ok := booleans[word]; ok {
+				token.TokenBoolean = true
+				if b {
+					token.TokenOperand = 1
+				}
+			} else if digits, ok := constants[word]; ok {
+				token.TokenOperand, _ = strconv.ParseFloat(digits, 64)
+				token.TokenLiteral = digits
+			} else if unit, next, ok := scanUnit(input, i); ok {
+				//a unit on its own is a quantity of 1 of that unit, e.g. the "km/h" in "x in km/h"
+				token.TokenOperand = 1
+				token.TokenUnit = unit
+				end = next
+			} else if zone, next, ok := scanZone(input, i); ok {
+				//a time zone on its own is what dates are converted to, like in "now in Asia/Tokyo"
+				token.TokenZone = zone
+				end = next
+			} else if p.Scope != nil && p.Scope.IsFunction(word) {
+				return nil, fmt.Errorf("%w: function %s without arguments at pos %d", ErrInvalidToken, word, i)
+			} else {
+				return nil, fmt.Errorf("%w: %s at pos %d", ErrInvalidToken, word, i)
+			}
+			if err := emit(token, kindIdentifier, i); err != nil {
+				return nil, err
+			}
+			i = end
+		case c == '$':
+			//"$3" is a variable like any other, the name is only reserved for numbered results
+			end := i + 1
+			for end < len(input) && isNumerical(int32(input[end])) {
+				end++
+			}
+			word := input[i:end]
+			if !p.isVariable(word) {
+				return nil, fmt.Errorf("%w: %s at pos %d", ErrInvalidToken, word, i)
+			}
+			err := emit(util.Token{
+				TokenType: util.TokenTypeOperand,
+				TokenName: word,
+			}, kindIdentifier, i)
+			if err != nil {
+				return nil, err
+			}
+			i = end
+		case c == locale.ArgumentSeparator:
+			err := emit(util.Token{
+				TokenType: util.TokenTypeOperator,
+				TokenOperator: &util.Operator{
+					Char:       c,
+					Precedence: 5,
+					Bracket:    false,
+					Op:         util.OpArgumentSeparator,
+				},
+			}, kindOperator, i)
+			if err != nil {
+				return nil, err
+			}
+			i += size
+		case c == '[':
+			err := emit(util.Token{
+				TokenType:     util.TokenTypeOperator,
+				TokenOperator: listOperator,
+			}, kindFunction, i)
+			if err != nil {
+				return nil, err
+			}
+			err = emit(util.Token{
+				TokenType:     util.TokenTypeOperator,
+				TokenOperator: opLookUp[c],
+			}, kindOpen, i)
+			if err != nil {
+				return nil, err
+			}
+			i += size
+		case isWhitespace(c):
+			i += size
+		default:
 			operator := opLookUp[c]
+			if alias, ok := opAliases[c]; ok {
+				operator = opLookUp[alias]
+			}
+			if i+1 < len(input) && symbolLookUp[input[i:i+2]] != nil {
+				operator = symbolLookUp[input[i:i+2]]
+				size = 2
+			}
+			if symbol := p.moduloSymbol(); strings.HasPrefix(input[i:], symbol) {
+				operator = modulo
+				size = len(symbol)
+			}
 			if operator == nil {
 				return nil, fmt.Errorf("%w: %c at pos %d", ErrInvalidToken, c, i)
 			}
-			tokens = append(tokens, util.Token{
+			err := emit(util.Token{
 				TokenType:     util.TokenTypeOperator,
 				TokenOperator: operator,
-			})
+			}, operatorKind(operator), i)
+			if err != nil {
+				return nil, err
+			}
+			i += size
 		}
 	}
-	//check for remaining number
-	if numQueued {
-		num, err := strconv.ParseFloat(numbuf, 64)
+	return
+}
+
+// tokenKind is used to find adjacent tokens which are implicitly multiplied
+type tokenKind int
+
+const (
+	kindNone tokenKind = iota
+	kindNumber
+	kindIdentifier
+	kindOpen
+	kindClose
+	kindPostfix
+	kindOperator
+	kindFunction
+)
+
+func operatorKind(o *util.Operator) tokenKind {
+	switch o.Op {
+	case util.OpLeftBracket:
+		return kindOpen
+	case util.OpRightBracket:
+		return kindClose
+	case util.OpFactorial, util.OpPercent:
+		return kindPostfix
+	default:
+		return kindOperator
+	}
+}
+
+func endsOperand(k tokenKind) bool {
+	return k == kindNumber || k == kindIdentifier || k == kindClose || k == kindPostfix
+}
+
+func startsOperand(k tokenKind) bool {
+	return k == kindNumber || k == kindIdentifier || k == kindOpen || k == kindFunction
+}
+
+// isUserWord reports whether the word at pos is a parameter, a variable or a call of a user defined function, which
+// take precedence over units and constants. Function names are only special if they are called, so "3 g" is still 3 gram.
+func (p *Parser) isUserWord(input string, pos int) bool {
+	end := scanWord(input, pos)
+	word := input[pos:end]
+	return p.isParam(word) || p.isVariable(word) || p.isCall(input, pos)
+}
+
+// isCall reports whether the word at pos is the name of a user defined function followed by its arguments
+func (p *Parser) isCall(input string, pos int) bool {
+	end := scanWord(input, pos)
+	next := skipWhitespace(input, end)
+	return p.Scope != nil && p.Scope.IsFunction(input[pos:end]) && next < len(input) && input[next] == '('
+}
+
+func (p *Parser) isParam(word string) bool {
+	for _, param := range p.params {
+		if param == word {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Parser) implicitOperator() *util.Operator {
+	if p.ImplicitMultiplication == ImplicitHighPrecedence {
+		return tightMultiplication
+	}
+	return opLookUp['*']
+}
+
+// scanNumber reads the number literal starting at pos, which is either a decimal number or an integer with a 0x, 0o or
+// 0b prefix. Integer literals also get their exact value as TokenInteger, so they can be used in programmer mode
+// without losing precision. separates is set inside the brackets of calls and lists, where the argument separator
+// separates numbers.
+func scanNumber(input string, pos int, locale util.Locale, separates bool) (token util.Token, end int, err error) {
+	token.TokenType = util.TokenTypeOperand
+	if base := literalBase(input, pos); base != 0 {
+		end = pos + 2
+		for end < len(input) && isDigitInBase(int32(input[end]), base) {
+			end++
+		}
+		if end < len(input) {
+			if c, _ := utf8.DecodeRuneInString(input[end:]); isLetter(c) || isNumerical(c) {
+				return token, end, fmt.Errorf("%w: malformed literal %s%c at pos %d", ErrInvalidToken, input[pos:end], c, pos)
+			}
+		}
+		num, err := strconv.ParseUint(input[pos+2:end], base, 64)
 		if err != nil {
-			return nil, fmt.Errorf("found malformed expression while cleaning up: %w", err)
+			return token, end, fmt.Errorf("malformed literal %s at pos %d: %w", input[pos:end], pos, err)
 		}
-		tokens = append(tokens, util.Token{
-			TokenType:    util.TokenTypeOperand,
-			TokenOperand: num,
-		})
+		token.TokenOperand = float64(num)
+		token.TokenInteger = &util.Integer{Value: num, Type: util.Uint64}
+		return token, end, nil
 	}
-	return
+
+	var digits strings.Builder
+	end, err = scanDecimal(input, pos, locale, separates, &digits)
+	if err != nil {
+		return token, end, err
+	}
+	//scanDecimal only collects valid numbers, so the only error left is a value out of range
+	num, err := strconv.ParseFloat(digits.String(), 64)
+	if err != nil {
+		return token, end, fmt.Errorf("%w: %s at pos %d is out of range", ErrMalformedNumber, input[pos:end], pos)
+	}
+	token.TokenOperand = num
+	token.TokenLiteral = digits.String()
+	if integer, err := strconv.ParseUint(digits.String(), 10, 64); err == nil {
+		token.TokenInteger = &util.Integer{Value: integer, Type: util.Uint64}
+	}
+	return token, end, nil
+}
+
+// scanDecimal collects the digits, decimal mark and exponent starting at pos into digits, using '.' as decimal point
+// and dropping group separators. A group separator has to be followed by exactly three digits, if it could also be
+// read as an argument separator because separates is set an error is returned instead of guessing. An 'e' or 'E'
+// starts an exponent if it is followed by digits, if it is followed by a letter the number ends before it, so
+// identifiers can start with e.
+func scanDecimal(input string, pos int, locale util.Locale, separates bool, digits *strings.Builder) (end int,
+	err error) {
+	end = pos
+	//groupDigits counts the digits since the start or the last group separator
+	groupDigits, mantissaDigits := 0, 0
+	grouped, decimal, exponent := false, false, false
+	for end < len(input) {
+		c, size := utf8.DecodeRuneInString(input[end:])
+		switch {
+		case isNumerical(c):
+			digits.WriteRune(c)
+			groupDigits++
+			if !exponent {
+				mantissaDigits++
+			}
+		case c == locale.DecimalMark:
+			if exponent {
+				return end, fmt.Errorf("%w: decimal mark '%c' in exponent at pos %d", ErrMalformedNumber, c, end)
+			}
+			if decimal {
+				return end, fmt.Errorf("%w: second decimal mark '%c' at pos %d", ErrMalformedNumber, c, end)
+			}
+			if grouped && groupDigits != 3 {
+				return end, fmt.Errorf("%w: group of %d digits before '%c' at pos %d",
+					ErrAmbiguousNumber, groupDigits, c, end)
+			}
+			digits.WriteByte('.')
+			decimal = true
+		case (c == 'e' || c == 'E') && !exponent:
+			if mantissaDigits == 0 {
+				return end, fmt.Errorf("%w: exponent without digits before it at pos %d", ErrMalformedNumber, end)
+			}
+			next := end + size
+			if next < len(input) && (input[next] == '+' || input[next] == '-') {
+				next++
+			} else if n, _ := utf8.DecodeRuneInString(input[next:]); next < len(input) && isLetter(n) {
+				//e.g. "2exp", the number ends here and an identifier follows
+				return end, nil
+			}
+			if next >= len(input) || !isNumerical(int32(input[next])) {
+				return end, fmt.Errorf("%w: expected exponent digits at pos %d", ErrMalformedNumber, next)
+			}
+			digits.WriteString(input[end:next])
+			exponent = true
+			end = next
+			continue
+		case c == locale.GroupSeparator && c != 0:
+			//separators that also end numbers anyway are only group separators if they look like one
+			ends := isWhitespace(c) || c == locale.ArgumentSeparator
+			valid := !decimal && !exponent && groupDigits >= 1 &&
+				(grouped && groupDigits == 3 || !grouped && groupDigits <= 3) && isDigitGroup(input, end+size)
+			switch {
+			case !valid && ends:
+				return end, checkMantissa(input, pos, mantissaDigits)
+			case !valid:
+				return end, fmt.Errorf("%w: '%c' at pos %d is the group separator and has to separate groups of "+
+					"three digits, use '%c' as decimal mark", ErrAmbiguousNumber, c, end, locale.DecimalMark)
+			case separates && c == locale.ArgumentSeparator:
+				return end, fmt.Errorf("%w: '%c' at pos %d could be a group separator or an argument separator",
+					ErrAmbiguousNumber, c, end)
+			}
+			grouped = true
+			groupDigits = 0
+		default:
+			return end, checkMantissa(input, pos, mantissaDigits)
+		}
+		end += size
+	}
+	return end, checkMantissa(input, pos, mantissaDigits)
+}
+
+// checkMantissa returns an error for numbers without any digits, like a lone decimal mark
+func checkMantissa(input string, pos int, mantissaDigits int) error {
+	if mantissaDigits == 0 {
+		c, _ := utf8.DecodeRuneInString(input[pos:])
+		return fmt.Errorf("%w: '%c' at pos %d is not followed by digits", ErrMalformedNumber, c, pos)
+	}
+	return nil
+}
+
+// isDigitGroup reports whether there are exactly three digits at pos
+func isDigitGroup(input string, pos int) bool {
+	if pos+3 > len(input) {
+		return false
+	}
+	for _, c := range input[pos : pos+3] {
+		if !isNumerical(c) {
+			return false
+		}
+	}
+	return pos+3 == len(input) || !isNumerical(int32(input[pos+3]))
+}
+
+// literalBase returns the base of the integer literal starting at pos, or 0 if there is no prefixed literal
+func literalBase(input string, pos int) int {
+	if pos+2 >= len(input) || input[pos] != '0' {
+		return 0
+	}
+	base := 0
+	switch input[pos+1] {
+	case 'x', 'X':
+		base = 16
+	case 'o', 'O':
+		base = 8
+	case 'b', 'B':
+		base = 2
+	}
+	if base == 0 || !isDigitInBase(int32(input[pos+2]), base) {
+		return 0
+	}
+	return base
+}
+
+func isDigitInBase(c int32, base int) bool {
+	switch {
+	case c >= '0' && c <= '9':
+		return int(c-'0') < base
+	case c >= 'a' && c <= 'f', c >= 'A' && c <= 'F':
+		return base == 16
+	default:
+		return false
+	}
+}
+
+// scanUnit reads the unit starting at pos, including an exponent written directly behind it like "m^2" or "s^-1".
+// It returns the unit and the position behind it, or false if there is no known unit at pos.
+func scanUnit(input string, pos int) (unit *util.Unit, end int, ok bool) {
+	if pos >= len(input) {
+		return nil, pos, false
+	}
+	if c, _ := utf8.DecodeRuneInString(input[pos:]); !isLetter(c) {
+		return nil, pos, false
+	}
+	end = scanWord(input, pos)
+	word := input[pos:end]
+	if wordLookUp[word] != nil {
+		return nil, pos, false
+	}
+	unit, ok = units.Lookup(word)
+	if !ok {
+		return nil, pos, false
+	}
+	//check for an exponent
+	if end < len(input) && input[end] == '^' {
+		expEnd := end + 1
+		if expEnd < len(input) && (input[expEnd] == '-' || input[expEnd] == '+') {
+			expEnd++
+		}
+		digits := expEnd
+		for expEnd < len(input) && isNumerical(int32(input[expEnd])) {
+			expEnd++
+		}
+		if expEnd > digits {
+			//a unit to the power of 0 is no unit at all, it is left to the '^' operator like any other exponent
+			exp, err := strconv.Atoi(input[end+1 : expEnd])
+			if err == nil && exp != 0 {
+				unit = unit.Pow(exp)
+				end = expEnd
+			}
+		}
+	}
+	return unit, end, true
+}
+
+// scanWord returns the position behind the word starting at pos
+func scanWord(input string, pos int) int {
+	for pos < len(input) {
+		c, size := utf8.DecodeRuneInString(input[pos:])
+		if !isLetter(c) && !isNumerical(c) {
+			break
+		}
+		pos += size
+	}
+	return pos
+}
+
+func skipWhitespace(input string, pos int) int {
+	for pos < len(input) && isWhitespace(int32(input[pos])) {
+		pos++
+	}
+	return pos
 }
 
 func isDot(c int32) bool {
@@ -129,3 +887,11 @@ func isDot(c int32) bool {
 func isNumerical(c int32) bool {
 	return c >= '0' && c <= '9'
 }
+
+func isLetter(c int32) bool {
+	return unicode.IsLetter(c) || c == '_'
+}
+
+func isWhitespace(c int32) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
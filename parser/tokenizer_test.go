@@ -146,11 +146,94 @@ func TestTokenizer(t *testing.T) {
 
 		{"@", fmt.Errorf("%w: %c at pos %d", ErrInvalidToken, '@', 0), []util.Token{}},
 
-		{"2..", errors.New("found malformed expression while cleaning up: strconv.ParseFloat: parsing " +
-			"\"2..\": invalid syntax"), []util.Token{}},
+		{"2..", fmt.Errorf("%w: second decimal mark '.' at pos 2", ErrMalformedNumber), []util.Token{}},
 
-		{"2.. 4 7 3", errors.New("malformed expression near ' ': strconv.ParseFloat: parsing " +
-			"\"2..\": invalid syntax"), []util.Token{}},
+		{"2.. 4 7 3", fmt.Errorf("%w: second decimal mark '.' at pos 2", ErrMalformedNumber), []util.Token{}},
+
+		{"1.2.3", fmt.Errorf("%w: second decimal mark '.' at pos 3", ErrMalformedNumber), []util.Token{}},
+
+		{"1 + 1e", fmt.Errorf("%w: expected exponent digits at pos 6", ErrMalformedNumber), []util.Token{}},
+
+		{"1e+ 2", fmt.Errorf("%w: expected exponent digits at pos 3", ErrMalformedNumber), []util.Token{}},
+
+		{"2e3.5", fmt.Errorf("%w: decimal mark '.' in exponent at pos 3", ErrMalformedNumber), []util.Token{}},
+
+		{"4 * .", fmt.Errorf("%w: '.' at pos 4 is not followed by digits", ErrMalformedNumber), []util.Token{}},
+
+		{"1e999", fmt.Errorf("%w: 1e999 at pos 0 is out of range", ErrMalformedNumber), []util.Token{}},
+
+		{"1.5e-3 6.022E23 2e+2 .5e1", nil, []util.Token{
+			{
+				TokenType:    util.TokenTypeOperand,
+				TokenOperand: 1.5e-3,
+			},
+			{
+				TokenType:    util.TokenTypeOperand,
+				TokenOperand: 6.022e23,
+			},
+			{
+				TokenType:    util.TokenTypeOperand,
+				TokenOperand: 200,
+			},
+			{
+				TokenType:    util.TokenTypeOperand,
+				TokenOperand: 5,
+			},
+		}},
+
+		{"2exp", fmt.Errorf("%w: %s at pos %d", ErrInvalidToken, "exp", 1), []util.Token{}},
+
+		{"3 km", nil, []util.Token{{
+			TokenType:    util.TokenTypeOperand,
+			TokenOperand: 3,
+			TokenUnit:    &util.Unit{Terms: []util.UnitTerm{{Symbol: "km", Power: 1}}},
+		}}},
+
+		{"2.5m^2", nil, []util.Token{{
+			TokenType:    util.TokenTypeOperand,
+			TokenOperand: 2.5,
+			TokenUnit:    &util.Unit{Terms: []util.UnitTerm{{Symbol: "m", Power: 2}}},
+		}}},
+
+		{"in h", nil, []util.Token{
+			{
+				TokenType: util.TokenTypeOperator,
+				TokenOperator: &util.Operator{
+					Op:              util.OpConversion,
+					Name:            "in",
+					Precedence:      -4,
+					LeftAssociative: true,
+					Bracket:         false,
+				},
+			},
+			{
+				TokenType:    util.TokenTypeOperand,
+				TokenOperand: 1,
+				TokenUnit:    &util.Unit{Terms: []util.UnitTerm{{Symbol: "h", Power: 1}}},
+			},
+		}},
+
+		{"0x1F 0o17 0b11", nil, []util.Token{
+			{
+				TokenType:    util.TokenTypeOperand,
+				TokenOperand: 31,
+			},
+			{
+				TokenType:    util.TokenTypeOperand,
+				TokenOperand: 15,
+			},
+			{
+				TokenType:    util.TokenTypeOperand,
+				TokenOperand: 3,
+			},
+		}},
+
+		{"0b102", fmt.Errorf("%w: malformed literal %s at pos %d", ErrInvalidToken, "0b102", 0), []util.Token{}},
+
+		{"0x1FFFFFFFFFFFFFFFF", errors.New("malformed literal 0x1FFFFFFFFFFFFFFFF at pos 0: strconv.ParseUint: " +
+			"parsing \"1FFFFFFFFFFFFFFFF\": value out of range"), []util.Token{}},
+
+		{"3 foo", fmt.Errorf("%w: %s at pos %d", ErrInvalidToken, "foo", 2), []util.Token{}},
 	}
 
 	for i, tt := range tests {
@@ -184,6 +267,147 @@ func TestTokenizer(t *testing.T) {
 				if !reflect.DeepEqual(got.TokenOperator, w.TokenOperator) {
 					t.Errorf("i=%d:Expected TokenOperator %v, got %v", i, w.TokenOperator, got.TokenOperator)
 				}
+				if got.TokenUnit.String() != w.TokenUnit.String() {
+					t.Errorf("i=%d:Expected TokenUnit %v, got %v", i, w.TokenUnit, got.TokenUnit)
+				}
+			}
+		})
+	}
+}
+
+func TestTokenizerLocale(t *testing.T) {
+	var tests = []struct {
+		locale util.Locale
+		input  string
+		want   string
+		err    error
+	}{
+		{util.LocaleEnglish, "3.5 * 2", "[3.5 * 2]", nil},
+		{util.LocaleGerman, "3,5 * 2", "[3.5 * 2]", nil},
+		{util.LocaleGerman, "1.234,56", "[1234.56]", nil},
+		{util.LocaleGerman, "1.234.567", "[1234567]", nil},
+		{util.LocaleGerman, "1,5e3 + 2,5E-1", "[1500 + 0.25]", nil},
+		{util.LocaleGerman, "(1,5; 2)", "[( 1.5 ; 2 )]", nil},
+		{util.LocaleFrench, "1 234,5 + 2", "[1234.5 + 2]", nil},
+		{util.LocaleFrench, "12 3", "[12 3]", nil},
+		{util.LocaleSwiss, "1'000.5", "[1000.5]", nil},
+		{util.LocaleEnglish, "(1, 234)", "[( 1 , 234 )]", nil},
+		{util.LocaleEnglish, "(1,23)", "[( 1 , 23 )]", nil},
+		{util.LocaleEnglish, "(1234,567)", "[( 1234 , 567 )]", nil},
+		{util.Locale{DecimalMark: '.'}, "1,234", "", ErrInvalidToken},
+		{util.LocaleEnglish, "1,234 + 1", "[1234 + 1]", nil},
+		{util.LocaleEnglish, "(1,234,567)", "[( 1234567 )]", nil},
+		{util.LocaleEnglish, "[1,234]", "", ErrAmbiguousNumber},
+		{util.LocaleEnglish, "f(1,234)", "", ErrAmbiguousNumber},
+		{util.LocaleEnglish, "2 (1,234)", "[2 * ( 1234 )]", nil},
+		{util.LocaleEnglish, "[(1,234)]", "[[] [ ( 1234 ) ]]", nil},
+		{util.LocaleEnglish, "[1, (2), 3,456]", "", ErrAmbiguousNumber},
+		{util.LocaleGerman, "3.5", "", ErrAmbiguousNumber},
+		{util.LocaleGerman, "1.2345", "", ErrAmbiguousNumber},
+		{util.LocaleGerman, "1,234.5", "", ErrAmbiguousNumber},
+		{util.LocaleGerman, "12.34,5", "", ErrAmbiguousNumber},
+		{util.LocaleGerman, "1, 2", "", ErrAmbiguousNumber},
+		{util.LocaleGerman, "f(1, 2)", "", ErrAmbiguousNumber},
+		{util.LocaleGerman, "1, + 2", "[1 + 2]", nil},
+		{util.Locale{DecimalMark: ',', GroupSeparator: '.', ArgumentSeparator: ','}, "1", "", ErrInvalidLocale},
+	}
+
+	for i, tt := range tests {
+		testname := fmt.Sprintf("%d:\"%s\"", i+1, tt.input)
+		t.Run(testname, func(t *testing.T) {
+			p := Parser{Locale: tt.locale, Scope: testScope{"f": true}}
+			got, err := p.Tokenize(tt.input)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Expected error %v, got %v", tt.err, err)
+			}
+			if err == nil && fmt.Sprint(got) != tt.want {
+				t.Errorf("Wanted %s, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestImplicitMultiplication(t *testing.T) {
+	var tests = []struct {
+		mode  ImplicitMode
+		input string
+		want  string
+		err   error
+	}{
+		{ImplicitSamePrecedence, "2(3+4)", "[2 3 4 + *]", nil},
+		{ImplicitSamePrecedence, "(1+2)(3+4)", "[1 2 + 3 4 + *]", nil},
+		{ImplicitSamePrecedence, "(1+2)3", "[1 2 + 3 *]", nil},
+		{ImplicitSamePrecedence, "3pi", "[3 3.141592653589793 *]", nil},
+		{ImplicitSamePrecedence, "2 e", "[2 2.718281828459045 *]", nil},
+		{ImplicitSamePrecedence, "3! (2)", "[3 ! 2 *]", nil},
+		{ImplicitSamePrecedence, "(1+1) km", "[1 1 + 1 km *]", nil},
+		{ImplicitSamePrecedence, "1/2pi", "[1 2 / 3.141592653589793 *]", nil},
+		{ImplicitHighPrecedence, "1/2pi", "[1 2 3.141592653589793 * /]", nil},
+		{ImplicitHighPrecedence, "1/2(3)^2", "[1 2 3 2 ^ * /]", nil},
+		{ImplicitHighPrecedence, "2*3(4)", "[2 3 4 * *]", nil},
+		{ImplicitSamePrecedence, "3 4 +", "[3 4 +]", nil},
+		{ImplicitStrict, "3 4 +", "[3 4 +]", nil},
+		{ImplicitStrict, "2*(3+4)", "[2 3 4 + *]", nil},
+		{ImplicitStrict, "2(3+4)", "", ErrImplicitMultiplication},
+		{ImplicitStrict, "3pi", "implicit multiplication is not allowed: before 'pi' at pos 1", ErrImplicitMultiplication},
+		{ImplicitStrict, "2 e", "implicit multiplication is not allowed: before 'e' at pos 2", ErrImplicitMultiplication},
+	}
+
+	for i, tt := range tests {
+		testname := fmt.Sprintf("%d:\"%s\"", i+1, tt.input)
+		t.Run(testname, func(t *testing.T) {
+			p := Parser{ImplicitMultiplication: tt.mode}
+			tokens, err := p.Tokenize(tt.input)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Expected error %v, got %v", tt.err, err)
+			}
+			if err != nil {
+				//the message of an error is compared if it is given instead of the result
+				if tt.want != "" && err.Error() != tt.want {
+					t.Errorf("Wanted error %q, got %q", tt.want, err)
+				}
+				return
+			}
+			rpn, err := ReformToRPN(tokens)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if fmt.Sprint(rpn) != tt.want {
+				t.Errorf("Wanted %s, got %v", tt.want, rpn)
+			}
+		})
+	}
+}
+
+func TestModuloSymbol(t *testing.T) {
+	var tests = []struct {
+		symbol string
+		input  string
+		want   string
+	}{
+		{"", "7 %% 3", "[7 3 mod]"},
+		{"", "7 mod 3 + 1", "[7 3 mod 1 +]"},
+		{"", "7 rem 3 * 2", "[7 3 rem 2 *]"},
+		{"", "7 // 2 ^ 2", "[7 2 2 ^ //]"},
+		{"", "10% * 2", "[10 % 2 *]"},
+		{"%", "7 % 3", "[7 3 mod]"},
+		{"\\", "7 \\ 3 - 1", "[7 3 mod 1 -]"},
+	}
+
+	for i, tt := range tests {
+		testname := fmt.Sprintf("%d:\"%s\"", i+1, tt.input)
+		t.Run(testname, func(t *testing.T) {
+			p := Parser{ModuloSymbol: tt.symbol}
+			tokens, err := p.Tokenize(tt.input)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			rpn, err := ReformToRPN(tokens)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if fmt.Sprint(rpn) != tt.want {
+				t.Errorf("Wanted %s, got %v", tt.want, rpn)
 			}
 		})
 	}
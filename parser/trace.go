@@ -0,0 +1,55 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/niklasstich/calculator/util"
+	"strings"
+)
+
+// ParseStep is the state of the Shunting-yard algorithm after a token of the infix expression has been handled
+type ParseStep struct {
+	// Token is the token read in this step, a '-' used as sign is already replaced by the negation. It is nil for the
+	// last step, which moves the remaining operators to the output.
+	Token *util.Token
+	// Output is the RPN produced so far, Operators the operator stack from the bottom to the top
+	Output    []util.Token
+	Operators []util.Token
+}
+
+// String prints the token and the contents of both queues, "end" stands for the last step
+func (s ParseStep) String() string {
+	token := "end"
+	if s.Token != nil {
+		token = s.Token.String()
+	}
+	return fmt.Sprintf("%-6s output [%s] operators [%s]", token, strings.Join(util.TokenStrings(s.Output), " "),
+		strings.Join(util.TokenStrings(s.Operators), " "))
+}
+
+// MarshalJSON writes all tokens of the step as text
+func (s ParseStep) MarshalJSON() ([]byte, error) {
+	var token *string
+	if s.Token != nil {
+		t := s.Token.String()
+		token = &t
+	}
+	return json.Marshal(struct {
+		Token     *string  `json:"token"`
+		Output    []string `json:"output"`
+		Operators []string `json:"operators"`
+	}{token, util.TokenStrings(s.Output), util.TokenStrings(s.Operators)})
+}
+
+// trace reports the step of token if the Parser is traced
+func (p *Parser) trace(token *util.Token, output RPNExpression, operators *util.TokenStack) {
+	if p.Trace == nil {
+		return
+	}
+	step := ParseStep{Output: append([]util.Token(nil), output...), Operators: operators.Tokens()}
+	if token != nil {
+		t := *token
+		step.Token = &t
+	}
+	p.Trace(step)
+}
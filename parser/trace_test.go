@@ -0,0 +1,72 @@
+package parser
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestTrace(t *testing.T) {
+	var tests = []struct {
+		input string
+		steps []string
+	}{
+		{"+2 ^ -(1)", []string{
+			"+      output [] operators []",
+			"2      output [2] operators []",
+			"^      output [2] operators [^]",
+			"neg    output [2] operators [^ neg]",
+			"(      output [2] operators [^ neg (]",
+			"1      output [2 1] operators [^ neg (]",
+			")      output [2 1] operators [^ neg]",
+			"end    output [2 1 neg ^] operators []",
+		}},
+		{"max(1, 2)", []string{
+			"max    output [] operators [max]",
+			"(      output [] operators [max (]",
+			"1      output [1] operators [max (]",
+			",      output [1] operators [max (]",
+			"2      output [1 2] operators [max (]",
+			")      output [1 2 max] operators []",
+			"end    output [1 2 max] operators []",
+		}},
+	}
+	for _, tt := range tests {
+		var steps []string
+		p := Parser{Scope: testScope{"max": true}, Trace: func(s ParseStep) {
+			steps = append(steps, s.String())
+		}}
+		tokens, err := p.Tokenize(tt.input)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", tt.input, err)
+		}
+		if _, err := p.ReformToRPN(tokens); err != nil {
+			t.Fatalf("%s: unexpected error %v", tt.input, err)
+		}
+		if strings.Join(steps, "\n") != strings.Join(tt.steps, "\n") {
+			t.Errorf("%s: wanted steps\n%s\ngot\n%s", tt.input, strings.Join(tt.steps, "\n"), strings.Join(steps, "\n"))
+		}
+	}
+}
+
+func TestParseStepJSON(t *testing.T) {
+	var steps []ParseStep
+	p := Parser{Trace: func(s ParseStep) {
+		steps = append(steps, s)
+	}}
+	tokens, err := p.Tokenize("1+2")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := p.ReformToRPN(tokens); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	b, err := json.Marshal(steps[len(steps)-2:])
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := `[{"token":"2","output":["1","2"],"operators":["+"]},{"token":null,"output":["1","2","+"],"operators":[]}]`
+	if string(b) != want {
+		t.Errorf("Wanted %s, got %s", want, b)
+	}
+}
@@ -0,0 +1,326 @@
+package plot
+
+import (
+	"bufio"
+	"fmt"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Palette holds the colors of the curves, the first curve gets the first color
+var Palette = []color.RGBA{
+	{R: 0x1f, G: 0x77, B: 0xb4, A: 0xff},
+	{R: 0xd6, G: 0x27, B: 0x28, A: 0xff},
+	{R: 0x2c, G: 0xa0, B: 0x2c, A: 0xff},
+	{R: 0xff, G: 0x7f, B: 0x0e, A: 0xff},
+	{R: 0x94, G: 0x67, B: 0xbd, A: 0xff},
+	{R: 0x8c, G: 0x56, B: 0x4b, A: 0xff},
+}
+
+var (
+	background = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	foreground = color.RGBA{A: 0xff}
+	grid       = color.RGBA{R: 0xe0, G: 0xe0, B: 0xe0, A: 0xff}
+	axis       = color.RGBA{R: 0x80, G: 0x80, B: 0x80, A: 0xff}
+)
+
+// margins around the area of the curves, the labels of the ticks are drawn in them
+const (
+	marginLeft   = 60
+	marginRight  = 15
+	marginTop    = 15
+	marginBottom = 35
+	//charWidth and lineHeight are the size of the characters of basicfont.Face7x13
+	charWidth  = 7
+	lineHeight = 13
+)
+
+// anchor is the part of a text that is at the given position
+type anchor int
+
+const (
+	anchorStart anchor = iota
+	anchorMiddle
+	anchorEnd
+)
+
+// canvas is the target of drawing a plot, x and y are pixels from the top left corner
+type canvas interface {
+	line(x0, y0, x1, y1 float64, c color.RGBA)
+	polyline(points []Point, c color.RGBA)
+	text(x, y float64, s string, a anchor, c color.RGBA)
+}
+
+// Image draws the plot
+func (p *Plot) Image() (*image.RGBA, error) {
+	w, h := p.size()
+	img := &raster{image.NewRGBA(image.Rect(0, 0, w, h))}
+	draw.Draw(img.RGBA, img.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+	if err := p.draw(img); err != nil {
+		return nil, err
+	}
+	return img.RGBA, nil
+}
+
+// PNG writes the plot as a PNG image
+func (p *Plot) PNG(w io.Writer) error {
+	img, err := p.Image()
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, img)
+}
+
+// SVG writes the plot as an SVG image, which can be scaled without getting blurred
+func (p *Plot) SVG(w io.Writer) error {
+	width, height := p.size()
+	s := &svg{}
+	if err := p.draw(s); err != nil {
+		return err
+	}
+	b := bufio.NewWriter(w)
+	fmt.Fprintf(b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		width, height, width, height)
+	fmt.Fprintf(b, `<rect width="100%%" height="100%%" fill="%s"/>`+"\n", hex(background))
+	b.WriteString(s.String())
+	b.WriteString("</svg>\n")
+	return b.Flush()
+}
+
+// draw draws the grid, the axes with their labels, the curves and the legend
+func (p *Plot) draw(c canvas) error {
+	v := p.view()
+	if !v.valid() {
+		return fmt.Errorf("%w: %v", ErrInvalidView, v)
+	}
+	width, height := p.size()
+	if width-marginRight <= marginLeft || height-marginBottom <= marginTop {
+		return fmt.Errorf("%w: %dx%d pixels is too small", ErrInvalidView, width, height)
+	}
+	area := image.Rect(marginLeft, marginTop, width-marginRight, height-marginBottom)
+	toPixel := func(pt Point) Point {
+		return Point{
+			X: float64(area.Min.X) + (pt.X-v.XMin)/(v.XMax-v.XMin)*float64(area.Dx()),
+			Y: float64(area.Max.Y) - (pt.Y-v.YMin)/(v.YMax-v.YMin)*float64(area.Dy()),
+		}
+	}
+	left, right := float64(area.Min.X), float64(area.Max.X)
+	top, bottom := float64(area.Min.Y), float64(area.Max.Y)
+
+	xs, xDecimals := ticks(v.XMin, v.XMax, area.Dx()/80)
+	for _, x := range xs {
+		px := toPixel(Point{X: x}).X
+		c.line(px, top, px, bottom, grid)
+		c.text(px, bottom+lineHeight+4, label(x, xDecimals), anchorMiddle, foreground)
+	}
+	ys, yDecimals := ticks(v.YMin, v.YMax, area.Dy()/50)
+	for _, y := range ys {
+		py := toPixel(Point{Y: y}).Y
+		c.line(left, py, right, py, grid)
+		c.text(left-5, py+lineHeight/2-2, label(y, yDecimals), anchorEnd, foreground)
+	}
+	//the axes are drawn where they are in the view
+	if v.XMin <= 0 && 0 <= v.XMax {
+		px := toPixel(Point{}).X
+		c.line(px, top, px, bottom, axis)
+	}
+	if v.YMin <= 0 && 0 <= v.YMax {
+		py := toPixel(Point{}).Y
+		c.line(left, py, right, py, axis)
+	}
+	c.text(right, bottom+2*lineHeight+6, Variable, anchorEnd, foreground)
+	c.text(left-5, top-2, "y", anchorEnd, foreground)
+	c.line(left, top, right, top, axis)
+	c.line(left, bottom, right, bottom, axis)
+	c.line(left, top, left, bottom, axis)
+	c.line(right, top, right, bottom, axis)
+
+	for i, curve := range p.Curves {
+		stroke := Palette[i%len(Palette)]
+		//a curve without points is left out, Fit reports why
+		segments, _ := p.Sample(curve)
+		for _, segment := range segments {
+			pixels := make([]Point, len(segment))
+			for j, pt := range segment {
+				pixels[j] = toPixel(pt)
+			}
+			for _, clipped := range clip(pixels, left, top, right, bottom) {
+				c.polyline(clipped, stroke)
+			}
+		}
+		y := top + float64(i+1)*(lineHeight+4)
+		c.line(left+8, y-lineHeight/2+1, left+24, y-lineHeight/2+1, stroke)
+		c.text(left+30, y, curve.Expression, anchorStart, foreground)
+	}
+	return nil
+}
+
+// ticks returns round values between min and max, about n of them, and the number of decimals they need
+func ticks(min, max float64, n int) ([]float64, int) {
+	if n < 2 {
+		n = 2
+	}
+	raw := (max - min) / float64(n)
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	step := magnitude
+	for _, f := range []float64{2, 5, 10} {
+		if step >= raw {
+			break
+		}
+		step = f * magnitude
+	}
+	decimals := 0
+	if d := -int(math.Floor(math.Log10(step))); d > 0 {
+		decimals = d
+	}
+	var values []float64
+	for i := math.Ceil(min / step); i*step <= max; i++ {
+		values = append(values, i*step)
+	}
+	return values, decimals
+}
+
+func label(f float64, decimals int) string {
+	s := strconv.FormatFloat(f, 'f', decimals, 64)
+	if strings.Trim(s, "-0.") == "" {
+		//rounding errors would make some zeros negative
+		return strconv.FormatFloat(0, 'f', decimals, 64)
+	}
+	return s
+}
+
+// clip returns the parts of the polyline inside the rectangle
+func clip(points []Point, left, top, right, bottom float64) [][]Point {
+	var parts [][]Point
+	var current []Point
+	for i := 1; i < len(points); i++ {
+		a, b, ok := clipLine(points[i-1], points[i], left, top, right, bottom)
+		if !ok {
+			if len(current) > 0 {
+				parts = append(parts, current)
+				current = nil
+			}
+			continue
+		}
+		if len(current) == 0 || current[len(current)-1] != a {
+			if len(current) > 0 {
+				parts = append(parts, current)
+			}
+			current = []Point{a}
+		}
+		current = append(current, b)
+	}
+	if len(current) > 0 {
+		parts = append(parts, current)
+	}
+	return parts
+}
+
+// clipLine cuts the line from a to b to the rectangle with the algorithm of Liang and Barsky, ok is false if no part
+// of it is inside
+func clipLine(a, b Point, left, top, right, bottom float64) (Point, Point, bool) {
+	dx, dy := b.X-a.X, b.Y-a.Y
+	t0, t1 := 0.0, 1.0
+	for _, edge := range [][2]float64{{-dx, a.X - left}, {dx, right - a.X}, {-dy, a.Y - top}, {dy, bottom - a.Y}} {
+		p, q := edge[0], edge[1]
+		if p == 0 {
+			if q < 0 {
+				return a, b, false
+			}
+			continue
+		}
+		t := q / p
+		if p < 0 {
+			t0 = math.Max(t0, t)
+		} else {
+			t1 = math.Min(t1, t)
+		}
+	}
+	if t0 > t1 {
+		return a, b, false
+	}
+	return Point{X: a.X + t0*dx, Y: a.Y + t0*dy}, Point{X: a.X + t1*dx, Y: a.Y + t1*dy}, true
+}
+
+// raster draws on an image
+type raster struct {
+	*image.RGBA
+}
+
+func (r *raster) line(x0, y0, x1, y1 float64, c color.RGBA) {
+	steps := int(math.Ceil(math.Max(math.Abs(x1-x0), math.Abs(y1-y0))))
+	if steps == 0 {
+		steps = 1
+	}
+	for i := 0; i <= steps; i++ {
+		t := float64(i) / float64(steps)
+		r.SetRGBA(int(math.Round(x0+t*(x1-x0))), int(math.Round(y0+t*(y1-y0))), c)
+	}
+}
+
+// polyline draws curves two pixels wide, so they stand out from the grid
+func (r *raster) polyline(points []Point, c color.RGBA) {
+	for i := 1; i < len(points); i++ {
+		a, b := points[i-1], points[i]
+		r.line(a.X, a.Y, b.X, b.Y, c)
+		r.line(a.X+1, a.Y, b.X+1, b.Y, c)
+		r.line(a.X, a.Y+1, b.X, b.Y+1, c)
+	}
+}
+
+func (r *raster) text(x, y float64, s string, a anchor, c color.RGBA) {
+	x -= float64(utf8.RuneCountInString(s)*charWidth) * float64(a) / 2
+	d := font.Drawer{
+		Dst:  r.RGBA,
+		Src:  image.NewUniform(c),
+		Face: basicfont.Face7x13,
+		Dot:  fixed.P(int(math.Round(x)), int(math.Round(y))),
+	}
+	d.DrawString(s)
+}
+
+// svg collects the elements of an SVG image
+type svg struct {
+	strings.Builder
+}
+
+func (s *svg) line(x0, y0, x1, y1 float64, c color.RGBA) {
+	fmt.Fprintf(s, `<line x1="%s" y1="%s" x2="%s" y2="%s" stroke="%s"/>`+"\n",
+		coordinate(x0), coordinate(y0), coordinate(x1), coordinate(y1), hex(c))
+}
+
+func (s *svg) polyline(points []Point, c color.RGBA) {
+	coordinates := make([]string, len(points))
+	for i, pt := range points {
+		coordinates[i] = coordinate(pt.X) + "," + coordinate(pt.Y)
+	}
+	fmt.Fprintf(s, `<polyline points="%s" fill="none" stroke="%s" stroke-width="2"/>`+"\n",
+		strings.Join(coordinates, " "), hex(c))
+}
+
+func (s *svg) text(x, y float64, text string, a anchor, c color.RGBA) {
+	fmt.Fprintf(s, `<text x="%s" y="%s" text-anchor="%s" font-family="monospace" font-size="12" fill="%s">`,
+		coordinate(x), coordinate(y), [...]string{"start", "middle", "end"}[a], hex(c))
+	_, _ = xmlEscaper.WriteString(s, text)
+	s.WriteString("</text>\n")
+}
+
+var xmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+
+func coordinate(f float64) string {
+	return strconv.FormatFloat(math.Round(f*100)/100, 'f', -1, 64)
+}
+
+func hex(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
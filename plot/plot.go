@@ -0,0 +1,272 @@
+// Package plot draws the graphs of functions of x like "sin(x)/x". Curves are sampled adaptively, so they are smooth
+// where they bend and poles or jumps break the graph instead of being joined by a steep line. Plots are drawn without
+// a window, so they can be exported to SVG and PNG from the command line and in tests.
+package plot
+
+import (
+	"errors"
+	"fmt"
+	"github.com/niklasstich/calculator/evaluation"
+	"github.com/niklasstich/calculator/parser"
+	"github.com/niklasstich/calculator/util"
+	"math"
+	"sort"
+)
+
+var ErrInvalidView = errors.New("invalid view")
+var ErrNoPoints = errors.New("no point of the curves can be evaluated")
+
+// Variable is the name of the variable of the plotted expressions
+const Variable = "x"
+
+const (
+	// DefaultWidth and DefaultHeight are the size of the image in pixels if Plot.Width and Plot.Height are not set
+	DefaultWidth  = 640
+	DefaultHeight = 480
+	// DefaultSamples is the number of evenly spaced points a curve is evaluated at before it is refined, if
+	// Plot.Samples is not set
+	DefaultSamples = 200
+	//maxRefinement is the number of times an interval between two samples is halved at most
+	maxRefinement = 10
+)
+
+// DefaultView is the View of a Plot whose View is not set
+var DefaultView = View{XMin: -10, XMax: 10, YMin: -10, YMax: 10}
+
+// Point is a point of a curve
+type Point struct {
+	X, Y float64
+}
+
+// Curve is the graph of an expression in x
+type Curve struct {
+	// Expression is the text the curve was compiled from, it is shown in the legend
+	Expression string
+	f          *evaluation.Function
+}
+
+// Compile parses the expression as a function of x, the Scope of p provides the user defined functions it may call
+func Compile(p parser.Parser, expression string) (*Curve, error) {
+	def, err := p.ParseFunction("", []string{Variable}, expression)
+	if err != nil {
+		return nil, err
+	}
+	f, err := evaluation.Compile(*def)
+	if err != nil {
+		return nil, err
+	}
+	return &Curve{Expression: def.Source, f: f}, nil
+}
+
+// View is the part of the plane which is shown
+type View struct {
+	XMin, XMax, YMin, YMax float64
+}
+
+// Pan moves the view by fractions of its width and height, Pan(0.1, 0) moves it a tenth of its width to the right
+func (v View) Pan(dx, dy float64) View {
+	dx *= v.XMax - v.XMin
+	dy *= v.YMax - v.YMin
+	return View{XMin: v.XMin + dx, XMax: v.XMax + dx, YMin: v.YMin + dy, YMax: v.YMax + dy}
+}
+
+// Zoom scales the view around its center, a factor larger than 1 zooms in
+func (v View) Zoom(factor float64) View {
+	cx, cy := (v.XMin+v.XMax)/2, (v.YMin+v.YMax)/2
+	w, h := (v.XMax-v.XMin)/factor/2, (v.YMax-v.YMin)/factor/2
+	return View{XMin: cx - w, XMax: cx + w, YMin: cy - h, YMax: cy + h}
+}
+
+func (v View) valid() bool {
+	for _, f := range []float64{v.XMin, v.XMax, v.YMin, v.YMax} {
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return false
+		}
+	}
+	return v.XMin < v.XMax && v.YMin < v.YMax
+}
+
+// Plot holds the curves and the settings of a plot
+type Plot struct {
+	Curves []*Curve
+	// View is the part of the plane which is shown, DefaultView if it is not set
+	View View
+	// Evaluator evaluates the curves, its Env provides the user defined functions they call
+	Evaluator evaluation.Evaluator
+	// Width and Height are the size of the image in pixels, DefaultWidth and DefaultHeight if they are not set
+	Width, Height int
+	// Samples is the number of evenly spaced points each curve is evaluated at first, DefaultSamples if it is not set
+	Samples int
+}
+
+// Sample returns the graph of the curve between XMin and XMax of the View as continuous segments. Where the curve
+// bends more than the View can show, more points are evaluated. Points where the curve is not defined and jumps
+// between neighbouring points are left out, so the graph is broken there. err is the error of the first point which
+// could not be evaluated, it is only returned if no point could be evaluated.
+func (p *Plot) Sample(c *Curve) ([][]Point, error) {
+	v := p.view()
+	if !v.valid() {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidView, v)
+	}
+	_, height := p.size()
+	s := sampler{
+		plot:  p,
+		curve: c,
+		//differences of half a pixel can't be seen
+		tolerance: (v.YMax - v.YMin) / float64(height) / 2,
+		jump:      3 * (v.YMax - v.YMin) / float64(height),
+	}
+	n := p.samples()
+	step := (v.XMax - v.XMin) / float64(n)
+	prev := s.point(v.XMin)
+	s.add(prev)
+	for i := 1; i <= n; i++ {
+		next := s.point(v.XMin + float64(i)*step)
+		s.refine(prev, next, 0)
+		prev = next
+	}
+	s.cut()
+	if len(s.segments) == 0 && s.err != nil {
+		return nil, s.err
+	}
+	return s.segments, nil
+}
+
+// Fit sets YMin and YMax of the View so the curves between XMin and XMax are visible. Poles like those of tan(x)
+// would make everything else flat, so the highest and lowest few percent of the evenly spaced samples are left out.
+func (p *Plot) Fit() error {
+	v := p.view()
+	if !(v.XMin < v.XMax) {
+		return fmt.Errorf("%w: %v", ErrInvalidView, v)
+	}
+	n := p.samples()
+	step := (v.XMax - v.XMin) / float64(n)
+	var ys []float64
+	var err error
+	for _, c := range p.Curves {
+		s := sampler{plot: p, curve: c}
+		for i := 0; i <= n; i++ {
+			if pt := s.point(v.XMin + float64(i)*step); defined(pt.Y) {
+				ys = append(ys, pt.Y)
+			}
+		}
+		if s.err != nil && err == nil {
+			err = fmt.Errorf("%s: %w", c.Expression, s.err)
+		}
+	}
+	if len(ys) == 0 {
+		if err == nil {
+			err = fmt.Errorf("%w between %v and %v", ErrNoPoints, v.XMin, v.XMax)
+		}
+		return err
+	}
+	sort.Float64s(ys)
+	cut := len(ys) / 50
+	low, high := ys[cut], ys[len(ys)-1-cut]
+	if high-low < 1e-9*math.Max(1, math.Abs(low)) {
+		//a constant gets a view of its own size around it
+		margin := math.Max(1, math.Abs(low))
+		low, high = low-margin, high+margin
+	}
+	margin := (high - low) / 10
+	v.YMin, v.YMax = low-margin, high+margin
+	p.View = v
+	return nil
+}
+
+func (p *Plot) view() View {
+	if p.View == (View{}) {
+		return DefaultView
+	}
+	return p.View
+}
+
+func (p *Plot) samples() int {
+	if p.Samples <= 0 {
+		return DefaultSamples
+	}
+	return p.Samples
+}
+
+func (p *Plot) size() (int, int) {
+	w, h := p.Width, p.Height
+	if w <= 0 {
+		w = DefaultWidth
+	}
+	if h <= 0 {
+		h = DefaultHeight
+	}
+	return w, h
+}
+
+// sampler collects the segments of a curve
+type sampler struct {
+	plot  *Plot
+	curve *Curve
+	//tolerance is the distance from a straight line which is still drawn as one, jump is the smallest difference
+	//between two points as close as they get which is a discontinuity
+	tolerance, jump float64
+	segments        [][]Point
+	current         []Point
+	err             error
+}
+
+// point evaluates the curve at x, the y of points where it is not defined is NaN
+func (s *sampler) point(x float64) Point {
+	v, err := s.plot.Evaluator.Call(s.curve.f, util.Token{TokenType: util.TokenTypeOperand, TokenOperand: x})
+	if err != nil {
+		if s.err == nil {
+			s.err = err
+		}
+		return Point{X: x, Y: math.NaN()}
+	}
+	return Point{X: x, Y: v.TokenOperand}
+}
+
+// refine adds the points between a and b and b itself, a was already added
+func (s *sampler) refine(a, b Point, depth int) {
+	mid := s.point((a.X + b.X) / 2)
+	if s.straight(a, mid, b) {
+		s.add(b)
+		return
+	}
+	if depth < maxRefinement {
+		s.refine(a, mid, depth+1)
+		s.refine(mid, b, depth+1)
+		return
+	}
+	if defined(a.Y) && defined(b.Y) && math.Abs(b.Y-a.Y) > s.jump {
+		//the points are as close as they get and the curve still doesn't look straight between them
+		s.cut()
+	}
+	s.add(b)
+}
+
+// straight reports whether the curve from a over mid to b can be drawn as a straight line from a to b
+func (s *sampler) straight(a, mid, b Point) bool {
+	if !defined(a.Y) || !defined(mid.Y) || !defined(b.Y) {
+		//the edge of the domain is searched, unless the curve isn't defined at all
+		return !defined(a.Y) && !defined(mid.Y) && !defined(b.Y)
+	}
+	return math.Abs(mid.Y-(a.Y+b.Y)/2) <= s.tolerance
+}
+
+func (s *sampler) add(pt Point) {
+	if !defined(pt.Y) {
+		s.cut()
+		return
+	}
+	s.current = append(s.current, pt)
+}
+
+// cut ends the current segment, a single point can't be drawn as a line and is dropped
+func (s *sampler) cut() {
+	if len(s.current) > 1 {
+		s.segments = append(s.segments, s.current)
+	}
+	s.current = nil
+}
+
+func defined(y float64) bool {
+	return !math.IsNaN(y) && !math.IsInf(y, 0)
+}
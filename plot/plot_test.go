@@ -0,0 +1,193 @@
+package plot
+
+import (
+	"bytes"
+	"errors"
+	"github.com/niklasstich/calculator/evaluation"
+	"github.com/niklasstich/calculator/parser"
+	"image/png"
+	"math"
+	"strings"
+	"testing"
+)
+
+func compile(t *testing.T, expressions ...string) *Plot {
+	t.Helper()
+	p := &Plot{}
+	for _, expression := range expressions {
+		c, err := Compile(parser.Parser{Scope: &evaluation.Environment{}}, expression)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", expression, err)
+		}
+		p.Curves = append(p.Curves, c)
+	}
+	return p
+}
+
+func TestSample(t *testing.T) {
+	var tests = []struct {
+		expression string
+		view       View
+		//segments is the number of continuous parts of the graph
+		segments int
+		err      error
+	}{
+		{"sin(x) / x", View{-10, 10, -1, 1}, 2, nil},
+		{"x^2", View{-2, 2, 0, 4}, 1, nil},
+		{"1 / (x - 0.3)", View{-2, 2, -10, 10}, 2, nil},
+		{"tan(x)", View{-4, 4, -5, 5}, 3, nil},
+		{"floor(x)", View{0.5, 3.5, 0, 4}, 4, nil},
+		{"sqrt(x)", View{-4, 4, 0, 2}, 1, nil},
+		{"sin(x m)", View{-1, 1, -1, 1}, 0, evaluation.ErrIncompatibleUnits},
+		{"x", View{1, -1, 0, 1}, 0, ErrInvalidView},
+	}
+	for _, tt := range tests {
+		p := compile(t, tt.expression)
+		p.View = tt.view
+		segments, err := p.Sample(p.Curves[0])
+		if !errors.Is(err, tt.err) {
+			t.Fatalf("%s: expected error %v, got %v", tt.expression, tt.err, err)
+		}
+		if len(segments) != tt.segments {
+			t.Errorf("%s: wanted %d segments, got %d", tt.expression, tt.segments, len(segments))
+		}
+		for _, segment := range segments {
+			for i := 1; i < len(segment); i++ {
+				if segment[i].X <= segment[i-1].X {
+					t.Fatalf("%s: points are not in order at %v", tt.expression, segment[i])
+				}
+			}
+		}
+	}
+}
+
+func TestSampleRefines(t *testing.T) {
+	p := compile(t, "x^2")
+	p.View = View{-2, 2, 0, 4}
+	p.Samples = 4
+	segments, err := p.Sample(p.Curves[0])
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	//every point lies on the parabola and the gaps between them are small enough to look smooth
+	for i, pt := range segments[0] {
+		if math.Abs(pt.Y-pt.X*pt.X) > 1e-9 {
+			t.Fatalf("Point %v is not on the curve", pt)
+		}
+		if i > 0 {
+			prev := segments[0][i-1]
+			mid := (prev.X + pt.X) / 2
+			if math.Abs((prev.Y+pt.Y)/2-mid*mid) > 4.0/DefaultHeight {
+				t.Errorf("Gap between %v and %v is too large", prev, pt)
+			}
+		}
+	}
+}
+
+func TestView(t *testing.T) {
+	v := View{-10, 10, -5, 5}
+	if got := v.Pan(0.25, -0.5); got != (View{-5, 15, -10, 0}) {
+		t.Errorf("Unexpected pan %v", got)
+	}
+	if got := v.Zoom(2); got != (View{-5, 5, -2.5, 2.5}) {
+		t.Errorf("Unexpected zoom %v", got)
+	}
+	if got := v.Zoom(0.5); got != (View{-20, 20, -10, 10}) {
+		t.Errorf("Unexpected zoom %v", got)
+	}
+}
+
+func TestFit(t *testing.T) {
+	p := compile(t, "sin(x) / x", "tan(x)")
+	p.View = View{XMin: -10, XMax: 10}
+	if err := p.Fit(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	//the poles of tan(x) are left out
+	if p.View.YMin > -1 || p.View.YMax < 1 || p.View.YMax > 100 || p.View.YMin < -100 {
+		t.Errorf("Unexpected view %v", p.View)
+	}
+	p = compile(t, "sqrt(x)")
+	p.View = View{XMin: -10, XMax: -5}
+	if err := p.Fit(); !errors.Is(err, ErrNoPoints) {
+		t.Errorf("Expected error %v, got %v", ErrNoPoints, err)
+	}
+}
+
+func TestTicks(t *testing.T) {
+	var tests = []struct {
+		min, max float64
+		n        int
+		want     string
+	}{
+		{-10, 10, 4, "-10 -5 0 5 10"},
+		{0.05, 0.5, 5, "0.1 0.2 0.3 0.4 0.5"},
+		{-1.3, 1.3, 6, "-1.0 -0.5 0.0 0.5 1.0"},
+	}
+	for _, tt := range tests {
+		values, decimals := ticks(tt.min, tt.max, tt.n)
+		labels := make([]string, len(values))
+		for i, v := range values {
+			labels[i] = label(v, decimals)
+		}
+		if got := strings.Join(labels, " "); got != tt.want {
+			t.Errorf("%v to %v: wanted %s, got %s", tt.min, tt.max, tt.want, got)
+		}
+	}
+}
+
+func TestExport(t *testing.T) {
+	p := compile(t, "sin(x) / x", "x < 0 ? 1 / x : -1")
+	p.View = View{-10, 10, -1.5, 1.5}
+	p.Width, p.Height = 320, 240
+
+	var svg bytes.Buffer
+	if err := p.SVG(&svg); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	out := svg.String()
+	for _, want := range []string{
+		`<svg xmlns="http://www.w3.org/2000/svg" width="320" height="240"`,
+		`stroke="#1f77b4" stroke-width="2"`,
+		`stroke="#d62728" stroke-width="2"`,
+		`>sin(x) / x</text>`,
+		`>x &lt; 0 ? 1 / x : -1</text>`,
+		`>-1</text>`,
+		`>10</text>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("SVG doesn't contain %s:\n%s", want, out)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := p.PNG(&buf); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 320 || b.Dy() != 240 {
+		t.Errorf("Unexpected size %v", b)
+	}
+	//sin(x)/x is 1 at 0, which is in the middle of the area of the curves
+	x := marginLeft + (320-marginLeft-marginRight)/2
+	y := marginTop + int(math.Round(0.5/3*float64(240-marginTop-marginBottom)))
+	found := false
+	for dx := -2; dx <= 2; dx++ {
+		for dy := -2; dy <= 2; dy++ {
+			if r, g, b, _ := img.At(x+dx, y+dy).RGBA(); r>>8 == 0x1f && g>>8 == 0x77 && b>>8 == 0xb4 {
+				found = true
+			}
+		}
+	}
+	if !found {
+		t.Errorf("Curve is not drawn at %d,%d", x, y)
+	}
+
+	p.Width = 10
+	if err := p.PNG(&buf); !errors.Is(err, ErrInvalidView) {
+		t.Errorf("Expected error %v, got %v", ErrInvalidView, err)
+	}
+}
@@ -0,0 +1,162 @@
+package render
+
+import (
+	"github.com/niklasstich/calculator/util"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// latex writes LaTeX math mode
+type latex struct{}
+
+// latexSymbols are the LaTeX commands of the operators written between or next to their operands
+var latexSymbols = map[util.Op]string{
+	util.OpAddition:       "+",
+	util.OpSubtraction:    "-",
+	util.OpNegation:       "-",
+	util.OpMultiplication: `\cdot`,
+	util.OpModulo:         `\bmod`,
+	util.OpRemainder:      `\operatorname{rem}`,
+	util.OpFactorial:      "!",
+	util.OpPercent:        `\%`,
+	util.OpConversion:     `\to`,
+	util.OpBitwiseNot:     `\sim`,
+	util.OpBitwiseAnd:     `\mathbin{\&}`,
+	util.OpBitwiseOr:      `\mathbin{|}`,
+	util.OpBitwiseXor:     `\oplus`,
+	util.OpShiftLeft:      `\ll`,
+	util.OpShiftRight:     `\gg`,
+	util.OpLess:           "<",
+	util.OpLessEqual:      `\le`,
+	util.OpGreater:        ">",
+	util.OpGreaterEqual:   `\ge`,
+	util.OpEqual:          "=",
+	util.OpNotEqual:       `\ne`,
+	util.OpAnd:            `\land`,
+	util.OpOr:             `\lor`,
+	util.OpNot:            `\lnot`,
+}
+
+// latexDigits protects the separators of the locales, a ',' in math mode would be followed by a space, an apostrophe
+// would be a prime and spaces would be ignored
+var latexDigits = strings.NewReplacer(",", "{,}", "'", `\text{'}`, " ", `\,`)
+
+// latexUnits replaces the Greek letters of unit symbols by their commands
+var latexUnits = strings.NewReplacer("Ω", `\Omega{}`, "µ", `\mu{}`, "μ", `\mu{}`)
+
+// latexConstants are the commands of the constants, other names are written as they are
+var latexConstants = map[string]string{
+	"pi":  `\pi`,
+	"tau": `\tau`,
+}
+
+func (latex) digits(s string) string {
+	return latexDigits.Replace(s)
+}
+
+func (latex) word(s string) string {
+	return `\mathrm{` + s + `}`
+}
+
+// latexText escapes the underscores of time zones like America/Buenos_Aires
+var latexText = strings.NewReplacer("_", `\_`)
+
+func (latex) text(s string) string {
+	return `\text{` + latexText.Replace(s) + `}`
+}
+
+func (latex) identifier(name string) string {
+	if command, ok := latexConstants[name]; ok {
+		return command
+	}
+	if utf8.RuneCountInString(name) == 1 {
+		return name
+	}
+	return `\mathit{` + name + `}`
+}
+
+func (latex) infinity() string {
+	return `\infty`
+}
+
+func (latex) unit(u *util.Unit) string {
+	terms := make([]string, len(u.Terms))
+	for i, t := range u.Terms {
+		terms[i] = `\mathrm{` + latexUnits.Replace(t.Symbol) + `}`
+		if t.Power != 1 {
+			terms[i] += "^{" + strconv.Itoa(t.Power) + "}"
+		}
+	}
+	return strings.Join(terms, `\cdot `)
+}
+
+func (latex) quantity(value, unit string) string {
+	return value + `\,` + unit
+}
+
+func (latex) symbol(o *util.Operator) string {
+	if s, ok := latexSymbols[o.Op]; ok {
+		return s
+	}
+	return o.String()
+}
+
+func (latex) row(parts ...string) string {
+	return strings.Join(parts, " ")
+}
+
+func (latex) prefix(symbol, operand string) string {
+	//a command like \lnot would run into a following letter
+	if c := symbol[len(symbol)-1]; c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' {
+		return symbol + " " + operand
+	}
+	return symbol + operand
+}
+
+func (latex) postfix(operand, symbol string) string {
+	return operand + symbol
+}
+
+func (latex) brackets(s string) string {
+	return `\left(` + s + `\right)`
+}
+
+func (latex) fraction(numerator, denominator string) string {
+	return `\frac{` + numerator + "}{" + denominator + "}"
+}
+
+func (latex) power(base, exponent string) string {
+	return base + "^{" + exponent + "}"
+}
+
+func (latex) floor(s string) string {
+	return `\left\lfloor ` + s + ` \right\rfloor`
+}
+
+func (latex) root(s string) string {
+	return `\sqrt{` + s + "}"
+}
+
+func (l latex) call(name string, args []string) string {
+	if utf8.RuneCountInString(name) > 1 {
+		name = `\operatorname{` + name + "}"
+	}
+	return name + l.brackets(strings.Join(args, ", "))
+}
+
+func (latex) list(elements []string) string {
+	return `\left[` + strings.Join(elements, ", ") + `\right]`
+}
+
+func (latex) cases(condition, then, otherwise string) string {
+	return `\begin{cases} ` + then + ` & \text{if } ` + condition + ` \\ ` + otherwise + ` & \text{otherwise} \end{cases}`
+}
+
+func (latex) scientific(mantissa, exponent string) string {
+	return mantissa + ` \times 10^{` + exponent + "}"
+}
+
+func (latex) document(s string) string {
+	return s
+}
@@ -0,0 +1,145 @@
+package render
+
+import (
+	"github.com/niklasstich/calculator/util"
+	"html"
+	"strconv"
+	"strings"
+)
+
+// mathML writes presentation MathML
+type mathML struct{}
+
+// mathMLSymbols are the characters of the operators written between or next to their operands, if they aren't
+// written as in the input
+var mathMLSymbols = map[util.Op]string{
+	util.OpSubtraction:    "−",
+	util.OpNegation:       "−",
+	util.OpMultiplication: "⋅",
+	util.OpConversion:     "→",
+	util.OpBitwiseXor:     "⊕",
+	util.OpShiftLeft:      "≪",
+	util.OpShiftRight:     "≫",
+	util.OpLessEqual:      "≤",
+	util.OpGreaterEqual:   "≥",
+	util.OpEqual:          "=",
+	util.OpNotEqual:       "≠",
+	util.OpAnd:            "∧",
+	util.OpOr:             "∨",
+	util.OpNot:            "¬",
+}
+
+// mathMLConstants are the characters of the constants, other names are written as they are
+var mathMLConstants = map[string]string{
+	"pi":  "π",
+	"tau": "τ",
+}
+
+func (mathML) digits(s string) string {
+	return "<mn>" + html.EscapeString(s) + "</mn>"
+}
+
+func (mathML) word(s string) string {
+	return "<mtext>" + html.EscapeString(s) + "</mtext>"
+}
+
+func (mathML) text(s string) string {
+	return "<mtext>" + html.EscapeString(s) + "</mtext>"
+}
+
+func (mathML) identifier(name string) string {
+	if c, ok := mathMLConstants[name]; ok {
+		name = c
+	}
+	return "<mi>" + html.EscapeString(name) + "</mi>"
+}
+
+func (mathML) infinity() string {
+	return "<mi>∞</mi>"
+}
+
+func (m mathML) unit(u *util.Unit) string {
+	terms := make([]string, len(u.Terms))
+	for i, t := range u.Terms {
+		terms[i] = `<mi mathvariant="normal">` + html.EscapeString(t.Symbol) + "</mi>"
+		if t.Power != 1 {
+			exponent := m.digits(strconv.Itoa(t.Power))
+			if t.Power < 0 {
+				exponent = m.prefix("<mo>−</mo>", m.digits(strconv.Itoa(-t.Power)))
+			}
+			terms[i] = m.power(terms[i], exponent)
+		}
+	}
+	if len(terms) == 1 {
+		return terms[0]
+	}
+	return "<mrow>" + strings.Join(terms, "<mo>⋅</mo>") + "</mrow>"
+}
+
+func (m mathML) quantity(value, unit string) string {
+	return m.row(value, `<mspace width="0.167em"/>`, unit)
+}
+
+func (mathML) symbol(o *util.Operator) string {
+	s, ok := mathMLSymbols[o.Op]
+	if !ok {
+		s = o.String()
+	}
+	return "<mo>" + html.EscapeString(s) + "</mo>"
+}
+
+func (mathML) row(parts ...string) string {
+	return "<mrow>" + strings.Join(parts, "") + "</mrow>"
+}
+
+func (m mathML) prefix(symbol, operand string) string {
+	return m.row(symbol, operand)
+}
+
+func (m mathML) postfix(operand, symbol string) string {
+	return m.row(operand, symbol)
+}
+
+func (m mathML) brackets(s string) string {
+	return m.row("<mo>(</mo>", s, "<mo>)</mo>")
+}
+
+func (mathML) fraction(numerator, denominator string) string {
+	return "<mfrac>" + numerator + denominator + "</mfrac>"
+}
+
+func (mathML) power(base, exponent string) string {
+	return "<msup>" + base + exponent + "</msup>"
+}
+
+func (m mathML) floor(s string) string {
+	return m.row("<mo>⌊</mo>", s, "<mo>⌋</mo>")
+}
+
+func (mathML) root(s string) string {
+	return "<msqrt>" + s + "</msqrt>"
+}
+
+func (m mathML) call(name string, args []string) string {
+	//U+2061 is the invisible function application operator
+	return m.row(m.identifier(name), "<mo>&#x2061;</mo>", m.brackets(strings.Join(args, "<mo>,</mo>")))
+}
+
+func (m mathML) list(elements []string) string {
+	return m.row("<mo>[</mo>", strings.Join(elements, "<mo>,</mo>"), "<mo>]</mo>")
+}
+
+func (m mathML) cases(condition, then, otherwise string) string {
+	return m.row("<mo>{</mo>", "<mtable>"+
+		"<mtr><mtd>"+then+"</mtd><mtd><mtext>if </mtext>"+condition+"</mtd></mtr>"+
+		"<mtr><mtd>"+otherwise+"</mtd><mtd><mtext>otherwise</mtext></mtd></mtr>"+
+		"</mtable>")
+}
+
+func (m mathML) scientific(mantissa, exponent string) string {
+	return m.row(mantissa, "<mo>×</mo>", m.power("<mn>10</mn>", exponent))
+}
+
+func (mathML) document(s string) string {
+	return `<math xmlns="http://www.w3.org/1998/Math/MathML">` + s + "</math>"
+}
@@ -0,0 +1,331 @@
+// Package render turns expressions and their results into LaTeX and presentation MathML, so calculations can be
+// embedded in documents. Unlike the infix notation of parser.Printer the output is meant to be read by people, so
+// divisions become fractions, exponents are superscripts and the square root of a call to sqrt gets a radical sign.
+package render
+
+import (
+	"errors"
+	"fmt"
+	"github.com/niklasstich/calculator/format"
+	"github.com/niklasstich/calculator/parser"
+	"github.com/niklasstich/calculator/util"
+	"math"
+	"strings"
+)
+
+var ErrInvalidExpression = errors.New("expression can't be rendered")
+var ErrUnknownFormat = errors.New("unknown output format")
+
+// Renderer holds the settings used to render, the zero value formats results with the zero value of format.Formatter
+type Renderer struct {
+	Formatter format.Formatter
+}
+
+// markup writes the parts of an expression in one output format, the walk over the expression is the same for all
+// formats and decides where brackets are needed. Every method returns a single element, so the results can be nested.
+type markup interface {
+	// digits is a number without sign, it may contain the separators of the Locale
+	digits(s string) string
+	// word is upright text like true or a hexadecimal literal
+	word(s string) string
+	// text is a value written like plain text with spaces, like a date
+	text(s string) string
+	identifier(name string) string
+	infinity() string
+	unit(u *util.Unit) string
+	quantity(value, unit string) string
+	symbol(o *util.Operator) string
+	// row puts the parts next to each other, like the operands and the symbol of a binary operator
+	row(parts ...string) string
+	prefix(symbol, operand string) string
+	postfix(operand, symbol string) string
+	brackets(s string) string
+	fraction(numerator, denominator string) string
+	power(base, exponent string) string
+	floor(s string) string
+	root(s string) string
+	call(name string, args []string) string
+	list(elements []string) string
+	cases(condition, then, otherwise string) string
+	scientific(mantissa, exponent string) string
+	// document wraps a whole expression so it can be embedded
+	document(s string) string
+}
+
+// node is a rendered subexpression, precedence is that of its outermost operator like in parser.Printer
+type node struct {
+	text       string
+	precedence float64
+	//fraction is set for nodes written as a fraction, they need brackets as base of a power or before a postfix operator
+	fraction bool
+}
+
+// atom is the precedence of nodes which never need brackets
+const atom = math.MaxFloat64
+
+// operator precedences the renderer needs besides those of the operators in the expression
+const (
+	negationPrecedence       = 3
+	multiplicationPrecedence = 2
+)
+
+// LaTeX renders the expression in LaTeX math mode without delimiters like $, followed by "= result" if result is not
+// nil. Function names of more than one letter and the modulo operators need the amsmath package.
+func (r Renderer) LaTeX(expression parser.RPNExpression, result *util.Token) (string, error) {
+	return r.render(latex{}, expression, result)
+}
+
+// MathML renders the expression as a math element of presentation MathML, followed by "= result" if result is not nil
+func (r Renderer) MathML(expression parser.RPNExpression, result *util.Token) (string, error) {
+	return r.render(mathML{}, expression, result)
+}
+
+func (r Renderer) render(m markup, expression parser.RPNExpression, result *util.Token) (string, error) {
+	n, err := r.expression(m, expression)
+	if err != nil {
+		return "", err
+	}
+	if result == nil {
+		return m.document(n.text), nil
+	}
+	return m.document(m.row(n.text, m.symbol(equals), r.result(m, result).text)), nil
+}
+
+// equals is printed between the expression and its result
+var equals = &util.Operator{Name: "==", Op: util.OpEqual}
+
+// expression walks the RPN like a stack machine, but creates markup instead of values
+func (r Renderer) expression(m markup, expression parser.RPNExpression) (node, error) {
+	stack := make([]node, 0, len(expression))
+	pop := func(n int) []node {
+		operands := make([]node, n)
+		copy(operands, stack[len(stack)-n:])
+		stack = stack[:len(stack)-n]
+		return operands
+	}
+	for _, t := range expression {
+		if t.TokenType == util.TokenTypeOperand {
+			stack = append(stack, operand(m, t))
+			continue
+		}
+		o := t.TokenOperator
+		if o == nil || o.Bracket || o.Op == util.OpArgumentSeparator || o.Op == util.OpCondition {
+			return node{}, fmt.Errorf("%w: unexpected '%v'", ErrInvalidExpression, t)
+		}
+		if len(stack) < o.Arity() {
+			return node{}, fmt.Errorf("%w: not enough operands for '%v'", ErrInvalidExpression, t)
+		}
+		var n node
+		switch {
+		case o.Op == util.OpCall:
+			args := pop(o.Arguments)
+			if o.Name == "sqrt" && len(args) == 1 {
+				n = node{text: m.root(args[0].text), precedence: atom}
+				break
+			}
+			texts := make([]string, len(args))
+			for i, arg := range args {
+				texts[i] = arg.text
+			}
+			n = node{text: m.call(o.Name, texts), precedence: atom}
+		case o.Op == util.OpList:
+			elements := pop(o.Arguments)
+			texts := make([]string, len(elements))
+			for i, element := range elements {
+				texts[i] = element.text
+			}
+			n = node{text: m.list(texts), precedence: atom}
+		case o.Op == util.OpTernary:
+			//the alternatives are delimited by the cases, so nothing inside needs brackets
+			args := pop(3)
+			n = node{text: m.cases(args[0].text, args[1].text, args[2].text), precedence: atom}
+		case o.Op == util.OpDivision:
+			args := pop(2)
+			n = node{text: m.fraction(args[0].text, args[1].text), precedence: atom, fraction: true}
+		case o.Op == util.OpIntegerDivision:
+			args := pop(2)
+			n = node{text: m.floor(m.fraction(args[0].text, args[1].text)), precedence: atom}
+		case o.Op == util.OpExponentiation:
+			args := pop(2)
+			base := group(m, args[0], args[0].precedence <= o.Precedence || args[0].fraction)
+			n = node{text: m.power(base, args[1].text), precedence: o.Precedence}
+		case o.Op == util.OpFactorial || o.Op == util.OpPercent:
+			operand := pop(1)[0]
+			n = node{
+				text:       m.postfix(group(m, operand, operand.precedence < o.Precedence || operand.fraction), m.symbol(o)),
+				precedence: o.Precedence,
+			}
+		case o.Unary:
+			operand := pop(1)[0]
+			n = node{
+				text:       m.prefix(m.symbol(o), group(m, operand, operand.precedence < o.Precedence)),
+				precedence: o.Precedence,
+			}
+		default:
+			args := pop(2)
+			left, right := args[0], args[1]
+			//implicit multiplications with high precedence are written like any other
+			precedence := o.Precedence
+			if o.Op == util.OpMultiplication {
+				precedence = multiplicationPrecedence
+			}
+			leftNeeded := left.precedence < precedence || left.precedence == precedence && !o.LeftAssociative
+			rightNeeded := right.precedence < precedence || right.precedence == precedence && o.LeftAssociative
+			n = node{
+				text:       m.row(group(m, left, leftNeeded), m.symbol(o), group(m, right, rightNeeded)),
+				precedence: precedence,
+			}
+		}
+		stack = append(stack, n)
+	}
+	if len(stack) != 1 {
+		return node{}, fmt.Errorf("%w: %d results instead of 1", ErrInvalidExpression, len(stack))
+	}
+	return stack[0], nil
+}
+
+// operand renders a single operand of an expression
+func operand(m markup, t util.Token) node {
+	switch {
+	case t.TokenName != "":
+		return node{text: m.identifier(t.TokenName), precedence: atom}
+	case t.TokenBoolean:
+		return node{text: m.word(t.String()), precedence: atom}
+	case t.TokenTime != nil, t.TokenZone != nil:
+		return node{text: m.text(t.String()), precedence: atom}
+	case t.TokenUnit != nil && t.TokenLiteral == "" && t.TokenInteger == nil && t.TokenOperand == 1:
+		//a unit on its own
+		return node{text: m.unit(t.TokenUnit), precedence: atom}
+	}
+	var n node
+	if name, ok := parser.ConstantName(t.TokenLiteral); ok {
+		n = node{text: m.identifier(name), precedence: atom}
+	} else if t.TokenLiteral != "" {
+		n = number(m, t.TokenLiteral)
+	} else if t.TokenInteger != nil {
+		//only literals in other bases than 10 have no TokenLiteral
+		n = number(m, t.TokenInteger.Format(16))
+	} else {
+		value := t
+		value.TokenUnit = nil
+		n = number(m, value.String())
+	}
+	return withUnit(m, n, t.TokenUnit)
+}
+
+// result renders the result of an evaluation, formatted by the Formatter of the Renderer
+func (r Renderer) result(m markup, t *util.Token) node {
+	if t.TokenList != nil {
+		texts := make([]string, len(t.TokenList))
+		for i := range t.TokenList {
+			texts[i] = r.result(m, &t.TokenList[i]).text
+		}
+		return node{text: m.list(texts), precedence: atom}
+	}
+	if t.TokenBoolean {
+		return node{text: m.word(t.String()), precedence: atom}
+	}
+	if t.TokenTime != nil || t.TokenZone != nil {
+		return node{text: m.text(t.String()), precedence: atom}
+	}
+	value := *t
+	value.TokenUnit = nil
+	return withUnit(m, number(m, r.Formatter.Format(&value)), t.TokenUnit)
+}
+
+func withUnit(m markup, n node, unit *util.Unit) node {
+	if unit == nil {
+		return n
+	}
+	//"2 km" binds like a multiplication
+	n.text = m.quantity(group(m, n, n.precedence < multiplicationPrecedence), m.unit(unit))
+	if n.precedence > multiplicationPrecedence {
+		n.precedence = multiplicationPrecedence
+	}
+	n.fraction = false
+	return n
+}
+
+// number renders a number as printed by strconv or format.Formatter, which may have a sign, an exponent, another base
+// or be a fraction
+func number(m markup, s string) node {
+	if strings.HasPrefix(s, "-") {
+		n := number(m, s[1:])
+		return node{
+			text:       m.prefix(m.symbol(&util.Operator{Op: util.OpNegation}), group(m, n, n.precedence < negationPrecedence)),
+			precedence: negationPrecedence,
+		}
+	}
+	s = strings.TrimPrefix(s, "+")
+	switch {
+	case s == "Inf":
+		return node{text: m.infinity(), precedence: atom}
+	case s == "NaN" || len(s) > 1 && s[0] == '0' && strings.IndexByte("xob", s[1]) >= 0:
+		return node{text: m.word(s), precedence: atom}
+	}
+	if i := strings.IndexByte(s, '/'); i >= 0 {
+		return node{text: m.fraction(m.digits(s[:i]), m.digits(s[i+1:])), precedence: atom, fraction: true}
+	}
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		exponent := strings.TrimPrefix(s[i+1:], "+")
+		//strconv pads the exponent to two digits
+		digits := strings.TrimLeft(strings.TrimPrefix(exponent, "-"), "0")
+		if digits == "" {
+			digits = "0"
+		}
+		exp := m.digits(digits)
+		if strings.HasPrefix(exponent, "-") {
+			exp = m.prefix(m.symbol(&util.Operator{Op: util.OpNegation}), exp)
+		}
+		return node{text: m.scientific(m.digits(s[:i]), exp), precedence: multiplicationPrecedence}
+	}
+	return node{text: m.digits(s), precedence: atom}
+}
+
+// group returns the text of the node, in brackets if needed
+func group(m markup, n node, needed bool) string {
+	if needed {
+		return m.brackets(n.text)
+	}
+	return n.text
+}
+
+// Format is one of the output formats of Render
+type Format int
+
+const (
+	// FormatText is the plain text of parser.Printer and format.Formatter
+	FormatText Format = iota
+	FormatLaTeX
+	FormatMathML
+)
+
+var formatNames = map[string]Format{
+	"text":   FormatText,
+	"latex":  FormatLaTeX,
+	"mathml": FormatMathML,
+}
+
+// ParseFormat returns the Format with the given name, which is text, latex or mathml
+func ParseFormat(name string) (Format, error) {
+	f, ok := formatNames[strings.ToLower(name)]
+	if !ok {
+		return FormatText, fmt.Errorf("%w: %s", ErrUnknownFormat, name)
+	}
+	return f, nil
+}
+
+// Render renders the expression followed by "= result" in the Format. FormatText only prints the result unless it is
+// nil, like the command line does.
+func (r Renderer) Render(f Format, expression parser.RPNExpression, result *util.Token) (string, error) {
+	switch f {
+	case FormatLaTeX:
+		return r.LaTeX(expression, result)
+	case FormatMathML:
+		return r.MathML(expression, result)
+	}
+	if result != nil {
+		return r.Formatter.Format(result), nil
+	}
+	return parser.ReformToInfix(expression)
+}
@@ -0,0 +1,191 @@
+package render
+
+import (
+	"errors"
+	"github.com/niklasstich/calculator/format"
+	"github.com/niklasstich/calculator/parser"
+	"github.com/niklasstich/calculator/util"
+	"math"
+	"testing"
+	"time"
+)
+
+func parse(t *testing.T, input string) parser.RPNExpression {
+	p := parser.Parser{Scope: testScope{}}
+	tokens, err := p.Tokenize(input)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	rpn, err := p.ReformToRPN(tokens)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return rpn
+}
+
+// testScope makes every name a function
+type testScope struct{}
+
+func (testScope) IsFunction(string) bool {
+	return true
+}
+
+func TestLaTeX(t *testing.T) {
+	var tests = []struct {
+		input, want string
+	}{
+		{"1 + 2 * 3", `1 + 2 \cdot 3`},
+		{"(1 + 2) * 3", `\left(1 + 2\right) \cdot 3`},
+		{"1 - (2 - 3)", `1 - \left(2 - 3\right)`},
+		{"(1 + 2) / (3 - 4)", `\frac{1 + 2}{3 - 4}`},
+		{"1 / 2 / 3", `\frac{\frac{1}{2}}{3}`},
+		{"(1/2)^2", `\left(\frac{1}{2}\right)^{2}`},
+		{"2^(1 + 2)", `2^{1 + 2}`},
+		{"(2^3)^4", `\left(2^{3}\right)^{4}`},
+		{"-2^2", `-2^{2}`},
+		{"(-2)^2", `\left(-2\right)^{2}`},
+		{"2^-1", `2^{-1}`},
+		{"sqrt(2) * pi", `\sqrt{2} \cdot \pi`},
+		{"max(1, tau) + e", `\operatorname{max}\left(1, \tau\right) + e`},
+		{"f(2)", `f\left(2\right)`},
+		{"[1, 2] / 2", `\frac{\left[1, 2\right]}{2}`},
+		{"2026-12-24 UTC - now", `\text{2026-12-24 UTC} - \mathit{now}`},
+		{"7 // 2", `\left\lfloor \frac{7}{2} \right\rfloor`},
+		{"7 mod 2 rem 3", `7 \bmod 2 \operatorname{rem} 3`},
+		{"(1 + 2)!", `\left(1 + 2\right)!`},
+		{"200 + 10%", `200 + 10\%`},
+		{"1.5e-3", `1.5 \times 10^{-3}`},
+		{"(1e3)^2", `\left(1 \times 10^{3}\right)^{2}`},
+		{"0x1F & ~3", `\mathrm{0x1f} \mathbin{\&} \sim 3`},
+		{"5 km^2 + 3 m*km in ha", `5\,\mathrm{km}^{2} + 3\,\mathrm{m} \cdot \mathrm{km} \to \mathrm{ha}`},
+		{"3 Ω", `3\,\mathrm{\Omega{}}`},
+		{"not true || 1 <= 2", `\lnot \mathrm{true} \lor 1 \le 2`},
+		{"1 < 2 ? 3 : 4", `\begin{cases} 3 & \text{if } 1 < 2 \\ 4 & \text{otherwise} \end{cases}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := Renderer{}.LaTeX(parse(t, tt.input), nil)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestMathML(t *testing.T) {
+	var tests = []struct {
+		input, want string
+	}{
+		{"1 + 2 * 3", `<mrow><mn>1</mn><mo>+</mo><mrow><mn>2</mn><mo>⋅</mo><mn>3</mn></mrow></mrow>`},
+		{"(1 - 2) / 3", `<mfrac><mrow><mn>1</mn><mo>−</mo><mn>2</mn></mrow><mn>3</mn></mfrac>`},
+		{"-2^2", `<mrow><mo>−</mo><msup><mn>2</mn><mn>2</mn></msup></mrow>`},
+		{"(1/2)^2", `<msup><mrow><mo>(</mo><mfrac><mn>1</mn><mn>2</mn></mfrac><mo>)</mo></mrow><mn>2</mn></msup>`},
+		{"sqrt(1 + pi)", `<msqrt><mrow><mn>1</mn><mo>+</mo><mi>π</mi></mrow></msqrt>`},
+		{"f(1, pi)", `<mrow><mi>f</mi><mo>&#x2061;</mo><mrow><mo>(</mo><mn>1</mn><mo>,</mo><mi>π</mi><mo>)</mo></mrow></mrow>`},
+		{"1 < 2 && 3 >= 4", `<mrow><mrow><mn>1</mn><mo>&lt;</mo><mn>2</mn></mrow><mo>∧</mo><mrow><mn>3</mn><mo>≥</mo><mn>4</mn></mrow></mrow>`},
+		{"3 m^-1", `<mrow><mn>3</mn><mspace width="0.167em"/><msup><mi mathvariant="normal">m</mi><mrow><mo>−</mo><mn>1</mn></mrow></msup></mrow>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := Renderer{}.MathML(parse(t, tt.input), nil)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			want := `<math xmlns="http://www.w3.org/1998/Math/MathML">` + tt.want + `</math>`
+			if got != want {
+				t.Errorf("Expected %s, got %s", want, got)
+			}
+		})
+	}
+}
+
+func TestResult(t *testing.T) {
+	km := &util.Unit{Terms: []util.UnitTerm{{Symbol: "km", Power: 1}}}
+	christmas := time.Date(2026, 12, 24, 18, 0, 0, 0, time.UTC)
+	var tests = []struct {
+		formatter format.Formatter
+		result    util.Token
+		latex     string
+		mathML    string
+	}{
+		{format.Formatter{}, util.Token{TokenOperand: 1.5}, `1.5`, `<mn>1.5</mn>`},
+		{format.Formatter{}, util.Token{TokenOperand: -2, TokenUnit: km}, `-2\,\mathrm{km}`,
+			`<mrow><mrow><mo>−</mo><mn>2</mn></mrow><mspace width="0.167em"/><mi mathvariant="normal">km</mi></mrow>`},
+		{format.Formatter{Mode: format.ModeFraction}, util.Token{TokenOperand: -0.75}, `-\frac{3}{4}`,
+			`<mrow><mo>−</mo><mfrac><mn>3</mn><mn>4</mn></mfrac></mrow>`},
+		{format.Formatter{Mode: format.ModeScientific, Precision: 2}, util.Token{TokenOperand: 12345}, `1.23 \times 10^{4}`,
+			`<mrow><mn>1.23</mn><mo>×</mo><msup><mn>10</mn><mn>4</mn></msup></mrow>`},
+		{format.Formatter{Locale: util.LocaleGerman}, util.Token{TokenOperand: 1.5}, `1{,}5`, `<mn>1,5</mn>`},
+		{format.Formatter{Locale: util.LocaleSwiss, Grouping: true}, util.Token{TokenOperand: 1234}, `1\text{'}234`,
+			`<mn>1&#39;234</mn>`},
+		{format.Formatter{}, util.Token{TokenBoolean: true, TokenOperand: 1}, `\mathrm{true}`, `<mtext>true</mtext>`},
+		{format.Formatter{}, util.Token{TokenOperand: math.Inf(1)}, `\infty`, `<mi>∞</mi>`},
+		{format.Formatter{}, util.Token{TokenTime: &christmas}, `\text{2026-12-24 18:00 UTC}`,
+			`<mtext>2026-12-24 18:00 UTC</mtext>`},
+		{format.Formatter{}, util.Token{TokenList: []util.Token{{TokenOperand: 1}, {TokenOperand: 2, TokenUnit: km}}},
+			`\left[1, 2\,\mathrm{km}\right]`, `<mrow><mo>[</mo><mn>1</mn><mo>,</mo><mrow><mn>2</mn>` +
+				`<mspace width="0.167em"/><mi mathvariant="normal">km</mi></mrow><mo>]</mo></mrow>`},
+	}
+
+	two := parse(t, "2")
+	for _, tt := range tests {
+		t.Run(tt.latex, func(t *testing.T) {
+			r := Renderer{Formatter: tt.formatter}
+			got, err := r.LaTeX(two, &tt.result)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if want := "2 = " + tt.latex; got != want {
+				t.Errorf("Expected %s, got %s", want, got)
+			}
+			got, err = r.MathML(two, &tt.result)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			want := `<math xmlns="http://www.w3.org/1998/Math/MathML"><mrow><mn>2</mn><mo>=</mo>` + tt.mathML +
+				`</mrow></math>`
+			if got != want {
+				t.Errorf("Expected %s, got %s", want, got)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	rpn := parse(t, "1/2")
+	result := util.Token{TokenOperand: 0.5}
+	var tests = []struct {
+		format string
+		result *util.Token
+		want   string
+	}{
+		{"text", &result, "0.5"},
+		{"Text", nil, "1 / 2"},
+		{"latex", &result, `\frac{1}{2} = 0.5`},
+		{"mathml", nil, `<math xmlns="http://www.w3.org/1998/Math/MathML"><mfrac><mn>1</mn><mn>2</mn></mfrac></math>`},
+	}
+	for _, tt := range tests {
+		f, err := ParseFormat(tt.format)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		got, err := Renderer{}.Render(f, rpn, tt.result)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.format, tt.want, got)
+		}
+	}
+	if _, err := ParseFormat("html"); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("Expected error %v, got %v", ErrUnknownFormat, err)
+	}
+	if _, err := (Renderer{}).LaTeX(rpn[:2], nil); !errors.Is(err, ErrInvalidExpression) {
+		t.Errorf("Expected error %v, got %v", ErrInvalidExpression, err)
+	}
+}
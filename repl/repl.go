@@ -0,0 +1,446 @@
+// Package repl implements the interactive command line of the calculator. Every line is either a command starting
+// with ':' or statements as described in package script, like a function definition "f(x) = x^2" or an expression.
+package repl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"github.com/niklasstich/calculator/evaluation"
+	"github.com/niklasstich/calculator/format"
+	"github.com/niklasstich/calculator/history"
+	"github.com/niklasstich/calculator/memory"
+	"github.com/niklasstich/calculator/parser"
+	"github.com/niklasstich/calculator/render"
+	"github.com/niklasstich/calculator/rpn"
+	"github.com/niklasstich/calculator/script"
+	"github.com/niklasstich/calculator/table"
+	"github.com/niklasstich/calculator/util"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var ErrUnknownCommand = errors.New("unknown command")
+var ErrInvalidSetting = errors.New("invalid setting")
+
+var errNoResult = errors.New("there is no result to store")
+
+// errQuit is returned by the :quit command to end Run
+var errQuit = errors.New("quit")
+
+const help = `Enter an expression to evaluate it, or define a function like f(x, y) = x^2 + y^2.
+Commands:
+  :functions      list all defined functions
+  :delete NAME    delete a function
+  :output FORMAT  print results as text, latex or mathml
+  :mode MODE      evaluate with float numbers and units, integer [TYPE] like integer uint8, or big [BITS] with
+                  arbitrary precision
+  :base BASE      print integer results in base 2, 8, 10 or 16
+  :locale LOCALE  read and print numbers in the locale en, de, fr or ch
+  :percent MODE   take percentages after + and - of the left operand (calculator) or always divide by 100 (divide)
+  :history [TEXT] list the calculations containing TEXT, use $N or ans for their results
+  :history clear  delete the history
+  :m+ [NAME]      add the last result to a memory register, M if no NAME is given
+  :m- [NAME]      subtract the last result from a memory register
+  :ms [NAME]      store the last result in a memory register
+  :mr [NAME]      show the value of a memory register, it can be used in expressions by its name
+  :mc [NAME]      clear a memory register
+  :memory         list all memory registers
+  :trace EXPR     show the steps of parsing and evaluating an expression
+  :table [FORMAT] FORMULA for VAR from START to END [step STEP] [and VAR from ...]
+                  print a table of the formula as markdown, csv or json, like :table x^2 for x from 0 to 5
+  :amortize [FORMAT] RATE, NPER, PV[, FV[, TYPE]]
+                  print the amortization schedule of a loan, like :amortize 5%/12, 12*10, 200000
+  :rpn            switch between expressions and the RPN stack, where every line is a list of values, operators
+                  and the commands swap, drop, dup, roll, neg, clear and undo; an empty line duplicates the top
+  :help           show this help
+  :quit           exit`
+
+// REPL reads lines, evaluates them and prints the results. The settings of Parser, Evaluator and Formatter can be
+// changed between lines.
+type REPL struct {
+	Parser    parser.Parser
+	Evaluator evaluation.Evaluator
+	Formatter format.Formatter
+	// Output is the format results are printed in, the expression is printed with them in LaTeX and MathML
+	Output render.Format
+	Env    *evaluation.Environment
+	// History records every expression and makes its result available as ans and $N, nothing is recorded if it is nil
+	History *history.History
+	// Memory holds the memory registers, they are variables of Env
+	Memory *memory.Registers
+	// RPN passes the lines to Stack instead of evaluating them as expressions
+	RPN   bool
+	Stack *rpn.Stack
+
+	//last is the last result, which the memory commands use
+	last *util.Token
+}
+
+// New returns a REPL with an empty Environment that is used for all lines and memory registers which are not stored
+func New() *REPL {
+	env := &evaluation.Environment{}
+	return &REPL{Env: env, Memory: memory.Load(nil, env), Stack: &rpn.Stack{}}
+}
+
+// Run reads lines from in until it ends or :quit is entered and writes a prompt and the output of each line to out.
+// Errors of single lines are printed, only errors reading in or writing out are returned.
+func (r *REPL) Run(in io.Reader, out io.Writer) error {
+	scanner := bufio.NewScanner(in)
+	for {
+		if _, err := fmt.Fprint(out, "> "); err != nil {
+			return err
+		}
+		if !scanner.Scan() {
+			return scanner.Err()
+		}
+		res, err := r.Execute(scanner.Text())
+		if errors.Is(err, errQuit) {
+			return nil
+		}
+		if err != nil {
+			res = "error: " + err.Error()
+		}
+		if res == "" {
+			continue
+		}
+		if _, err := fmt.Fprintln(out, res); err != nil {
+			return err
+		}
+	}
+}
+
+// Execute runs a single line and returns its output, the line may contain several statements separated by ';'
+func (r *REPL) Execute(line string) (string, error) {
+	line = strings.TrimSpace(line)
+	if strings.HasPrefix(line, ":") {
+		return r.command(strings.Fields(line[1:]))
+	}
+	if r.RPN {
+		return r.enter(line)
+	}
+	if line == "" {
+		return "", nil
+	}
+	runner := script.Runner{Parser: r.Parser, Evaluator: r.Evaluator, Env: r.Env}
+	//a failure to store the history doesn't stop the line, it is reported after the results
+	var historyErr error
+	if r.History != nil {
+		runner.Record = func(res script.Result) {
+			if _, err := r.History.Add(res.Text, res.Value, res.Err); err != nil && historyErr == nil {
+				historyErr = err
+			}
+		}
+	}
+	output, err := r.output(runner.Run(line))
+	if historyErr != nil {
+		output = strings.TrimPrefix(output+"\nerror: history: "+historyErr.Error(), "\n")
+	}
+	return output, err
+}
+
+// output returns the lines printed for the results of a line
+func (r *REPL) output(results []script.Result) (string, error) {
+	if len(results) == 1 && results[0].Err != nil {
+		return "", results[0].Err
+	}
+	//a line with several statements prints one line for each of them
+	lines := make([]string, 0, len(results))
+	for _, res := range results {
+		switch {
+		case res.Err != nil:
+			lines = append(lines, "error: "+res.Err.Error())
+		case res.Definition != nil:
+			lines = append(lines, res.Definition.Source)
+		default:
+			r.last = res.Value
+			output, err := render.Renderer{Formatter: r.Formatter}.Render(r.Output, res.Expression, res.Value)
+			if err != nil {
+				output = "error: " + err.Error()
+			}
+			lines = append(lines, output)
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func (r *REPL) command(args []string) (string, error) {
+	if len(args) == 0 {
+		return "", fmt.Errorf("%w: ':'", ErrUnknownCommand)
+	}
+	switch args[0] {
+	case "functions":
+		lines := make([]string, 0, len(r.Env.Functions()))
+		for _, f := range r.Env.Functions() {
+			lines = append(lines, f.String())
+		}
+		return strings.Join(lines, "\n"), nil
+	case "delete":
+		if len(args) != 2 {
+			return "", fmt.Errorf("usage: :delete NAME")
+		}
+		if !r.Env.Delete(args[1]) {
+			return "", fmt.Errorf("%w: %s", evaluation.ErrUnknownFunction, args[1])
+		}
+		return "", nil
+	case "output":
+		if len(args) != 2 {
+			return "", fmt.Errorf("usage: :output text|latex|mathml")
+		}
+		f, err := render.ParseFormat(args[1])
+		if err != nil {
+			return "", err
+		}
+		r.Output = f
+		return "", nil
+	case "mode", "base", "locale", "percent":
+		return "", r.Set(args[0], args[1:]...)
+	case "history":
+		if r.History == nil {
+			return "", fmt.Errorf("%w: the history is disabled", ErrUnknownCommand)
+		}
+		if len(args) == 2 && args[1] == "clear" {
+			return "", r.History.Clear()
+		}
+		entries := r.History.Search(strings.Join(args[1:], " "))
+		lines := make([]string, len(entries))
+		for i, e := range entries {
+			lines[i] = e.String()
+		}
+		return strings.Join(lines, "\n"), nil
+	case "m+", "m-", "ms", "mr", "mc", "memory":
+		if r.Memory == nil {
+			return "", fmt.Errorf("%w: there are no memory registers", ErrUnknownCommand)
+		}
+		if args[0] == "memory" {
+			return r.registers(), nil
+		}
+		if len(args) > 2 {
+			return "", fmt.Errorf("usage: :%s [NAME]", args[0])
+		}
+		name := memory.Default
+		if len(args) == 2 {
+			name = args[1]
+		}
+		return r.memoryKey(args[0], name)
+	case "rpn":
+		r.RPN = !r.RPN
+		if !r.RPN {
+			return "", nil
+		}
+		return r.Stack.Format(r.Formatter), nil
+	case "trace":
+		if len(args) < 2 {
+			return "", fmt.Errorf("usage: :trace EXPR")
+		}
+		return r.trace(strings.Join(args[1:], " "))
+	case "table":
+		if len(args) < 2 {
+			return "", fmt.Errorf("usage: :table [markdown|csv|json] FORMULA for VAR from START to END [step STEP]")
+		}
+		output, spec := table.FormatMarkdown, args[1:]
+		if f, err := table.ParseFormat(args[1]); err == nil {
+			output, spec = f, args[2:]
+		}
+		return r.table(output, strings.Join(spec, " "))
+	case "amortize":
+		if len(args) < 2 {
+			return "", fmt.Errorf("usage: :amortize [markdown|csv|json] RATE, NPER, PV[, FV[, TYPE]]")
+		}
+		output, spec := table.FormatMarkdown, args[1:]
+		if f, err := table.ParseFormat(args[1]); err == nil {
+			output, spec = f, args[2:]
+		}
+		return r.amortize(output, strings.Join(spec, " "))
+	case "help":
+		return help, nil
+	case "quit", "exit":
+		return "", errQuit
+	default:
+		return "", fmt.Errorf("%w: :%s", ErrUnknownCommand, args[0])
+	}
+}
+
+// locales are the names of the locales :locale accepts
+var locales = map[string]util.Locale{
+	"en": util.LocaleEnglish,
+	"de": util.LocaleGerman,
+	"fr": util.LocaleFrench,
+	"ch": util.LocaleSwiss,
+}
+
+// Set changes a setting of the Parser, Evaluator or Formatter like the commands :mode, :base, :locale and :percent,
+// args are the arguments of the command
+func (r *REPL) Set(setting string, args ...string) error {
+	values := map[string]string{
+		"mode":    "float, integer [TYPE] or big [BITS]",
+		"base":    "2, 8, 10 or 16",
+		"locale":  "en, de, fr or ch",
+		"percent": "calculator or divide",
+	}[setting]
+	if values == "" {
+		return fmt.Errorf("%w: %s", ErrInvalidSetting, setting)
+	}
+	usage := fmt.Errorf("%w: %s takes %s", ErrInvalidSetting, setting, values)
+	if len(args) == 0 || len(args) > 2 || len(args) == 2 && setting != "mode" {
+		return usage
+	}
+	switch setting {
+	case "mode":
+		return r.setMode(args, usage)
+	case "base":
+		base, err := strconv.Atoi(args[0])
+		if err != nil || base != 2 && base != 8 && base != 10 && base != 16 {
+			return usage
+		}
+		r.Formatter.Base = base
+	case "locale":
+		locale, ok := locales[strings.ToLower(args[0])]
+		if !ok {
+			return usage
+		}
+		r.Parser.Locale = locale
+		r.Formatter.Locale = locale
+	default:
+		switch args[0] {
+		case "calculator":
+			r.Evaluator.Percent = evaluation.PercentCalculator
+		case "divide":
+			r.Evaluator.Percent = evaluation.PercentDivide
+		default:
+			return usage
+		}
+	}
+	return nil
+}
+
+// setMode sets the Mode of the Evaluator and the IntType or Precision given after it
+func (r *REPL) setMode(args []string, usage error) error {
+	switch {
+	case args[0] == "float" && len(args) == 1:
+		r.Evaluator.Mode = evaluation.ModeFloat
+	case args[0] == "integer":
+		t := util.Int64
+		if len(args) == 2 {
+			var err error
+			if t, err = util.ParseIntType(args[1]); err != nil {
+				return fmt.Errorf("%w: %v", ErrInvalidSetting, err)
+			}
+		}
+		r.Evaluator.Mode = evaluation.ModeInteger
+		r.Evaluator.IntType = t
+	case args[0] == "big":
+		precision := uint64(evaluation.DefaultPrecision)
+		if len(args) == 2 {
+			var err error
+			if precision, err = strconv.ParseUint(args[1], 10, 32); err != nil || precision == 0 {
+				return fmt.Errorf("%w: %s bits", ErrInvalidSetting, args[1])
+			}
+		}
+		r.Evaluator.Mode = evaluation.ModeBig
+		r.Evaluator.Precision = uint(precision)
+	default:
+		return usage
+	}
+	return nil
+}
+
+// enter passes the line to the Stack and prints the stack afterwards, the value on top is the result used by the memory
+// commands
+func (r *REPL) enter(line string) (string, error) {
+	r.Stack.Parser = r.Parser
+	r.Stack.Evaluator = r.Evaluator
+	r.Stack.Evaluator.Env = r.Env
+	if err := r.Stack.Enter(line); err != nil {
+		return "", err
+	}
+	if top, ok := r.Stack.Top(); ok {
+		r.last = &top
+	}
+	return r.Stack.Format(r.Formatter), nil
+}
+
+// table generates the table and writes it in the format
+func (r *REPL) table(output table.Format, spec string) (string, error) {
+	g := table.Generator{Parser: r.Parser, Evaluator: r.Evaluator}
+	g.Parser.Scope = r.Env
+	g.Evaluator.Env = r.Env
+	t, err := g.Run(spec)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := t.Write(&b, output, r.Formatter); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+// amortize generates the amortization schedule and writes it in the format
+func (r *REPL) amortize(output table.Format, spec string) (string, error) {
+	g := table.Generator{Parser: r.Parser, Evaluator: r.Evaluator}
+	g.Parser.Scope = r.Env
+	g.Evaluator.Env = r.Env
+	s, err := g.Amortize(spec)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := s.Write(&b, output, r.Formatter); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+// trace runs the line like Execute and prints every step of the Shunting-yard algorithm and of the evaluation before
+// the results
+func (r *REPL) trace(line string) (string, error) {
+	var steps strings.Builder
+	runner := script.Runner{Parser: r.Parser, Evaluator: r.Evaluator, Env: r.Env}
+	runner.Parser.Trace = func(s parser.ParseStep) {
+		fmt.Fprintf(&steps, "parse %v\n", s)
+	}
+	runner.Evaluator.Trace = func(s evaluation.Step) {
+		fmt.Fprintf(&steps, "eval  %v\n", s)
+	}
+	output, err := r.output(runner.Run(line))
+	return steps.String() + output, err
+}
+
+// registers lists the values of all memory registers
+func (r *REPL) registers() string {
+	names := r.Memory.Names()
+	lines := make([]string, len(names))
+	for i, name := range names {
+		v, _ := r.Memory.Recall(name)
+		lines[i] = name + " = " + r.Formatter.Format(&v)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// memoryKey runs one of the memory commands on the register with the given name
+func (r *REPL) memoryKey(key, name string) (string, error) {
+	switch key {
+	case "mr":
+		v, err := r.Memory.Recall(name)
+		if err != nil {
+			return "", err
+		}
+		return r.Formatter.Format(&v), nil
+	case "mc":
+		r.Memory.Clear(name)
+		return "", nil
+	}
+	if r.last == nil {
+		return "", errNoResult
+	}
+	switch key {
+	case "m+":
+		return "", r.Memory.Add(name, *r.last)
+	case "m-":
+		return "", r.Memory.Subtract(name, *r.last)
+	default:
+		return "", r.Memory.Store(name, *r.last)
+	}
+}
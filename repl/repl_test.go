@@ -0,0 +1,218 @@
+package repl
+
+import (
+	"errors"
+	"github.com/niklasstich/calculator/evaluation"
+	"github.com/niklasstich/calculator/history"
+	"github.com/niklasstich/calculator/memory"
+	"github.com/niklasstich/calculator/parser"
+	"github.com/niklasstich/calculator/render"
+	"github.com/niklasstich/calculator/rpn"
+	"github.com/niklasstich/calculator/table"
+	"strings"
+	"testing"
+)
+
+func TestExecute(t *testing.T) {
+	var tests = []struct {
+		line, output string
+		err          error
+	}{
+		{"1 + 2", "3", nil},
+		{"1 + 2; 3 * 4 # comment", "3\n12", nil},
+		{"1 / 0; 2", "error: division by 0\n2", nil},
+		{"f(x, y) = x^2 + y^2", "f(x, y) = x^2 + y^2", nil},
+		{"f(3, 4)", "25", nil},
+		{"g(x) = x / 2", "g(x) = x / 2", nil},
+		{":functions", "f(x, y) = x^2 + y^2\ng(x) = x / 2", nil},
+		{":delete f", "", nil},
+		{":functions", "g(x) = x / 2", nil},
+		{"f(3, 4)", "", parser.ErrInvalidToken},
+		{":delete f", "", evaluation.ErrUnknownFunction},
+		{":output latex", "", nil},
+		{"g(1) / 4", `\frac{g\left(1\right)}{4} = 0.125`, nil},
+		{":output text", "", nil},
+		{":trace 2 * 3", "parse 2      output [2] operators []\n" +
+			"parse *      output [2] operators [*]\n" +
+			"parse 3      output [2 3] operators [*]\n" +
+			"parse end    output [2 3 *] operators []\n" +
+			"eval  2      [] -> [2]\n" +
+			"eval  3      [2] -> [2 3]\n" +
+			"eval  *      [2 3] -> [6]\n" +
+			"6", nil},
+		{":table g(x) for x from 0 to 2", "| x | g(x) |\n| ---: | ---: |\n| 0 | 0 |\n| 1 | 0.5 |\n| 2 | 1 |", nil},
+		{":table csv x * y for x from 1 to 2 and y from 2 to 2", "x,y,x * y\n1,2,2\n2,2,4", nil},
+		{":table x for x from 1", "", table.ErrInvalidSpec},
+		{":amortize csv 0, 2, 100", "period,payment,interest,principal,balance\n1,50,0,50,50\n2,50,0,50,0", nil},
+		{":amortize 1%", "", table.ErrInvalidSpec},
+		{"3h 20m * 4", "800 min", nil},
+		{":output html", "", render.ErrUnknownFormat},
+		{":nothing", "", ErrUnknownCommand},
+		{"", "", nil},
+	}
+
+	r := New()
+	for _, tt := range tests {
+		got, err := r.Execute(tt.line)
+		if !errors.Is(err, tt.err) {
+			t.Fatalf("%s: expected error %v, got %v", tt.line, tt.err, err)
+		}
+		if got != tt.output {
+			t.Errorf("%s: wanted %q, got %q", tt.line, tt.output, got)
+		}
+	}
+}
+
+func TestHistory(t *testing.T) {
+	var tests = []struct {
+		line, output string
+		err          error
+	}{
+		{":history", "", nil},
+		{"6 * 7; ans / 2", "42\n21", nil},
+		{"$1 - 1", "41", nil},
+		{"$5", "", parser.ErrInvalidToken},
+		{":history", "$1  6 * 7 = 42\n$2  ans / 2 = 21\n$3  $1 - 1 = 41\n" +
+			"$4  $5: error: expression contains invalid token: $5 at pos 0", nil},
+		{":history - 1", "$3  $1 - 1 = 41", nil},
+		{":history clear", "", nil},
+		{":history", "", nil},
+	}
+
+	r := New()
+	r.History = &history.History{Env: r.Env}
+	for _, tt := range tests {
+		got, err := r.Execute(tt.line)
+		if !errors.Is(err, tt.err) {
+			t.Fatalf("%s: expected error %v, got %v", tt.line, tt.err, err)
+		}
+		if got != tt.output {
+			t.Errorf("%s: wanted %q, got %q", tt.line, tt.output, got)
+		}
+	}
+	if _, err := New().Execute(":history"); !errors.Is(err, ErrUnknownCommand) {
+		t.Errorf("Expected error %v, got %v", ErrUnknownCommand, err)
+	}
+}
+
+func TestMemory(t *testing.T) {
+	var tests = []struct {
+		line, output string
+		err          error
+	}{
+		{":m+", "", errNoResult},
+		{"2 km", "2 km", nil},
+		{":m+", "", nil},
+		{":m+", "", nil},
+		{"500 m", "500 m", nil},
+		{":m- M", "", nil},
+		{":mr", "3.5 km", nil},
+		{"M in m", "3500 m", nil},
+		{":ms M1", "", nil},
+		{"M1 / M", "1", nil},
+		{":ms pi", "", memory.ErrInvalidName},
+		{":memory", "M = 3.5 km\nM1 = 3500 m", nil},
+		{":mc", "", nil},
+		{":mr", "", memory.ErrEmptyRegister},
+		{":memory", "M1 = 3500 m", nil},
+	}
+
+	r := New()
+	for _, tt := range tests {
+		got, err := r.Execute(tt.line)
+		if !errors.Is(err, tt.err) {
+			t.Fatalf("%s: expected error %v, got %v", tt.line, tt.err, err)
+		}
+		if got != tt.output {
+			t.Errorf("%s: wanted %q, got %q", tt.line, tt.output, got)
+		}
+	}
+}
+
+func TestRPN(t *testing.T) {
+	var tests = []struct {
+		line, output string
+		err          error
+	}{
+		{"sq(x) = x^2", "sq(x) = x^2", nil},
+		{":rpn", "", nil},
+		{"3 4", "2: 3\n1: 4", nil},
+		{"sq swap", "2: 16\n1: 3", nil},
+		{"", "3: 16\n2: 3\n1: 3", nil},
+		{"* +", "1: 25", nil},
+		{"roll", "1: 25", nil},
+		{"drop drop", "", rpn.ErrStackTooSmall},
+		{":ms", "", nil},
+		{"undo", "1: 25", nil},
+		{"undo", "3: 16\n2: 3\n1: 3", nil},
+		{":rpn", "", nil},
+		{"M / 5", "5", nil},
+		{":rpn", "3: 16\n2: 3\n1: 3", nil},
+	}
+
+	r := New()
+	for _, tt := range tests {
+		got, err := r.Execute(tt.line)
+		if !errors.Is(err, tt.err) {
+			t.Fatalf("%s: expected error %v, got %v", tt.line, tt.err, err)
+		}
+		if got != tt.output {
+			t.Errorf("%s: wanted %q, got %q", tt.line, tt.output, got)
+		}
+	}
+}
+
+func TestSettings(t *testing.T) {
+	var tests = []struct {
+		line, output string
+		err          error
+	}{
+		{":mode integer uint8", "", nil},
+		{"0xff + 1", "0", nil},
+		{"7 / 2", "3", nil},
+		{":base 16", "", nil},
+		{"255", "0xff", nil},
+		{":base 10", "", nil},
+		{":mode big 64", "", nil},
+		{"1 / 3", "0.33333333333333333334", nil},
+		{":mode float", "", nil},
+		{"200 + 10%", "220", nil},
+		{":percent divide", "", nil},
+		{"200 + 10%", "200.1", nil},
+		{":percent calculator", "", nil},
+		{":locale de", "", nil},
+		{"max(1,5; 2,5)", "2,5", nil},
+		{":locale en", "", nil},
+		{"max(1.5, 2.5)", "2.5", nil},
+		{":mode integer int3", "", ErrInvalidSetting},
+		{":mode decimal", "", ErrInvalidSetting},
+		{":mode big 0", "", ErrInvalidSetting},
+		{":base 7", "", ErrInvalidSetting},
+		{":locale xx", "", ErrInvalidSetting},
+		{":percent", "", ErrInvalidSetting},
+		{":percent divide now", "", ErrInvalidSetting},
+	}
+
+	r := New()
+	for _, tt := range tests {
+		got, err := r.Execute(tt.line)
+		if !errors.Is(err, tt.err) {
+			t.Fatalf("%s: expected error %v, got %v", tt.line, tt.err, err)
+		}
+		if got != tt.output {
+			t.Errorf("%s: wanted %q, got %q", tt.line, tt.output, got)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	in := strings.NewReader("sq(x) = x*x\nsq(1/0)\nsq(3)\n:quit\n1\n")
+	var out strings.Builder
+	if err := New().Run(in, &out); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := "> sq(x) = x*x\n> error: division by 0\n> 9\n> "
+	if out.String() != want {
+		t.Errorf("Wanted %q, got %q", want, out.String())
+	}
+}
@@ -0,0 +1,284 @@
+// Package rpn implements an interactive stack calculator like the RPN calculators of HP. Values are pushed onto the
+// stack and operators are applied to the values on top of it, so "3 4 +" leaves 7 on the stack.
+package rpn
+
+import (
+	"errors"
+	"fmt"
+	"github.com/niklasstich/calculator/evaluation"
+	"github.com/niklasstich/calculator/format"
+	"github.com/niklasstich/calculator/parser"
+	"github.com/niklasstich/calculator/util"
+	"strings"
+)
+
+var ErrStackTooSmall = errors.New("not enough values on the stack")
+var ErrNothingToUndo = errors.New("nothing to undo")
+var ErrUnsupportedOperator = errors.New("operator can't be used on the stack")
+
+// DefaultMaxUndo is the number of changes which can be undone if Stack.MaxUndo is not set
+const DefaultMaxUndo = 100
+
+// negation is applied by the neg and chs commands, '-' always subtracts
+var negation = &util.Operator{Char: '-', Name: "neg", Precedence: 3, Unary: true, Op: util.OpNegation}
+
+// commands are the words which change the stack without evaluating anything
+var commands = map[string]func(s *Stack) error{
+	"swap":  (*Stack).swap,
+	"drop":  (*Stack).drop,
+	"dup":   (*Stack).dup,
+	"roll":  (*Stack).roll,
+	"clear": (*Stack).clear,
+	"neg": func(s *Stack) error {
+		return s.apply(negation)
+	},
+	"chs": func(s *Stack) error {
+		return s.apply(negation)
+	},
+}
+
+// Stack is the stack of an RPN calculator. Every change can be undone, a change which fails leaves the stack as it
+// was.
+type Stack struct {
+	// Parser reads the values entered, its Scope is the Env of the Evaluator if it is not set
+	Parser parser.Parser
+	// Evaluator applies the operators, functions of its Env and builtin functions can be applied like operators
+	Evaluator evaluation.Evaluator
+	// MaxUndo is the number of changes which can be undone, DefaultMaxUndo if it is 0
+	MaxUndo int
+
+	items []util.Token
+	//undo holds the items before each change, the last change is at the end
+	undo [][]util.Token
+}
+
+// Items returns the values on the stack from the bottom to the top
+func (s *Stack) Items() []util.Token {
+	return append([]util.Token(nil), s.items...)
+}
+
+// Len returns the number of values on the stack
+func (s *Stack) Len() int {
+	return len(s.items)
+}
+
+// Top returns the value on top of the stack
+func (s *Stack) Top() (util.Token, bool) {
+	if len(s.items) == 0 {
+		return util.Token{}, false
+	}
+	return s.items[len(s.items)-1], true
+}
+
+// Push puts the value on top of the stack
+func (s *Stack) Push(value util.Token) {
+	_ = s.change(func() error {
+		s.items = append(s.items, value)
+		return nil
+	})
+}
+
+// Enter runs the words of the line from left to right. A word is a command like swap, an operator like + or the name
+// of a function which is applied to the values on top of the stack, or an expression like 2 or 3km whose value is
+// pushed. An empty line duplicates the top value like the Enter key of a HP calculator and "undo" on its own reverts
+// the last change. The whole line is a single change, if any word fails the stack stays as it was.
+func (s *Stack) Enter(line string) error {
+	words := strings.Fields(line)
+	if len(words) == 1 && words[0] == "undo" {
+		return s.Undo()
+	}
+	return s.change(func() error {
+		if len(words) == 0 {
+			return s.dup()
+		}
+		for _, word := range words {
+			if err := s.word(word); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Apply applies the operator to the values on top of the stack and replaces them with the result
+func (s *Stack) Apply(operator *util.Operator) error {
+	return s.change(func() error {
+		return s.apply(operator)
+	})
+}
+
+// Swap exchanges the two values on top of the stack
+func (s *Stack) Swap() error {
+	return s.change(s.swap)
+}
+
+// Drop removes the value on top of the stack
+func (s *Stack) Drop() error {
+	return s.change(s.drop)
+}
+
+// Dup pushes the value on top of the stack again
+func (s *Stack) Dup() error {
+	return s.change(s.dup)
+}
+
+// Roll moves the value on top of the stack to the bottom, like the roll down key
+func (s *Stack) Roll() error {
+	return s.change(s.roll)
+}
+
+// Clear removes all values from the stack
+func (s *Stack) Clear() {
+	_ = s.change(s.clear)
+}
+
+// Undo reverts the last change
+func (s *Stack) Undo() error {
+	if len(s.undo) == 0 {
+		return ErrNothingToUndo
+	}
+	s.items = s.undo[len(s.undo)-1]
+	s.undo = s.undo[:len(s.undo)-1]
+	return nil
+}
+
+// Format prints the stack with the level of each value like a HP calculator, level 1 is the top at the end
+func (s *Stack) Format(f format.Formatter) string {
+	lines := make([]string, len(s.items))
+	for i, item := range s.items {
+		lines[i] = fmt.Sprintf("%d: %s", len(s.items)-i, f.Format(&item))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// change runs f as a single change of the stack, which can be undone. If f fails the stack is restored.
+func (s *Stack) change(f func() error) error {
+	before := s.Items()
+	if err := f(); err != nil {
+		s.items = before
+		return err
+	}
+	s.undo = append(s.undo, before)
+	if max := s.maxUndo(); len(s.undo) > max {
+		s.undo = s.undo[len(s.undo)-max:]
+	}
+	return nil
+}
+
+func (s *Stack) word(word string) error {
+	if command, ok := commands[word]; ok {
+		return command(s)
+	}
+	if word == "undo" {
+		//undoing inside a line would mix with the undo of the line itself
+		return fmt.Errorf("%w: undo has to be entered on its own", ErrUnsupportedOperator)
+	}
+	env := s.Evaluator.Env
+	if env != nil {
+		if f, ok := env.Function(word); ok {
+			return s.apply(&util.Operator{Name: word, Precedence: 5, Op: util.OpCall, Arguments: len(f.Params)})
+		}
+	}
+	if b, ok := evaluation.LookupBuiltin(word); ok {
+		n := b.Params - b.Optional
+		if b.Params == evaluation.Variadic {
+			//functions like sum are applied to the list on top of the stack
+			n = 1
+		}
+		return s.apply(&util.Operator{Name: word, Precedence: 5, Op: util.OpCall, Arguments: n})
+	}
+	p := s.Parser
+	if p.Scope == nil && env != nil {
+		p.Scope = env
+	}
+	tokens, err := p.Tokenize(word)
+	if err != nil {
+		return err
+	}
+	if len(tokens) == 1 && tokens[0].TokenType == util.TokenTypeOperator {
+		return s.apply(tokens[0].TokenOperator)
+	}
+	expression, err := p.ReformToRPN(tokens)
+	if err != nil {
+		return err
+	}
+	v, err := s.Evaluator.Evaluate(expression)
+	if err != nil {
+		return err
+	}
+	s.items = append(s.items, *v)
+	return nil
+}
+
+func (s *Stack) apply(operator *util.Operator) error {
+	if operator.Bracket || operator.Op == util.OpArgumentSeparator || operator.Op == util.OpCondition ||
+		operator.Op == util.OpTernary {
+		return fmt.Errorf("%w: %v", ErrUnsupportedOperator, operator)
+	}
+	n := operator.Arity()
+	if err := s.need(n); err != nil {
+		return err
+	}
+	expression := make(parser.RPNExpression, 0, n+1)
+	expression = append(expression, s.items[len(s.items)-n:]...)
+	expression = append(expression, util.Token{TokenType: util.TokenTypeOperator, TokenOperator: operator})
+	v, err := s.Evaluator.Evaluate(expression)
+	if err != nil {
+		return err
+	}
+	s.items = append(s.items[:len(s.items)-n], *v)
+	return nil
+}
+
+func (s *Stack) swap() error {
+	if err := s.need(2); err != nil {
+		return err
+	}
+	n := len(s.items)
+	s.items[n-1], s.items[n-2] = s.items[n-2], s.items[n-1]
+	return nil
+}
+
+func (s *Stack) drop() error {
+	if err := s.need(1); err != nil {
+		return err
+	}
+	s.items = s.items[:len(s.items)-1]
+	return nil
+}
+
+func (s *Stack) dup() error {
+	if err := s.need(1); err != nil {
+		return err
+	}
+	s.items = append(s.items, s.items[len(s.items)-1])
+	return nil
+}
+
+func (s *Stack) roll() error {
+	if err := s.need(1); err != nil {
+		return err
+	}
+	top := s.items[len(s.items)-1]
+	s.items = append([]util.Token{top}, s.items[:len(s.items)-1]...)
+	return nil
+}
+
+func (s *Stack) clear() error {
+	s.items = nil
+	return nil
+}
+
+func (s *Stack) need(n int) error {
+	if len(s.items) < n {
+		return fmt.Errorf("%w: %d needed, there are %d", ErrStackTooSmall, n, len(s.items))
+	}
+	return nil
+}
+
+func (s *Stack) maxUndo() int {
+	if s.MaxUndo <= 0 {
+		return DefaultMaxUndo
+	}
+	return s.MaxUndo
+}
@@ -0,0 +1,99 @@
+package rpn
+
+import (
+	"errors"
+	"github.com/niklasstich/calculator/evaluation"
+	"github.com/niklasstich/calculator/format"
+	"github.com/niklasstich/calculator/parser"
+	"strings"
+	"testing"
+)
+
+func TestEnter(t *testing.T) {
+	env := &evaluation.Environment{}
+	def, _, err := (&parser.Parser{Scope: env}).ParseDefinition("hyp(a, b) = (a^2 + b^2)^0.5")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := env.Define(*def); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var tests = []struct {
+		line, stack string
+		err         error
+	}{
+		{"3 4 +", "7", nil},
+		{"2 *", "14", nil},
+		{"", "14 14", nil},
+		{"-", "0", nil},
+		{"undo", "14 14", nil},
+		{"-5 swap", "14 -5 14", nil},
+		{"roll", "14 14 -5", nil},
+		{"drop dup", "14 14 14", nil},
+		{"clear 3 4 hyp", "5", nil},
+		{"chs 3 %", "-5 0.03", nil},
+		{"2km 500m + m in", "-5 0.03 2500 m", nil},
+		{"+", "-5 0.03 2500 m", evaluation.ErrIncompatibleUnits},
+		{"drop drop drop +", "-5 0.03 2500 m", ErrStackTooSmall},
+		{"(", "-5 0.03 2500 m", ErrUnsupportedOperator},
+		{"1 undo", "-5 0.03 2500 m", ErrUnsupportedOperator},
+		{"x", "-5 0.03 2500 m", parser.ErrInvalidToken},
+		{"clear", "", nil},
+		{"drop", "", ErrStackTooSmall},
+		{"undo", "-5 0.03 2500 m", nil},
+	}
+
+	s := &Stack{Evaluator: evaluation.Evaluator{Env: env}}
+	for _, tt := range tests {
+		err := s.Enter(tt.line)
+		if !errors.Is(err, tt.err) {
+			t.Fatalf("%s: expected error %v, got %v", tt.line, tt.err, err)
+		}
+		items := s.Items()
+		values := make([]string, len(items))
+		for i, item := range items {
+			values[i] = item.String()
+		}
+		if got := strings.Join(values, " "); got != tt.stack {
+			t.Errorf("%s: wanted stack %q, got %q", tt.line, tt.stack, got)
+		}
+	}
+
+	if got := s.Format(format.Formatter{}); got != "3: -5\n2: 0.03\n1: 2500 m" {
+		t.Errorf("Unexpected format %q", got)
+	}
+}
+
+func TestBuiltin(t *testing.T) {
+	s := &Stack{}
+	if err := s.Enter("16 sqrt 0 cos"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := s.Format(format.Formatter{}); got != "2: 4\n1: 1" {
+		t.Errorf("Unexpected format %q", got)
+	}
+	if err := s.Enter("drop drop sqrt"); !errors.Is(err, ErrStackTooSmall) {
+		t.Errorf("Expected error %v, got %v", ErrStackTooSmall, err)
+	}
+}
+
+func TestUndo(t *testing.T) {
+	s := &Stack{MaxUndo: 2}
+	for _, line := range []string{"1", "2", "3"} {
+		if err := s.Enter(line); err != nil {
+			t.Fatalf("%s: unexpected error %v", line, err)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		if err := s.Undo(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if err := s.Undo(); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("Expected error %v, got %v", ErrNothingToUndo, err)
+	}
+	if top, ok := s.Top(); !ok || s.Len() != 1 || top.String() != "1" {
+		t.Errorf("Unexpected stack %v", s.Items())
+	}
+}
@@ -0,0 +1,152 @@
+// Package script runs calculation recipes with several statements. Statements are separated by ';' or line breaks
+// outside of brackets, so a bracket can span several lines, and '#' starts a comment until the end of the line.
+// Every statement is a function definition or an expression, the value of the last one is the result of the script.
+package script
+
+import (
+	"context"
+	"errors"
+	"github.com/niklasstich/calculator/evaluation"
+	"github.com/niklasstich/calculator/parser"
+	"github.com/niklasstich/calculator/util"
+	"strings"
+)
+
+var ErrEmptyScript = errors.New("script has no statements")
+
+// Statement is the text of a single statement and the Line it starts on, counting from 1
+type Statement struct {
+	Text string
+	Line int
+}
+
+// Result is the outcome of a single statement, Value is set for expressions and Definition for function definitions
+// unless Err is set. Expression is the parsed expression, it is also set if its evaluation failed.
+type Result struct {
+	Statement
+	Expression parser.RPNExpression
+	Value      *util.Token
+	Definition *parser.Definition
+	Err        error
+}
+
+// Runner holds the settings used to run scripts
+type Runner struct {
+	Parser    parser.Parser
+	Evaluator evaluation.Evaluator
+	// Env stores the functions defined by the scripts, every Run starts with an empty Environment if it is not set
+	Env *evaluation.Environment
+	// Record is called with the result of every expression before the next statement runs, so it can store variables
+	// in Env which the following statements use
+	Record func(Result)
+}
+
+// Split splits the script into its statements, comments and empty statements are dropped. Round brackets and the
+// square brackets of lists both keep a statement going.
+func Split(src string) []Statement {
+	statements := make([]Statement, 0, 8)
+	var text strings.Builder
+	line, start, depth := 1, 1, 0
+	finish := func() {
+		if s := strings.TrimSpace(text.String()); s != "" {
+			statements = append(statements, Statement{Text: s, Line: start})
+		}
+		text.Reset()
+	}
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		switch {
+		case c == '#':
+			//skip the comment, the line break behind it is handled like any other
+			for i+1 < len(src) && src[i+1] != '\n' {
+				i++
+			}
+			continue
+		case c == '(' || c == '[':
+			depth++
+		case (c == ')' || c == ']') && depth > 0:
+			depth--
+		case c == ';' && depth == 0:
+			finish()
+			continue
+		case c == '\n':
+			line++
+			if depth == 0 {
+				finish()
+				continue
+			}
+		}
+		if strings.TrimSpace(text.String()) == "" {
+			start = line
+		}
+		text.WriteByte(c)
+	}
+	finish()
+	return statements
+}
+
+// Run executes all statements of the script in order and returns the result of each. A failing statement doesn't
+// stop the script, the following statements are still run.
+func (r *Runner) Run(src string) []Result {
+	return r.RunContext(context.Background(), src)
+}
+
+// RunContext works like Run, but stops evaluating once ctx is done, all expressions evaluated after that fail with the
+// error of ctx.
+func (r *Runner) RunContext(ctx context.Context, src string) []Result {
+	env := r.Env
+	if env == nil {
+		env = &evaluation.Environment{}
+	}
+	p := r.Parser
+	p.Scope = env
+	e := r.Evaluator
+	e.Env = env
+
+	statements := Split(src)
+	results := make([]Result, len(statements))
+	for i, s := range statements {
+		results[i] = execute(ctx, &p, &e, env, s)
+		if r.Record != nil && results[i].Definition == nil {
+			r.Record(results[i])
+		}
+	}
+	return results
+}
+
+func execute(ctx context.Context, p *parser.Parser, e *evaluation.Evaluator, env *evaluation.Environment,
+	s Statement) Result {
+	res := Result{Statement: s}
+	def, ok, err := p.ParseDefinition(s.Text)
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	if ok {
+		if res.Err = env.Define(*def); res.Err == nil {
+			res.Definition = def
+		}
+		return res
+	}
+	tokens, err := p.Tokenize(s.Text)
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	res.Expression, err = p.ReformToRPN(tokens)
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	res.Value, res.Err = e.EvaluateContext(ctx, res.Expression)
+	return res
+}
+
+// Final returns the result of the script, which is the value of its last statement
+func Final(results []Result) (*util.Token, error) {
+	if len(results) == 0 {
+		return nil, ErrEmptyScript
+	}
+	last := results[len(results)-1]
+	return last.Value, last.Err
+}
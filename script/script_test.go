@@ -0,0 +1,116 @@
+package script
+
+import (
+	"errors"
+	"fmt"
+	"github.com/niklasstich/calculator/evaluation"
+	"github.com/niklasstich/calculator/parser"
+	"github.com/niklasstich/calculator/util"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	var tests = []struct {
+		src  string
+		want string
+	}{
+		{"1 + 2", "[{1 + 2 1}]"},
+		{"1; 2;; 3", "[{1 1} {2 1} {3 1}]"},
+		{"1\n2\r\n\n3", "[{1 1} {2 2} {3 4}]"},
+		{"# price rules\nprice(q) = q > 100 ? 9 : 10 # per piece\nprice(120)", "[{price(q) = q > 100 ? 9 : 10 2} {price(120) 3}]"},
+		{"(1 +\n\t2)\n3", "[{(1 +\n\t2) 1} {3 3}]"},
+		{"\n\n  (1 # one\n+ 2)", "[{(1 \n+ 2) 3}]"},
+		{"f(1; 2); 3", "[{f(1; 2) 1} {3 1}]"},
+		{"# nothing\n;", "[]"},
+		{"1)\n2", "[{1) 1} {2 2}]"},
+		{"[1;2;3] * 2; 3", "[{[1;2;3] * 2 1} {3 1}]"},
+		{"[1,\n2] * 2\n3", "[{[1,\n2] * 2 1} {3 3}]"},
+	}
+
+	for i, tt := range tests {
+		testname := fmt.Sprintf("%d:%q", i+1, tt.src)
+		t.Run(testname, func(t *testing.T) {
+			if got := fmt.Sprint(Split(tt.src)); got != tt.want {
+				t.Errorf("Wanted %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	src := `# volume discount
+price(qty) = qty > 100 ? 0.9 : 1
+total(qty) = qty * price(qty)
+
+total(50); total(200)
+total(1/0)
+total(10) * 2`
+	results := (&Runner{}).Run(src)
+	var tests = []struct {
+		line  int
+		value string
+		def   string
+		err   error
+	}{
+		{2, "", "price", nil},
+		{3, "", "total", nil},
+		{5, "50", "", nil},
+		{5, "180", "", nil},
+		{6, "", "", evaluation.ErrDivByZero},
+		{7, "20", "", nil},
+	}
+	if len(results) != len(tests) {
+		t.Fatalf("Expected %d results, got %d", len(tests), len(results))
+	}
+	for i, tt := range tests {
+		res := results[i]
+		if res.Line != tt.line {
+			t.Errorf("%d: expected line %d, got %d", i, tt.line, res.Line)
+		}
+		if !errors.Is(res.Err, tt.err) {
+			t.Errorf("%d: expected error %v, got %v", i, tt.err, res.Err)
+		}
+		if res.Value != nil && res.Value.String() != tt.value || res.Value == nil && tt.value != "" {
+			t.Errorf("%d: expected value %s, got %v", i, tt.value, res.Value)
+		}
+		if res.Definition != nil && res.Definition.Name != tt.def || res.Definition == nil && tt.def != "" {
+			t.Errorf("%d: expected definition of %s, got %v", i, tt.def, res.Definition)
+		}
+		//failing expressions keep their parsed form too
+		if (res.Definition == nil) == (res.Expression == nil) {
+			t.Errorf("%d: expected an expression only for expressions, got %v", i, res.Expression)
+		}
+	}
+
+	final, err := Final(results)
+	if err != nil || final.String() != "20" {
+		t.Errorf("Expected final result 20, got %v, %v", final, err)
+	}
+	if _, err := Final((&Runner{}).Run("# empty")); !errors.Is(err, ErrEmptyScript) {
+		t.Errorf("Expected error %v, got %v", ErrEmptyScript, err)
+	}
+}
+
+func TestRunLocale(t *testing.T) {
+	//';' separates arguments in German, but statements outside of brackets
+	r := Runner{Parser: parser.Parser{Locale: util.LocaleGerman}, Env: &evaluation.Environment{}}
+	final, err := Final(r.Run("f(a; b) = a * b; f(1,5; 2)"))
+	if err != nil || final.String() != "3" {
+		t.Errorf("Expected final result 3, got %v, %v", final, err)
+	}
+	if !r.Env.IsFunction("f") {
+		t.Errorf("Expected f to be stored in the environment")
+	}
+	//lists are separated by ';' as well
+	final, err = Final(r.Run("[1;2;3] * 2"))
+	if err != nil || final.String() != "[2, 4, 6]" {
+		t.Errorf("Expected final result [2, 4, 6], got %v, %v", final, err)
+	}
+}
+
+func TestRunList(t *testing.T) {
+	final, err := Final((&Runner{}).Run("[1,\n2] * 2"))
+	if err != nil || final.String() != "[2, 4]" {
+		t.Errorf("Expected final result [2, 4], got %v, %v", final, err)
+	}
+}
@@ -0,0 +1,125 @@
+package table
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/niklasstich/calculator/finance"
+	"github.com/niklasstich/calculator/format"
+	"github.com/niklasstich/calculator/util"
+	"io"
+	"math"
+	"strings"
+)
+
+// Schedule is the amortization schedule of a loan, with a row for every payment
+type Schedule struct {
+	Installments []finance.Installment
+}
+
+// Header returns the names of the columns
+func (s *Schedule) Header() []string {
+	return []string{"period", "payment", "interest", "principal", "balance"}
+}
+
+// Cells returns the installment as text, the amounts are formatted by f
+func (s *Schedule) Cells(installment finance.Installment, f format.Formatter) []string {
+	cells := []string{fmt.Sprint(installment.Period)}
+	for _, amount := range []float64{installment.Payment, installment.Interest, installment.Principal,
+		installment.Balance} {
+		cells = append(cells, f.Format(&util.Token{TokenType: util.TokenTypeOperand, TokenOperand: amount}))
+	}
+	return cells
+}
+
+// Write writes the schedule in the Format, the amounts are formatted by f
+func (s *Schedule) Write(w io.Writer, output Format, f format.Formatter) error {
+	rows := make([][]string, len(s.Installments))
+	for i, installment := range s.Installments {
+		rows[i] = s.Cells(installment, f)
+	}
+	switch output {
+	case FormatCSV:
+		return writeCSV(w, s.Header(), rows)
+	case FormatJSON:
+		return s.writeJSON(w)
+	}
+	return writeMarkdown(w, s.Header(), rows)
+}
+
+// String returns the schedule as Markdown
+func (s *Schedule) String() string {
+	var b strings.Builder
+	_ = s.Write(&b, FormatMarkdown, format.Formatter{})
+	return b.String()
+}
+
+// jsonInstallment is a row of FormatJSON, the amounts are not rounded
+type jsonInstallment struct {
+	Period    int     `json:"period"`
+	Payment   float64 `json:"payment"`
+	Interest  float64 `json:"interest"`
+	Principal float64 `json:"principal"`
+	Balance   float64 `json:"balance"`
+}
+
+func (s *Schedule) writeJSON(w io.Writer) error {
+	rows := make([]jsonInstallment, len(s.Installments))
+	for i, installment := range s.Installments {
+		rows[i] = jsonInstallment(installment)
+	}
+	out := json.NewEncoder(w)
+	out.SetIndent("", "  ")
+	return out.Encode(rows)
+}
+
+// Amortize parses and generates the amortization schedule of a loan written like the arguments of pmt, which are
+// "RATE, NPER, PV[, FV[, TYPE]]" like "5%/12, 10*12, 200000". The arguments may be expressions. TYPE is 1 for
+// payments at the start of each period, and 0 for payments at their end if it is left out.
+func (g *Generator) Amortize(spec string) (*Schedule, error) {
+	usage := fmt.Errorf("%w: expected RATE, NPER, PV[, FV[, TYPE]]", ErrInvalidSpec)
+	if strings.TrimSpace(spec) == "" {
+		return nil, usage
+	}
+	//the arguments are evaluated as a list, which separates them like the arguments of functions
+	tokens, err := g.Parser.Tokenize("[" + spec + "]")
+	if err != nil {
+		return nil, err
+	}
+	rpn, err := g.Parser.ReformToRPN(tokens)
+	if err != nil {
+		return nil, err
+	}
+	v, err := g.Evaluator.Evaluate(rpn)
+	if err != nil {
+		return nil, err
+	}
+	args := v.TokenList
+	if len(args) < 3 || len(args) > 5 {
+		return nil, usage
+	}
+	values := []float64{0, 0, 0, 0, 0}
+	for i, arg := range args {
+		if arg.TokenList != nil || arg.TokenUnit != nil {
+			return nil, fmt.Errorf("%w: %v is not a number", ErrInvalidSpec, arg)
+		}
+		values[i] = arg.TokenOperand
+	}
+	max := g.MaxRows
+	if max <= 0 {
+		max = DefaultMaxRows
+	}
+	if nper := values[1]; nper != math.Trunc(nper) {
+		return nil, fmt.Errorf("%w: %v periods, it has to be a whole number", ErrInvalidSpec, nper)
+	} else if nper > float64(max) {
+		return nil, fmt.Errorf("%w: %v periods, at most %d are allowed", ErrTooManyRows, nper, max)
+	}
+	due := finance.EndOfPeriod
+	if values[4] != 0 {
+		due = finance.StartOfPeriod
+	}
+	installments, err := finance.Amortize(values[0], int(values[1]), values[2], values[3], due)
+	if err != nil {
+		return nil, err
+	}
+	return &Schedule{Installments: installments}, nil
+}
@@ -0,0 +1,162 @@
+package table
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/niklasstich/calculator/format"
+	"github.com/niklasstich/calculator/util"
+	"io"
+	"math"
+	"strings"
+)
+
+var ErrUnknownFormat = errors.New("unknown table format")
+
+// Format selects how a Table is written
+type Format int
+
+const (
+	// FormatMarkdown writes a Markdown table, which is also readable as plain text
+	FormatMarkdown Format = iota
+	// FormatCSV writes comma separated values with a header row
+	FormatCSV
+	// FormatJSON writes an array with an object for every row
+	FormatJSON
+)
+
+var formatNames = map[string]Format{
+	"markdown": FormatMarkdown,
+	"md":       FormatMarkdown,
+	"csv":      FormatCSV,
+	"json":     FormatJSON,
+}
+
+// ParseFormat returns the Format with the given name, which is markdown, csv or json
+func ParseFormat(name string) (Format, error) {
+	f, ok := formatNames[strings.ToLower(name)]
+	if !ok {
+		return FormatMarkdown, fmt.Errorf("%w: %s", ErrUnknownFormat, name)
+	}
+	return f, nil
+}
+
+// Header returns the names of the columns, which are the variables followed by the formula
+func (t *Table) Header() []string {
+	return append(append([]string(nil), t.Variables...), t.Formula)
+}
+
+// Cells returns the row as text, the inputs followed by the result or the error
+func (t *Table) Cells(row Row, f format.Formatter) []string {
+	cells := make([]string, 0, len(row.Inputs)+1)
+	for _, input := range row.Inputs {
+		cells = append(cells, f.Format(&util.Token{TokenType: util.TokenTypeOperand, TokenOperand: input}))
+	}
+	if row.Err != nil {
+		return append(cells, "error: "+row.Err.Error())
+	}
+	return append(cells, f.Format(row.Value))
+}
+
+// Write writes the table in the Format, the values are formatted by f
+func (t *Table) Write(w io.Writer, output Format, f format.Formatter) error {
+	switch output {
+	case FormatCSV:
+		return t.writeCSV(w, f)
+	case FormatJSON:
+		return t.writeJSON(w, f)
+	}
+	return t.writeMarkdown(w, f)
+}
+
+// String returns the table as Markdown
+func (t *Table) String() string {
+	var b strings.Builder
+	_ = t.writeMarkdown(&b, format.Formatter{})
+	return b.String()
+}
+
+func (t *Table) writeCSV(w io.Writer, f format.Formatter) error {
+	return writeCSV(w, t.Header(), t.cells(f))
+}
+
+func (t *Table) writeMarkdown(w io.Writer, f format.Formatter) error {
+	return writeMarkdown(w, t.Header(), t.cells(f))
+}
+
+// cells returns the rows as text
+func (t *Table) cells(f format.Formatter) [][]string {
+	rows := make([][]string, len(t.Rows))
+	for i, row := range t.Rows {
+		rows[i] = t.Cells(row, f)
+	}
+	return rows
+}
+
+func writeCSV(w io.Writer, header []string, rows [][]string) error {
+	out := csv.NewWriter(w)
+	if err := out.Write(header); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if err := out.Write(row); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+func writeMarkdown(w io.Writer, header []string, rows [][]string) error {
+	escape := strings.NewReplacer("|", `\|`)
+	line := func(cells []string) string {
+		for i, cell := range cells {
+			cells[i] = escape.Replace(cell)
+		}
+		return "| " + strings.Join(cells, " | ") + " |\n"
+	}
+	var b strings.Builder
+	b.WriteString(line(header))
+	//numbers are aligned to the right
+	b.WriteString(strings.Repeat("| ---: ", len(header)) + "|\n")
+	for _, row := range rows {
+		b.WriteString(line(row))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// jsonRow is a row of FormatJSON. Value is the number without its unit, it is left out for infinite values which JSON
+// can't represent. Result is the formatted value with its unit.
+type jsonRow struct {
+	Inputs map[string]float64 `json:"inputs"`
+	Value  *float64           `json:"value,omitempty"`
+	Unit   string             `json:"unit,omitempty"`
+	Result string             `json:"result,omitempty"`
+	Error  string             `json:"error,omitempty"`
+}
+
+func (t *Table) writeJSON(w io.Writer, f format.Formatter) error {
+	rows := make([]jsonRow, len(t.Rows))
+	for i, row := range t.Rows {
+		rows[i].Inputs = make(map[string]float64, len(row.Inputs))
+		for j, input := range row.Inputs {
+			rows[i].Inputs[t.Variables[j]] = input
+		}
+		if row.Err != nil {
+			rows[i].Error = row.Err.Error()
+			continue
+		}
+		if value := row.Value.TokenOperand; !math.IsInf(value, 0) && !math.IsNaN(value) {
+			rows[i].Value = &value
+		}
+		if row.Value.TokenUnit != nil {
+			rows[i].Unit = row.Value.TokenUnit.String()
+		}
+		rows[i].Result = f.Format(row.Value)
+	}
+	out := json.NewEncoder(w)
+	out.SetIndent("", "  ")
+	return out.Encode(rows)
+}
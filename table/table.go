@@ -0,0 +1,189 @@
+// Package table evaluates a formula for every value of one or two variables, like "x^2 + 3x for x from 0 to 10 step
+// 0.5", and writes the rows as CSV, Markdown or JSON. With two variables the table has a row for every combination of
+// their values, the values of the second variable change fastest. Amortization schedules of loans are written the same
+// way.
+package table
+
+import (
+	"errors"
+	"fmt"
+	"github.com/niklasstich/calculator/evaluation"
+	"github.com/niklasstich/calculator/parser"
+	"github.com/niklasstich/calculator/util"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidSpec = errors.New("invalid table")
+var ErrInvalidRange = errors.New("invalid range")
+var ErrTooManyRows = errors.New("table has too many rows")
+
+// DefaultMaxRows is the number of rows a table may have if Generator.MaxRows is not set
+const DefaultMaxRows = 10000
+
+// Range is the values of a variable from From to To in steps of Step, To is included if a step ends on it
+type Range struct {
+	Variable       string
+	From, To, Step float64
+}
+
+// Values returns the values of the range, at most max of them
+func (r Range) Values(max int) ([]float64, error) {
+	if r.Step == 0 || math.IsNaN(r.Step) || math.IsInf(r.Step, 0) || math.IsNaN(r.From) || math.IsNaN(r.To) {
+		return nil, fmt.Errorf("%w: %s from %v to %v step %v", ErrInvalidRange, r.Variable, r.From, r.To, r.Step)
+	}
+	if (r.To-r.From)/r.Step < 0 {
+		return nil, fmt.Errorf("%w: step %v doesn't lead from %v to %v", ErrInvalidRange, r.Step, r.From, r.To)
+	}
+	//a little tolerance keeps the end of ranges like 0 to 1 step 0.1, which is 9.999999999999998 steps
+	steps := math.Floor((r.To-r.From)/r.Step + 1e-9)
+	if steps+1 > float64(max) {
+		return nil, fmt.Errorf("%w: %s has more than %d values", ErrTooManyRows, r.Variable, max)
+	}
+	values := make([]float64, int(steps)+1)
+	for i := range values {
+		//values are computed from the start instead of adding up steps, and rounded so 3 * 0.1 is 0.3
+		values[i], _ = strconv.ParseFloat(strconv.FormatFloat(r.From+float64(i)*r.Step, 'g', 12, 64), 64)
+	}
+	return values, nil
+}
+
+// Row is the result of the formula for one combination of values of the variables
+type Row struct {
+	// Inputs are the values of the variables in the order of Table.Variables
+	Inputs []float64
+	// Value is the result, it is nil if the formula can't be evaluated for the inputs
+	Value *util.Token
+	Err   error
+}
+
+// Table holds the rows of a formula evaluated over ranges of its variables
+type Table struct {
+	Formula   string
+	Variables []string
+	Rows      []Row
+}
+
+// Generator creates tables
+type Generator struct {
+	// Parser parses the formula and the bounds of the ranges, its Scope provides the functions the formula may call
+	Parser parser.Parser
+	// Evaluator evaluates the formula for every row, it always uses ModeFloat
+	Evaluator evaluation.Evaluator
+	// MaxRows is the number of rows a table may have, DefaultMaxRows if it is not set
+	MaxRows int
+}
+
+// spec matches a table like "x^2 for x from 0 to 10 step 0.5 and y from 1 to 2"
+var (
+	specFor   = regexp.MustCompile(`^(.+?)\s+for\s+(.+)$`)
+	specAnd   = regexp.MustCompile(`\s+and\s+`)
+	specRange = regexp.MustCompile(`^(\pL[\pL\pN_]*)\s+from\s+(.+?)\s+to\s+(.+?)(?:\s+step\s+(.+))?$`)
+)
+
+// Run parses and generates a table written like "x^2 + 3x for x from 0 to 10 step 0.5". A second variable is added
+// with "and", like "x * y for x from 1 to 9 and y from 1 to 9". The step is 1 if it is left out. The bounds and steps
+// may be expressions like 2pi.
+func (g *Generator) Run(spec string) (*Table, error) {
+	m := specFor.FindStringSubmatch(strings.TrimSpace(spec))
+	if m == nil {
+		return nil, fmt.Errorf("%w: expected FORMULA for VARIABLE from START to END [step STEP]", ErrInvalidSpec)
+	}
+	var ranges []Range
+	for _, part := range specAnd.Split(m[2], -1) {
+		r := specRange.FindStringSubmatch(part)
+		if r == nil {
+			return nil, fmt.Errorf("%w: expected VARIABLE from START to END [step STEP] in %q", ErrInvalidSpec, part)
+		}
+		bounds := []float64{0, 0, 1}
+		for i, expression := range r[2:] {
+			if expression == "" {
+				continue
+			}
+			v, err := g.number(expression)
+			if err != nil {
+				return nil, err
+			}
+			bounds[i] = v
+		}
+		ranges = append(ranges, Range{Variable: r[1], From: bounds[0], To: bounds[1], Step: bounds[2]})
+	}
+	return g.Generate(m[1], ranges...)
+}
+
+// Generate evaluates the formula for every value of one or two ranges
+func (g *Generator) Generate(formula string, ranges ...Range) (*Table, error) {
+	if len(ranges) == 0 || len(ranges) > 2 {
+		return nil, fmt.Errorf("%w: %d variables, there have to be one or two", ErrInvalidSpec, len(ranges))
+	}
+	max := g.MaxRows
+	if max <= 0 {
+		max = DefaultMaxRows
+	}
+	t := &Table{Variables: make([]string, len(ranges))}
+	values := make([][]float64, len(ranges))
+	rows := 1
+	for i, r := range ranges {
+		t.Variables[i] = r.Variable
+		var err error
+		if values[i], err = r.Values(max); err != nil {
+			return nil, err
+		}
+		rows *= len(values[i])
+	}
+	if rows > max {
+		return nil, fmt.Errorf("%w: %d rows, at most %d are allowed", ErrTooManyRows, rows, max)
+	}
+	def, err := g.Parser.ParseFunction("", t.Variables, formula)
+	if err != nil {
+		return nil, err
+	}
+	t.Formula = def.Source
+	f, err := evaluation.Compile(*def)
+	if err != nil {
+		return nil, err
+	}
+
+	t.Rows = make([]Row, 0, rows)
+	inputs := make([]float64, len(ranges))
+	var fill func(i int)
+	fill = func(i int) {
+		if i == len(ranges) {
+			args := make([]util.Token, len(inputs))
+			for j, input := range inputs {
+				args[j] = util.Token{TokenType: util.TokenTypeOperand, TokenOperand: input}
+			}
+			v, err := g.Evaluator.Call(f, args...)
+			t.Rows = append(t.Rows, Row{Inputs: append([]float64(nil), inputs...), Value: v, Err: err})
+			return
+		}
+		for _, v := range values[i] {
+			inputs[i] = v
+			fill(i + 1)
+		}
+	}
+	fill(0)
+	return t, nil
+}
+
+// number evaluates a bound or step of a range, which has to be a number without a unit
+func (g *Generator) number(expression string) (float64, error) {
+	tokens, err := g.Parser.Tokenize(expression)
+	if err != nil {
+		return 0, err
+	}
+	rpn, err := g.Parser.ReformToRPN(tokens)
+	if err != nil {
+		return 0, err
+	}
+	v, err := g.Evaluator.Evaluate(rpn)
+	if err != nil {
+		return 0, err
+	}
+	if v.TokenUnit != nil {
+		return 0, fmt.Errorf("%w: %s has a unit", ErrInvalidRange, expression)
+	}
+	return v.TokenOperand, nil
+}
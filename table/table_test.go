@@ -0,0 +1,193 @@
+package table
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/niklasstich/calculator/evaluation"
+	"github.com/niklasstich/calculator/finance"
+	"github.com/niklasstich/calculator/format"
+	"github.com/niklasstich/calculator/parser"
+	"strings"
+	"testing"
+)
+
+func TestRange(t *testing.T) {
+	var tests = []struct {
+		r      Range
+		values string
+		err    error
+	}{
+		{Range{"x", 0, 1, 0.1}, "[0 0.1 0.2 0.3 0.4 0.5 0.6 0.7 0.8 0.9 1]", nil},
+		{Range{"x", 0, 10, 3}, "[0 3 6 9]", nil},
+		{Range{"x", 2, -2, -2}, "[2 0 -2]", nil},
+		{Range{"x", 5, 5, 1}, "[5]", nil},
+		{Range{"x", 0, 1, -1}, "", ErrInvalidRange},
+		{Range{"x", 0, 1, 0}, "", ErrInvalidRange},
+		{Range{"x", 0, 100, 1}, "", ErrTooManyRows},
+	}
+	for _, tt := range tests {
+		values, err := tt.r.Values(100)
+		if !errors.Is(err, tt.err) {
+			t.Fatalf("%v: expected error %v, got %v", tt.r, tt.err, err)
+		}
+		if err == nil && fmt.Sprint(values) != tt.values {
+			t.Errorf("%v: wanted %s, got %v", tt.r, tt.values, values)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	env := &evaluation.Environment{}
+	def, _, err := (&parser.Parser{Scope: env}).ParseDefinition("f(a) = a^2")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := env.Define(*def); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	g := &Generator{Parser: parser.Parser{Scope: env}, Evaluator: evaluation.Evaluator{Env: env}, MaxRows: 50}
+
+	var tests = []struct {
+		spec, table string
+		err         error
+	}{
+		{"x^2 + 3x for x from 0 to 2 step 0.5",
+			"| x | x^2 + 3x |\n| ---: | ---: |\n| 0 | 0 |\n| 0.5 | 1.75 |\n| 1 | 4 |\n| 1.5 | 6.75 |\n| 2 | 10 |\n", nil},
+		{"x * y for x from 1 to 2 and y from 3 to 4",
+			"| x | y | x * y |\n| ---: | ---: | ---: |\n| 1 | 3 | 3 |\n| 1 | 4 | 4 |\n| 2 | 3 | 6 |\n| 2 | 4 | 8 |\n", nil},
+		{"1 / f(n) for n from -1 to 1",
+			"| n | 1 / f(n) |\n| ---: | ---: |\n| -1 | 1 |\n| 0 | error: division by 0 |\n| 1 | 1 |\n", nil},
+		{"x km for x from 2 * 3 to 3! step 1", "", evaluation.ErrNotImplemented},
+		{"x for x from 1 m to 2 m", "", ErrInvalidRange},
+		{"x for x from 1 to 100", "", ErrTooManyRows},
+		{"x * y for x from 1 to 10 and y from 1 to 10", "", ErrTooManyRows},
+		{"x + y for x from 1 to 2", "", parser.ErrInvalidToken},
+		{"x from 1 to 2", "", ErrInvalidSpec},
+		{"x for x = 1 to 2", "", ErrInvalidSpec},
+	}
+	for _, tt := range tests {
+		table, err := g.Run(tt.spec)
+		if !errors.Is(err, tt.err) {
+			t.Fatalf("%s: expected error %v, got %v", tt.spec, tt.err, err)
+		}
+		if err == nil && table.String() != tt.table {
+			t.Errorf("%s: wanted\n%s\ngot\n%s", tt.spec, tt.table, table)
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+	g := &Generator{Parser: parser.Parser{Scope: &evaluation.Environment{}}}
+	table, err := g.Run("ln(x) km for x from 0 to 1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	table.Rows = append(table.Rows, Row{Inputs: []float64{2}, Err: evaluation.ErrDivByZero})
+
+	var tests = []struct {
+		format Format
+		want   string
+	}{
+		{FormatCSV, "x,ln(x) km\n0.000,-Inf km\n1.000,0.000 km\n2.000,error: division by 0\n"},
+		{FormatMarkdown, "| x | ln(x) km |\n| ---: | ---: |\n| 0.000 | -Inf km |\n| 1.000 | 0.000 km |\n| 2.000 | error: division by 0 |\n"},
+		{FormatJSON, `[
+  {
+    "inputs": {
+      "x": 0
+    },
+    "unit": "km",
+    "result": "-Inf km"
+  },
+  {
+    "inputs": {
+      "x": 1
+    },
+    "value": 0,
+    "unit": "km",
+    "result": "0.000 km"
+  },
+  {
+    "inputs": {
+      "x": 2
+    },
+    "error": "division by 0"
+  }
+]
+`},
+	}
+	for _, tt := range tests {
+		var b bytes.Buffer
+		if err := table.Write(&b, tt.format, format.Formatter{Mode: format.ModeFixed, Precision: 3}); err != nil {
+			t.Fatalf("%d: unexpected error %v", tt.format, err)
+		}
+		if b.String() != tt.want {
+			t.Errorf("%d: wanted\n%s\ngot\n%s", tt.format, tt.want, b.String())
+		}
+	}
+
+	if f, err := ParseFormat("MD"); err != nil || f != FormatMarkdown {
+		t.Errorf("Unexpected format %v: %v", f, err)
+	}
+	if _, err := ParseFormat("xml"); !errors.Is(err, ErrUnknownFormat) || !strings.Contains(err.Error(), "xml") {
+		t.Errorf("Expected error %v, got %v", ErrUnknownFormat, err)
+	}
+}
+
+func TestAmortize(t *testing.T) {
+	g := &Generator{Parser: parser.Parser{Scope: &evaluation.Environment{}}, MaxRows: 50}
+	var tests = []struct {
+		spec, schedule string
+		err            error
+	}{
+		//PMT(10%/12, 3, 2000) split by IPMT and PPMT
+		{"10%/12, 3, 2000",
+			"| period | payment | interest | principal | balance |\n| ---: | ---: | ---: | ---: | ---: |\n" +
+				"| 1 | 677.81 | 16.67 | 661.14 | 1338.86 |\n| 2 | 677.81 | 11.16 | 666.65 | 672.21 |\n" +
+				"| 3 | 677.81 | 5.60 | 672.21 | 0.00 |\n", nil},
+		{"10%/12, 3, 2000, 0, 1",
+			"| period | payment | interest | principal | balance |\n| ---: | ---: | ---: | ---: | ---: |\n" +
+				"| 1 | 672.21 | 0.00 | 672.21 | 1327.79 |\n| 2 | 672.21 | 11.06 | 661.14 | 666.65 |\n" +
+				"| 3 | 672.21 | 5.56 | 666.65 | 0.00 |\n", nil},
+		{"0, 2, 100, -50", "| period | payment | interest | principal | balance |\n| ---: | ---: | ---: | ---: | ---: |\n" +
+			"| 1 | 25.00 | 0.00 | 25.00 | 75.00 |\n| 2 | 25.00 | 0.00 | 25.00 | 50.00 |\n", nil},
+		{"1%, 2.5, 100", "", ErrInvalidSpec},
+		{"1%, 100, 100", "", ErrTooManyRows},
+		{"1%, 10", "", ErrInvalidSpec},
+		{"1%, 10, 100 m", "", ErrInvalidSpec},
+		{"", "", ErrInvalidSpec},
+		{"1%, 0, 100", "", finance.ErrInvalidArgument},
+	}
+	f := format.Formatter{Mode: format.ModeFixed, Precision: 2}
+	for _, tt := range tests {
+		schedule, err := g.Amortize(tt.spec)
+		if !errors.Is(err, tt.err) {
+			t.Fatalf("%s: expected error %v, got %v", tt.spec, tt.err, err)
+		}
+		if err != nil {
+			continue
+		}
+		var b bytes.Buffer
+		if err := schedule.Write(&b, FormatMarkdown, f); err != nil {
+			t.Fatalf("%s: unexpected error %v", tt.spec, err)
+		}
+		if b.String() != tt.schedule {
+			t.Errorf("%s: wanted\n%s\ngot\n%s", tt.spec, tt.schedule, b.String())
+		}
+	}
+
+	schedule, err := g.Amortize("0, 1, 10")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var b bytes.Buffer
+	if err := schedule.Write(&b, FormatCSV, f); err != nil || b.String() !=
+		"period,payment,interest,principal,balance\n1,10.00,0.00,10.00,0.00\n" {
+		t.Errorf("Unexpected CSV %q: %v", b.String(), err)
+	}
+	b.Reset()
+	if err := schedule.Write(&b, FormatJSON, f); err != nil || b.String() !=
+		"[\n  {\n    \"period\": 1,\n    \"payment\": 10,\n    \"interest\": 0,\n    \"principal\": 10,\n    \"balance\": 0\n  }\n]\n" {
+		t.Errorf("Unexpected JSON %q: %v", b.String(), err)
+	}
+}
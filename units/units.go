@@ -0,0 +1,214 @@
+// Package units contains the database of units known to the calculator. Units are resolved by symbol ("km"), by name
+// ("kilometres") and with SI prefixes for all units where those make sense.
+package units
+
+import (
+	"github.com/niklasstich/calculator/util"
+	"strings"
+)
+
+type definition struct {
+	factor     float64
+	dimension  util.Dimension
+	prefixable bool
+}
+
+type prefix struct {
+	symbol, name string
+	factor       float64
+}
+
+var (
+	length      = util.Dimension{util.DimLength: 1}
+	area        = util.Dimension{util.DimLength: 2}
+	volume      = util.Dimension{util.DimLength: 3}
+	mass        = util.Dimension{util.DimMass: 1}
+	time        = util.Dimension{util.DimTime: 1}
+	current     = util.Dimension{util.DimCurrent: 1}
+	temperature = util.Dimension{util.DimTemperature: 1}
+	amount      = util.Dimension{util.DimAmount: 1}
+	luminosity  = util.Dimension{util.DimLuminosity: 1}
+	frequency   = util.Dimension{util.DimTime: -1}
+	velocity    = util.Dimension{util.DimLength: 1, util.DimTime: -1}
+	force       = util.Dimension{util.DimLength: 1, util.DimMass: 1, util.DimTime: -2}
+	pressure    = util.Dimension{util.DimLength: -1, util.DimMass: 1, util.DimTime: -2}
+	energy      = util.Dimension{util.DimLength: 2, util.DimMass: 1, util.DimTime: -2}
+	power       = util.Dimension{util.DimLength: 2, util.DimMass: 1, util.DimTime: -3}
+	charge      = util.Dimension{util.DimCurrent: 1, util.DimTime: 1}
+	voltage     = util.Dimension{util.DimLength: 2, util.DimMass: 1, util.DimTime: -3, util.DimCurrent: -1}
+	resistance  = util.Dimension{util.DimLength: 2, util.DimMass: 1, util.DimTime: -3, util.DimCurrent: -2}
+)
+
+// definitions maps unit symbols to their factor to the coherent SI unit
+var definitions = map[string]definition{
+	//SI base units, the gram is used instead of the kilogram so prefixes work as expected
+	"m":   {1, length, true},
+	"g":   {1e-3, mass, true},
+	"s":   {1, time, true},
+	"A":   {1, current, true},
+	"K":   {1, temperature, true},
+	"mol": {1, amount, true},
+	"cd":  {1, luminosity, true},
+
+	//SI derived and accepted units
+	"Hz":  {1, frequency, true},
+	"N":   {1, force, true},
+	"Pa":  {1, pressure, true},
+	"J":   {1, energy, true},
+	"W":   {1, power, true},
+	"C":   {1, charge, true},
+	"V":   {1, voltage, true},
+	"ohm": {1, resistance, true},
+	"Ω":   {1, resistance, true},
+	"L":   {1e-3, volume, true},
+	"l":   {1e-3, volume, true},
+	"Wh":  {3600, energy, true},
+	"eV":  {1.602176634e-19, energy, true},
+	"t":   {1e3, mass, false},
+	"ha":  {1e4, area, false},
+	"bar": {1e5, pressure, true},
+	"min": {60, time, false},
+	"h":   {3600, time, false},
+	"d":   {86400, time, false},
+	"wk":  {604800, time, false},
+
+	//imperial and US customary units, the inch has no symbol since "in" is the conversion operator
+	"inch": {0.0254, length, false},
+	"ft":   {0.3048, length, false},
+	"yd":   {0.9144, length, false},
+	"mi":   {1609.344, length, false},
+	"nmi":  {1852, length, false},
+	"acre": {4046.8564224, area, false},
+	"gal":  {3.785411784e-3, volume, false},
+	"qt":   {9.46352946e-4, volume, false},
+	"pt":   {4.73176473e-4, volume, false},
+	"oz":   {0.028349523125, mass, false},
+	"lb":   {0.45359237, mass, false},
+	"st":   {6.35029318, mass, false},
+	"mph":  {0.44704, velocity, false},
+	"kn":   {1852.0 / 3600, velocity, false},
+	"psi":  {6894.757293168361, pressure, false},
+}
+
+// aliases maps unit names to their symbols
+var aliases = map[string]string{
+	"meter": "m", "meters": "m", "metre": "m", "metres": "m",
+	"gram": "g", "grams": "g",
+	"second": "s", "seconds": "s", "sec": "s",
+	"ampere": "A", "amperes": "A", "amp": "A", "amps": "A",
+	"kelvin": "K",
+	"mole":   "mol", "moles": "mol",
+	"candela": "cd",
+	"hertz":   "Hz",
+	"newton":  "N", "newtons": "N",
+	"pascal": "Pa", "pascals": "Pa",
+	"joule": "J", "joules": "J",
+	"watt": "W", "watts": "W",
+	"coulomb": "C", "coulombs": "C",
+	"volt": "V", "volts": "V",
+	"ohms":  "ohm",
+	"liter": "L", "liters": "L", "litre": "L", "litres": "L",
+	"tonne": "t", "tonnes": "t",
+	"hectare": "ha", "hectares": "ha",
+	"minute": "min", "minutes": "min",
+	"hour": "h", "hours": "h", "hr": "h",
+	"day": "d", "days": "d",
+	"week": "wk", "weeks": "wk",
+	"inches": "inch",
+	"foot":   "ft", "feet": "ft",
+	"yard": "yd", "yards": "yd",
+	"mile": "mi", "miles": "mi",
+	"acres":  "acre",
+	"gallon": "gal", "gallons": "gal",
+	"quart": "qt", "quarts": "qt",
+	"pint": "pt", "pints": "pt",
+	"ounce": "oz", "ounces": "oz",
+	"pound": "lb", "pounds": "lb", "lbs": "lb",
+	"stone": "st",
+	"knot":  "kn", "knots": "kn",
+}
+
+// prefixes are sorted so that "da" is tried before "d"
+var prefixes = []prefix{
+	{"da", "deca", 1e1},
+	{"Y", "yotta", 1e24},
+	{"Z", "zetta", 1e21},
+	{"E", "exa", 1e18},
+	{"P", "peta", 1e15},
+	{"T", "tera", 1e12},
+	{"G", "giga", 1e9},
+	{"M", "mega", 1e6},
+	{"k", "kilo", 1e3},
+	{"h", "hecto", 1e2},
+	{"d", "deci", 1e-1},
+	{"c", "centi", 1e-2},
+	{"m", "milli", 1e-3},
+	{"µ", "micro", 1e-6},
+	{"μ", "micro", 1e-6},
+	{"u", "micro", 1e-6},
+	{"n", "nano", 1e-9},
+	{"p", "pico", 1e-12},
+	{"f", "femto", 1e-15},
+	{"a", "atto", 1e-18},
+	{"z", "zepto", 1e-21},
+	{"y", "yocto", 1e-24},
+}
+
+// Lookup resolves a unit symbol or name such as "km", "µs", "kilometres" or "mph". Units are looked up exactly
+// first, so "min" is a minute and not a milli-inch.
+func Lookup(name string) (*util.Unit, bool) {
+	if u, ok := lookupExact(name); ok {
+		return u, true
+	}
+	for _, p := range prefixes {
+		if strings.HasPrefix(name, p.symbol) {
+			if u, ok := withPrefix(p.symbol, p.factor, name[len(p.symbol):], false); ok {
+				return u, true
+			}
+		}
+		if strings.HasPrefix(name, p.name) {
+			if u, ok := withPrefix(p.symbol, p.factor, name[len(p.name):], true); ok {
+				return u, true
+			}
+		}
+	}
+	return nil, false
+}
+
+func lookupExact(name string) (*util.Unit, bool) {
+	if sym, ok := aliases[name]; ok {
+		name = sym
+	}
+	def, ok := definitions[name]
+	if !ok {
+		return nil, false
+	}
+	return &util.Unit{
+		Terms:     []util.UnitTerm{{Symbol: name, Power: 1}},
+		Factor:    def.factor,
+		Dimension: def.dimension,
+	}, true
+}
+
+// withPrefix resolves rest as a prefixable unit, rest has to be a name instead of a symbol if the prefix was spelled
+// out, so "kilom" and "kmetre" are both rejected.
+func withPrefix(symbol string, factor float64, rest string, long bool) (*util.Unit, bool) {
+	sym := rest
+	if long {
+		var ok bool
+		if sym, ok = aliases[rest]; !ok {
+			return nil, false
+		}
+	} else if _, ok := aliases[rest]; ok {
+		return nil, false
+	}
+	def, ok := definitions[sym]
+	if !ok || !def.prefixable {
+		return nil, false
+	}
+	return &util.Unit{
+		Terms:     []util.UnitTerm{{Symbol: symbol + sym, Power: 1}},
+		Factor:    factor * def.factor,
+		Dimension: def.dimension,
+	}, true
+}
@@ -0,0 +1,58 @@
+package units
+
+import (
+	"fmt"
+	"github.com/niklasstich/calculator/util"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	var tests = []struct {
+		name      string
+		ok        bool
+		symbol    string
+		factor    float64
+		dimension util.Dimension
+	}{
+		{"m", true, "m", 1, length},
+		{"km", true, "km", 1e3, length},
+		{"kilometres", true, "km", 1e3, length},
+		{"kg", true, "kg", 1, mass},
+		{"µs", true, "µs", 1e-6, time},
+		{"us", true, "us", 1e-6, time},
+		{"min", true, "min", 60, time},
+		{"mi", true, "mi", 1609.344, length},
+		{"dam", true, "dam", 10, length},
+		{"hPa", true, "hPa", 100, pressure},
+		{"kWh", true, "kWh", 3.6e6, energy},
+		{"feet", true, "ft", 0.3048, length},
+		{"inch", true, "inch", 0.0254, length},
+		{"in", false, "", 0, util.Dimension{}},
+		{"kmin", false, "", 0, util.Dimension{}},
+		{"kmetre", false, "", 0, util.Dimension{}},
+		{"kilom", false, "", 0, util.Dimension{}},
+		{"foo", false, "", 0, util.Dimension{}},
+	}
+
+	for i, tt := range tests {
+		testname := fmt.Sprintf("%d: %s", i+1, tt.name)
+		t.Run(testname, func(t *testing.T) {
+			got, ok := Lookup(tt.name)
+			if ok != tt.ok {
+				t.Fatalf("Expected ok to be %v, got %v", tt.ok, ok)
+			}
+			if !ok {
+				return
+			}
+			if got.String() != tt.symbol {
+				t.Errorf("Expected symbol %s, got %s", tt.symbol, got)
+			}
+			if got.Factor != tt.factor {
+				t.Errorf("Expected factor %v, got %v", tt.factor, got.Factor)
+			}
+			if got.Dimension != tt.dimension {
+				t.Errorf("Expected dimension %v, got %v", tt.dimension, got.Dimension)
+			}
+		})
+	}
+}
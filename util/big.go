@@ -0,0 +1,44 @@
+package util
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// MaxBigExp is the largest binary exponent of the big.Float values of arbitrary precision mode, which are about
+// 1e±19728. Printing a big.Float takes time proportional to its exponent, even if only a few digits are printed.
+const MaxBigExp = 1 << 16
+
+// log10of2 has enough digits for the decimal exponent of every big.Float
+var log10of2, _, _ = big.ParseFloat("0.30102999566398119521373889472449302676818988", 10, 128, big.ToNearestEven)
+
+// BigText prints v like Text('g', -1). Values beyond MaxBigExp are printed with 15 significant digits, which are
+// computed from the mantissa and the exponent of v in constant time.
+func BigText(v *big.Float) string {
+	mant := new(big.Float)
+	exp := v.MantExp(mant)
+	if exp >= -MaxBigExp && exp <= MaxBigExp {
+		return v.Text('g', -1)
+	}
+	//v = mant * 10^(exp*log10(2)), the integer part of the power is the decimal exponent
+	k := new(big.Float).SetPrec(128).SetInt64(int64(exp))
+	k.Mul(k, log10of2)
+	d, _ := k.Int64()
+	frac, _ := k.Sub(k, new(big.Float).SetInt64(d)).Float64()
+	m, _ := mant.Float64()
+	m *= math.Pow(10, frac)
+	for math.Abs(m) >= 10 {
+		m /= 10
+		d++
+	}
+	for math.Abs(m) < 1 {
+		m *= 10
+		d--
+	}
+	sign := "+"
+	if d < 0 {
+		sign = ""
+	}
+	return strconv.FormatFloat(m, 'g', 15, 64) + "e" + sign + strconv.FormatInt(d, 10)
+}
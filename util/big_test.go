@@ -0,0 +1,26 @@
+package util
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestBigText(t *testing.T) {
+	var tests = []struct {
+		mant float64
+		exp  int
+		want string
+	}{
+		{0.5, 1, "1"},
+		{0.5, 101, "1.2676506002282294e+30"},
+		{0.5, 1<<20 + 1, "6.74114012549907e+315652"},
+		{-0.5, 1<<20 + 1, "-6.74114012549907e+315652"},
+		{0.5, -1<<20 + 1, "1.48342859128146e-315653"},
+	}
+	for _, tt := range tests {
+		v := new(big.Float).SetMantExp(big.NewFloat(tt.mant), tt.exp)
+		if got := BigText(v); got != tt.want {
+			t.Errorf("%v*2^%d: expected %s, got %s", tt.mant, tt.exp, tt.want, got)
+		}
+	}
+}
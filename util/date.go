@@ -0,0 +1,26 @@
+package util
+
+import "time"
+
+// TimeDimension is the Dimension of durations like 3 h, which can be added to dates
+var TimeDimension = Dimension{DimTime: 1}
+
+// FormatDate prints a date the way it is written in expressions, like 2026-12-24. The time of day follows if it isn't
+// midnight, with seconds only if there are any, and the time zone follows unless it is time.Local, like
+// 2026-12-24 15:30 Europe/Berlin.
+func FormatDate(t time.Time) string {
+	layout := "2006-01-02"
+	switch {
+	case t.Nanosecond() != 0:
+		layout += " 15:04:05.999999999"
+	case t.Second() != 0:
+		layout += " 15:04:05"
+	case t.Hour() != 0 || t.Minute() != 0:
+		layout += " 15:04"
+	}
+	s := t.Format(layout)
+	if t.Location() != time.Local {
+		s += " " + t.Location().String()
+	}
+	return s
+}
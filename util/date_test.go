@@ -0,0 +1,26 @@
+package util
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatDate(t *testing.T) {
+	var tests = []struct {
+		t    time.Time
+		want string
+	}{
+		{time.Date(2026, 12, 24, 0, 0, 0, 0, time.Local), "2026-12-24"},
+		{time.Date(2026, 12, 24, 15, 30, 0, 0, time.Local), "2026-12-24 15:30"},
+		{time.Date(2026, 12, 24, 0, 0, 5, 0, time.Local), "2026-12-24 00:00:05"},
+		{time.Date(2026, 12, 24, 8, 0, 0, 250e6, time.UTC), "2026-12-24 08:00:00.25 UTC"},
+	}
+	for _, tt := range tests {
+		if got := FormatDate(tt.t); got != tt.want {
+			t.Errorf("Expected %s, got %s", tt.want, got)
+		}
+	}
+	if got := (Token{TokenType: TokenTypeOperand, TokenZone: time.UTC}).String(); got != "UTC" {
+		t.Errorf("Expected UTC, got %s", got)
+	}
+}
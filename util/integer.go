@@ -0,0 +1,99 @@
+package util
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// IntType describes the width in Bits and the signedness of the fixed width integers used in programmer mode.
+type IntType struct {
+	Bits   int
+	Signed bool
+}
+
+var (
+	Int8   = IntType{Bits: 8, Signed: true}
+	Int16  = IntType{Bits: 16, Signed: true}
+	Int32  = IntType{Bits: 32, Signed: true}
+	Int64  = IntType{Bits: 64, Signed: true}
+	Uint8  = IntType{Bits: 8}
+	Uint16 = IntType{Bits: 16}
+	Uint32 = IntType{Bits: 32}
+	Uint64 = IntType{Bits: 64}
+)
+
+// ParseIntType parses the name of an integer type, "int8" to "int64" and "uint8" to "uint64".
+func ParseIntType(name string) (IntType, error) {
+	for _, t := range []IntType{Int8, Int16, Int32, Int64, Uint8, Uint16, Uint32, Uint64} {
+		if t.String() == name {
+			return t, nil
+		}
+	}
+	return IntType{}, fmt.Errorf("unknown integer type %s", name)
+}
+
+func (t IntType) String() string {
+	if t.Signed {
+		return "int" + strconv.Itoa(t.Bits)
+	}
+	return "uint" + strconv.Itoa(t.Bits)
+}
+
+func (t IntType) mask() uint64 {
+	if t.Bits >= 64 {
+		return ^uint64(0)
+	}
+	return 1<<uint(t.Bits) - 1
+}
+
+// Wrap truncates v to the width of the type and sign extends it again for signed types, which gives the usual two's
+// complement wraparound semantics.
+func (t IntType) Wrap(v uint64) uint64 {
+	mask := t.mask()
+	v &= mask
+	if t.Signed && v>>uint(t.Bits-1)&1 == 1 {
+		v |= ^mask
+	}
+	return v
+}
+
+// Integer is a fixed width integer of the given Type. Value holds its bits, sign extended to 64 bits for signed
+// types, so int64(Value) is the value of a signed Integer.
+type Integer struct {
+	Value uint64
+	Type  IntType
+}
+
+// Float returns the value of the Integer as float64, which may lose precision for values above 2^53.
+func (i Integer) Float() float64 {
+	if i.Type.Signed {
+		return float64(int64(i.Value))
+	}
+	return float64(i.Value)
+}
+
+// Format prints the Integer in the given base between 2 and 36. Base 10 uses the signedness of the Type, all other
+// bases print the raw two's complement bits of the type's width, with a 0x, 0o or 0b prefix where there is one.
+func (i Integer) Format(base int) string {
+	if base == 10 {
+		if i.Type.Signed {
+			return strconv.FormatInt(int64(i.Value), 10)
+		}
+		return strconv.FormatUint(i.Value, 10)
+	}
+	digits := strconv.FormatUint(i.Value&i.Type.mask(), base)
+	switch base {
+	case 16:
+		return "0x" + digits
+	case 8:
+		return "0o" + digits
+	case 2:
+		return "0b" + digits
+	default:
+		return digits
+	}
+}
+
+func (i Integer) String() string {
+	return i.Format(10)
+}
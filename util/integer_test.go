@@ -0,0 +1,74 @@
+package util
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestIntTypeWrap(t *testing.T) {
+	var tests = []struct {
+		t    IntType
+		v    uint64
+		want string
+	}{
+		{Int8, 127, "127"},
+		{Int8, 128, "-128"},
+		{Int8, 255, "-1"},
+		{Uint8, 256, "0"},
+		{Uint8, 300, "44"},
+		{Int16, 0x18000, "-32768"},
+		{Uint32, ^uint64(0), "4294967295"},
+		{Int64, ^uint64(0), "-1"},
+		{Uint64, ^uint64(0), "18446744073709551615"},
+	}
+
+	for i, tt := range tests {
+		testname := fmt.Sprintf("%d: %v(%d)", i+1, tt.t, tt.v)
+		t.Run(testname, func(t *testing.T) {
+			got := Integer{Value: tt.t.Wrap(tt.v), Type: tt.t}.String()
+			if got != tt.want {
+				t.Errorf("Wanted %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestIntegerFormat(t *testing.T) {
+	var tests = []struct {
+		i    Integer
+		base int
+		want string
+	}{
+		{Integer{255, Uint8}, 16, "0xff"},
+		{Integer{Int8.Wrap(255), Int8}, 16, "0xff"},
+		{Integer{Int8.Wrap(255), Int8}, 2, "0b11111111"},
+		{Integer{Int8.Wrap(255), Int8}, 10, "-1"},
+		{Integer{8, Int32}, 8, "0o10"},
+		{Integer{35, Uint16}, 36, "z"},
+		{Integer{Int16.Wrap(0xfffe), Int16}, 16, "0xfffe"},
+	}
+
+	for i, tt := range tests {
+		testname := fmt.Sprintf("%d: %s", i+1, tt.want)
+		t.Run(testname, func(t *testing.T) {
+			if got := tt.i.Format(tt.base); got != tt.want {
+				t.Errorf("Wanted %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseIntType(t *testing.T) {
+	for _, name := range []string{"int8", "int16", "int32", "int64", "uint8", "uint16", "uint32", "uint64"} {
+		got, err := ParseIntType(name)
+		if err != nil {
+			t.Errorf("Unexpected error for %s: %v", name, err)
+		}
+		if got.String() != name {
+			t.Errorf("Wanted %s, got %v", name, got)
+		}
+	}
+	if _, err := ParseIntType("int128"); err == nil {
+		t.Errorf("Expected error for int128, got nil")
+	}
+}
@@ -0,0 +1,17 @@
+package util
+
+// Locale contains the characters used to write numbers, the DecimalMark between the integer and the fractional part
+// and the GroupSeparator between groups of thousands, as well as the ArgumentSeparator between function arguments.
+// A GroupSeparator of 0 disables grouping.
+type Locale struct {
+	DecimalMark       int32
+	GroupSeparator    int32
+	ArgumentSeparator int32
+}
+
+var (
+	LocaleEnglish = Locale{DecimalMark: '.', GroupSeparator: ',', ArgumentSeparator: ','}
+	LocaleGerman  = Locale{DecimalMark: ',', GroupSeparator: '.', ArgumentSeparator: ';'}
+	LocaleFrench  = Locale{DecimalMark: ',', GroupSeparator: ' ', ArgumentSeparator: ';'}
+	LocaleSwiss   = Locale{DecimalMark: '.', GroupSeparator: '\'', ArgumentSeparator: ','}
+)
@@ -36,3 +36,17 @@ func (stack *TokenStack) Pop() (t *Token) {
 func (stack *TokenStack) HasElements() bool {
 	return stack.top != nil
 }
+
+// Tokens returns a copy of all tokens on the stack, from the bottom to the top
+func (stack *TokenStack) Tokens() []Token {
+	n := 0
+	for w := stack.top; w != nil; w = w.prev {
+		n++
+	}
+	tokens := make([]Token, n)
+	for w := stack.top; w != nil; w = w.prev {
+		n--
+		tokens[n] = w.Token
+	}
+	return tokens
+}
@@ -1,6 +1,11 @@
 package util
 
-import "strconv"
+import (
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
 
 type TokenType = int
 type Op = int32
@@ -19,35 +24,138 @@ const (
 	OpDivision
 	OpAddition
 	OpSubtraction
+	OpConversion
+	OpNegation
+	OpBitwiseNot
+	OpBitwiseAnd
+	OpBitwiseOr
+	OpBitwiseXor
+	OpShiftLeft
+	OpShiftRight
+	OpArgumentSeparator
+	OpPercent
+	OpModulo
+	OpRemainder
+	OpIntegerDivision
+	OpLess
+	OpLessEqual
+	OpGreater
+	OpGreaterEqual
+	OpEqual
+	OpNotEqual
+	OpAnd
+	OpOr
+	OpNot
+	OpCondition
+	OpTernary
+	OpCall
+	OpList
 )
 
 // Operator contains Op which can be used to quickly get the type of operation, Char which is its textual representation
-// as a character, a Precedence from 1 to 5, whether the operation is LeftAssociative and whether the Operator is
-// a Bracket or not.
+// as a character, a Precedence, whether the operation is LeftAssociative, whether the Operator is a Bracket or not and
+// whether it is a Unary prefix operator. Operators that are spelled with more than one character, like "in" or "<<",
+// have their textual representation in Name instead. Calls of user defined functions have the function as Name and
+// the number of Arguments they were called with, list literals like [1, 2, 3] have their number of elements.
 type Operator struct {
 	Op
 	Char            int32
-	Precedence      int
+	Name            string
+	Precedence      float64
 	LeftAssociative bool
 	Bracket         bool
+	Unary           bool
+	Arguments       int
+}
+
+// Arity returns the number of operands the operator takes
+func (o Operator) Arity() int {
+	switch {
+	case o.Op == OpCall, o.Op == OpList:
+		return o.Arguments
+	case o.Op == OpTernary:
+		return 3
+	case o.Unary, o.Op == OpFactorial, o.Op == OpPercent:
+		return 1
+	default:
+		return 2
+	}
 }
 
 func (o Operator) String() string {
+	if o.Name != "" {
+		return o.Name
+	}
 	return string(o.Char)
 }
 
 // Token contains a TokenType, which denotes the type of the token, either TokenTypeOperand or TokenTypeOperator.
-// Depending on this, either TokenOperand or TokenOperator can be expected to have valid values.
+// Depending on this, either TokenOperand or TokenOperator can be expected to have valid values. Operands can have a
+// TokenUnit, in which case TokenOperand is the value in that unit, a nil TokenUnit means the operand is dimensionless.
+// Integer literals and results of programmer mode additionally have their exact value in TokenInteger.
+// TokenPercent marks operands created by the '%' operator, TokenOperand already is the value divided by 100.
+// Operands with a TokenName are variables like the parameters of a function, their value is only known during
+// evaluation.
+// TokenBoolean marks results of comparisons and logical operators, TokenOperand is 1 for true and 0 for false.
+// Decimal literals and constants keep their digits in TokenLiteral, so they can be read again with more precision than
+// float64 has, and results of arbitrary precision evaluation have their exact value in TokenBig.
+// Lists like [1, 2, 3] have their elements in TokenList, which is not nil even for an empty list, the other values of
+// a list are not used. Elements may be lists themselves.
+// Dates like 2026-12-24 15:30 have their instant and time zone in TokenTime, a TokenZone on its own is a time zone like
+// Europe/Berlin which dates can be converted to. The other values of dates and time zones are not used either.
 type Token struct {
 	TokenType
 	TokenOperator *Operator
 	TokenOperand  float64
+	TokenName     string
+	TokenUnit     *Unit
+	TokenInteger  *Integer
+	TokenPercent  bool
+	TokenBoolean  bool
+	TokenLiteral  string
+	TokenBig      *big.Float
+	TokenList     []Token
+	TokenTime     *time.Time
+	TokenZone     *time.Location
 }
 
 func (t Token) String() string {
 	if t.TokenType == TokenTypeOperand {
-		return strconv.FormatFloat(t.TokenOperand, 'g', -1, 64)
+		if t.TokenName != "" {
+			return t.TokenName
+		}
+		if t.TokenList != nil {
+			return "[" + strings.Join(TokenStrings(t.TokenList), ", ") + "]"
+		}
+		if t.TokenTime != nil {
+			return FormatDate(*t.TokenTime)
+		}
+		if t.TokenZone != nil {
+			return t.TokenZone.String()
+		}
+		if t.TokenBoolean {
+			return strconv.FormatBool(t.TokenOperand != 0)
+		}
+		value := strconv.FormatFloat(t.TokenOperand, 'g', -1, 64)
+		if t.TokenInteger != nil {
+			value = t.TokenInteger.String()
+		} else if t.TokenBig != nil {
+			value = BigText(t.TokenBig)
+		}
+		if t.TokenUnit != nil {
+			return value + " " + t.TokenUnit.String()
+		}
+		return value
 	} else {
 		return t.TokenOperator.String()
 	}
 }
+
+// TokenStrings returns the String of every token
+func TokenStrings(tokens []Token) []string {
+	s := make([]string, len(tokens))
+	for i, t := range tokens {
+		s[i] = t.String()
+	}
+	return s
+}
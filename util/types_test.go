@@ -89,6 +89,18 @@ func TestStringer(t *testing.T) {
 				},
 			}, "[1 + 2 * 3 - 4]",
 		},
+
+		{
+			Token{
+				TokenType: TokenTypeOperand,
+				TokenList: []Token{
+					{TokenType: TokenTypeOperand, TokenOperand: 1.5},
+					{TokenType: TokenTypeOperand, TokenList: []Token{}},
+					{TokenType: TokenTypeOperand, TokenOperand: 2, TokenUnit: &Unit{Factor: 1000,
+						Terms: []UnitTerm{{Symbol: "km", Power: 1}}, Dimension: Dimension{DimLength: 1}}},
+				},
+			}, "[1.5, [], 2 km]",
+		},
 	}
 
 	for i, tt := range tests {
@@ -0,0 +1,174 @@
+package util
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Dimension holds the exponent of each SI base quantity, indexed by the Dim constants. Two units can only be added,
+// subtracted or converted into each other if their Dimension is equal.
+type Dimension [7]int
+
+const (
+	DimLength = iota
+	DimMass
+	DimTime
+	DimCurrent
+	DimTemperature
+	DimAmount
+	DimLuminosity
+)
+
+// UnitTerm is a single named unit raised to a Power, e.g. km^2.
+type UnitTerm struct {
+	Symbol string
+	Power  int
+}
+
+// Unit describes a (possibly compound) unit. Factor converts a value in this unit into the coherent SI unit of the
+// same Dimension, Terms are the named units it was built from and are only used to print it.
+// A nil *Unit is treated as dimensionless with a Factor of 1, so all methods can be called on nil.
+type Unit struct {
+	Terms     []UnitTerm
+	Factor    float64
+	Dimension Dimension
+}
+
+// Mul returns the product of both units, merging terms with the same symbol.
+func (u *Unit) Mul(o *Unit) *Unit {
+	return u.combine(o, 1)
+}
+
+// Div returns the quotient of both units, merging terms with the same symbol.
+func (u *Unit) Div(o *Unit) *Unit {
+	return u.combine(o, -1)
+}
+
+// Pow raises the unit to an integer power.
+func (u *Unit) Pow(n int) *Unit {
+	if u == nil || n == 0 {
+		return nil
+	}
+	res := &Unit{
+		Terms:  make([]UnitTerm, len(u.Terms)),
+		Factor: math.Pow(u.Factor, float64(n)),
+	}
+	for i, t := range u.Terms {
+		res.Terms[i] = UnitTerm{Symbol: t.Symbol, Power: t.Power * n}
+	}
+	for i, d := range u.Dimension {
+		res.Dimension[i] = d * n
+	}
+	return res
+}
+
+func (u *Unit) combine(o *Unit, sign int) *Unit {
+	if o == nil {
+		return u
+	}
+	if u == nil {
+		return o.Pow(sign)
+	}
+	res := &Unit{
+		Terms:  make([]UnitTerm, len(u.Terms), len(u.Terms)+len(o.Terms)),
+		Factor: u.Factor,
+	}
+	copy(res.Terms, u.Terms)
+	if sign > 0 {
+		res.Factor *= o.Factor
+	} else {
+		res.Factor /= o.Factor
+	}
+	for i := range res.Dimension {
+		res.Dimension[i] = u.Dimension[i] + sign*o.Dimension[i]
+	}
+	for _, t := range o.Terms {
+		merged := false
+		for i := range res.Terms {
+			if res.Terms[i].Symbol == t.Symbol {
+				res.Terms[i].Power += sign * t.Power
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			res.Terms = append(res.Terms, UnitTerm{Symbol: t.Symbol, Power: sign * t.Power})
+		}
+	}
+	//drop terms that cancelled out
+	terms := res.Terms[:0]
+	for _, t := range res.Terms {
+		if t.Power != 0 {
+			terms = append(terms, t)
+		}
+	}
+	res.Terms = terms
+	return res
+}
+
+// Dimensionless reports whether all exponents of the unit's Dimension are zero.
+func (u *Unit) Dimensionless() bool {
+	return u == nil || u.Dimension == Dimension{}
+}
+
+// Compatible reports whether values in both units can be added to or converted into each other.
+func (u *Unit) Compatible(o *Unit) bool {
+	var d1, d2 Dimension
+	if u != nil {
+		d1 = u.Dimension
+	}
+	if o != nil {
+		d2 = o.Dimension
+	}
+	return d1 == d2
+}
+
+// ConversionFactor returns the factor a value in unit u has to be multiplied with to be expressed in unit o.
+func (u *Unit) ConversionFactor(o *Unit) float64 {
+	f1, f2 := 1.0, 1.0
+	if u != nil {
+		f1 = u.Factor
+	}
+	if o != nil {
+		f2 = o.Factor
+	}
+	return f1 / f2
+}
+
+// String prints the unit in a form that can be tokenized again, e.g. "km/h" or "kg*m/s^2".
+func (u *Unit) String() string {
+	if u == nil {
+		return ""
+	}
+	var num, den []string
+	for _, t := range u.Terms {
+		if t.Power > 0 {
+			num = append(num, termString(t.Symbol, t.Power))
+		} else {
+			den = append(den, termString(t.Symbol, -t.Power))
+		}
+	}
+	switch {
+	case len(den) == 0:
+		return strings.Join(num, "*")
+	case len(num) == 0:
+		//there is nothing to divide, so use negative exponents
+		neg := make([]string, 0, len(u.Terms))
+		for _, t := range u.Terms {
+			neg = append(neg, termString(t.Symbol, t.Power))
+		}
+		return strings.Join(neg, "*")
+	case len(den) == 1:
+		return strings.Join(num, "*") + "/" + den[0]
+	default:
+		return strings.Join(num, "*") + "/(" + strings.Join(den, "*") + ")"
+	}
+}
+
+func termString(symbol string, power int) string {
+	if power == 1 {
+		return symbol
+	}
+	return symbol + "^" + strconv.Itoa(power)
+}
@@ -0,0 +1,66 @@
+package util
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestUnitArithmetic(t *testing.T) {
+	km := &Unit{Terms: []UnitTerm{{"km", 1}}, Factor: 1000, Dimension: Dimension{DimLength: 1}}
+	m := &Unit{Terms: []UnitTerm{{"m", 1}}, Factor: 1, Dimension: Dimension{DimLength: 1}}
+	h := &Unit{Terms: []UnitTerm{{"h", 1}}, Factor: 3600, Dimension: Dimension{DimTime: 1}}
+	kg := &Unit{Terms: []UnitTerm{{"kg", 1}}, Factor: 1, Dimension: Dimension{DimMass: 1}}
+	s := &Unit{Terms: []UnitTerm{{"s", 1}}, Factor: 1, Dimension: Dimension{DimTime: 1}}
+
+	var tests = []struct {
+		got           *Unit
+		want          string
+		factor        float64
+		dimensionless bool
+	}{
+		{km.Div(h), "km/h", 1000.0 / 3600, false},
+		{m.Mul(m), "m^2", 1, false},
+		{m.Pow(3), "m^3", 1, false},
+		{km.Pow(-2), "km^-2", 1e-6, false},
+		{kg.Mul(m).Div(s.Pow(2)), "kg*m/s^2", 1, false},
+		{kg.Div(m.Mul(s)), "kg/(m*s)", 1, false},
+		{km.Div(km), "", 1, true},
+		{km.Div(m), "km/m", 1000, true},
+		{(*Unit)(nil).Div(s), "s^-1", 1, false},
+		{m.Mul(nil), "m", 1, false},
+	}
+
+	for i, tt := range tests {
+		testname := fmt.Sprintf("%d: %s", i+1, tt.want)
+		t.Run(testname, func(t *testing.T) {
+			if got := tt.got.String(); got != tt.want {
+				t.Errorf("Wanted %s, got %s", tt.want, got)
+			}
+			if tt.got != nil && tt.got.Factor != tt.factor {
+				t.Errorf("Wanted factor %v, got %v", tt.factor, tt.got.Factor)
+			}
+			if got := tt.got.Dimensionless(); got != tt.dimensionless {
+				t.Errorf("Wanted dimensionless %v, got %v", tt.dimensionless, got)
+			}
+		})
+	}
+}
+
+func TestUnitCompatible(t *testing.T) {
+	km := &Unit{Terms: []UnitTerm{{"km", 1}}, Factor: 1000, Dimension: Dimension{DimLength: 1}}
+	mi := &Unit{Terms: []UnitTerm{{"mi", 1}}, Factor: 1609.344, Dimension: Dimension{DimLength: 1}}
+	s := &Unit{Terms: []UnitTerm{{"s", 1}}, Factor: 1, Dimension: Dimension{DimTime: 1}}
+
+	if !km.Compatible(mi) {
+		t.Errorf("Expected km and mi to be compatible")
+	}
+	if km.Compatible(s) {
+		t.Errorf("Expected km and s to be incompatible")
+	}
+	if km.Compatible(nil) || !(*Unit)(nil).Compatible(nil) {
+		t.Errorf("Expected only dimensionless units to be compatible with nil")
+	}
+	if got := mi.ConversionFactor(km); got != 1.609344 {
+		t.Errorf("Wanted conversion factor 1.609344, got %v", got)
+	}
+}